@@ -0,0 +1,44 @@
+package cai
+
+import (
+	"context"
+	"time"
+)
+
+// abortTimeout bounds sending the abort message once the context of a generation is done
+const abortTimeout = 5 * time.Second
+
+// AbortGeneration calls AbortGenerationContext with context.Background().
+func (c *Client) AbortGeneration(chatID string) error {
+	return c.AbortGenerationContext(context.Background(), chatID)
+}
+
+// AbortGenerationContext stops the generation of the turn in progress in a chat. The candidate keeps the text
+// generated so far and is marked final. Cancelling the context of a generating call has the same effect.
+func (c *Client) AbortGenerationContext(ctx context.Context, chatID string) error {
+	err := c.Requester.InitializeWebSocketContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.Requester.SendWebSocketMessageContext(ctx, newAbortGenerationMessage(chatID))
+}
+
+// newAbortGenerationMessage constructs the message stopping the generation in a chat
+func newAbortGenerationMessage(chatID string) WebSocketMessage {
+	return WebSocketMessage{
+		Command:   "abort_generation",
+		OriginID:  "web-next",
+		RequestID: generateUUID(),
+		Payload:   AbortGenerationPayload{ChatID: chatID},
+	}
+}
+
+// abortGeneration stops the generation requested by w after its context is done, so that the service does not
+// keep generating a reply nobody waits for. Failures are ignored, as the generation ends on its own eventually.
+func (w *WebSocketRequest) abortGeneration() {
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	_ = w.requester.SendWebSocketMessageContext(ctx, newAbortGenerationMessage(w.Key.ChatID))
+}
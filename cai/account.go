@@ -3,6 +3,7 @@
 package cai
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -13,19 +14,24 @@ import (
 	"os"
 )
 
-// FetchMe retrieves the account information.
+// FetchMe calls FetchMeContext with context.Background().
 func (c *Client) FetchMe() (*UserAccount, error) {
-	urlStr := "https://beta.character.ai/chat/user/"
+	return c.FetchMeContext(context.Background())
+}
+
+// FetchMeContext retrieves the account information.
+func (c *Client) FetchMeContext(ctx context.Context) (*UserAccount, error) {
+	urlStr := c.Endpoints.Beta + "/chat/user/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch account info, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch account info", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -47,24 +53,34 @@ func (c *Client) FetchMe() (*UserAccount, error) {
 	return account, nil
 }
 
-// GetSelf is an alias for FetchMe.
+// GetSelf calls GetSelfContext with context.Background().
 func (c *Client) GetSelf() (*UserAccount, error) {
-	return c.FetchMe()
+	return c.GetSelfContext(context.Background())
+}
+
+// GetSelfContext is an alias for FetchMeContext.
+func (c *Client) GetSelfContext(ctx context.Context) (*UserAccount, error) {
+	return c.FetchMeContext(ctx)
 }
 
-// FetchMySettings retrieves the user's settings.
+// FetchMySettings calls FetchMySettingsContext with context.Background().
 func (c *Client) FetchMySettings() (*Settings, error) {
-	urlStr := "https://plus.character.ai/chat/user/settings/"
+	return c.FetchMySettingsContext(context.Background())
+}
+
+// FetchMySettingsContext retrieves the user's settings.
+func (c *Client) FetchMySettingsContext(ctx context.Context) (*Settings, error) {
+	urlStr := c.Endpoints.Plus + "/chat/user/settings/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch settings, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch settings", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -81,19 +97,24 @@ func (c *Client) FetchMySettings() (*Settings, error) {
 	return &settings, nil
 }
 
-// FetchMyFollowers retrieves the user's followers.
+// FetchMyFollowers calls FetchMyFollowersContext with context.Background().
 func (c *Client) FetchMyFollowers() ([]*PublicUser, error) {
-	urlStr := "https://plus.character.ai/chat/user/followers/"
+	return c.FetchMyFollowersContext(context.Background())
+}
+
+// FetchMyFollowersContext retrieves the user's followers.
+func (c *Client) FetchMyFollowersContext(ctx context.Context) ([]*PublicUser, error) {
+	urlStr := c.Endpoints.Plus + "/chat/user/followers/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch followers, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch followers", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -112,19 +133,24 @@ func (c *Client) FetchMyFollowers() ([]*PublicUser, error) {
 	return result.Followers, nil
 }
 
-// FetchMyFollowing retrieves the users that the user is following.
+// FetchMyFollowing calls FetchMyFollowingContext with context.Background().
 func (c *Client) FetchMyFollowing() ([]*PublicUser, error) {
-	urlStr := "https://plus.character.ai/chat/user/following/"
+	return c.FetchMyFollowingContext(context.Background())
+}
+
+// FetchMyFollowingContext retrieves the users that the user is following.
+func (c *Client) FetchMyFollowingContext(ctx context.Context) ([]*PublicUser, error) {
+	urlStr := c.Endpoints.Plus + "/chat/user/following/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch following, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch following", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -143,19 +169,24 @@ func (c *Client) FetchMyFollowing() ([]*PublicUser, error) {
 	return result.Following, nil
 }
 
-// FetchMyPersonas retrieves all the user's personas.
+// FetchMyPersonas calls FetchMyPersonasContext with context.Background().
 func (c *Client) FetchMyPersonas() ([]*Character, error) {
-	urlStr := "https://plus.character.ai/chat/personas/?force_refresh=1"
+	return c.FetchMyPersonasContext(context.Background())
+}
+
+// FetchMyPersonasContext retrieves all the user's personas.
+func (c *Client) FetchMyPersonasContext(ctx context.Context) ([]*Character, error) {
+	urlStr := c.Endpoints.Plus + "/chat/personas/?force_refresh=1"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch personas, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch personas", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -174,19 +205,24 @@ func (c *Client) FetchMyPersonas() ([]*Character, error) {
 	return result.Personas, nil
 }
 
-// FetchMyCharacters retrieves all the user's characters.
+// FetchMyCharacters calls FetchMyCharactersContext with context.Background().
 func (c *Client) FetchMyCharacters() ([]*CharacterShort, error) {
-	urlStr := "https://plus.character.ai/chat/characters/?scope=user"
+	return c.FetchMyCharactersContext(context.Background())
+}
+
+// FetchMyCharactersContext retrieves all the user's characters.
+func (c *Client) FetchMyCharactersContext(ctx context.Context) ([]*CharacterShort, error) {
+	urlStr := c.Endpoints.Plus + "/chat/characters/?scope=user"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch characters, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch characters", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -205,19 +241,24 @@ func (c *Client) FetchMyCharacters() ([]*CharacterShort, error) {
 	return result.Characters, nil
 }
 
-// FetchMyUpvotedCharacters retrieves the characters the user has upvoted.
+// FetchMyUpvotedCharacters calls FetchMyUpvotedCharactersContext with context.Background().
 func (c *Client) FetchMyUpvotedCharacters() ([]*CharacterShort, error) {
-	urlStr := "https://plus.character.ai/chat/user/characters/upvoted/"
+	return c.FetchMyUpvotedCharactersContext(context.Background())
+}
+
+// FetchMyUpvotedCharactersContext retrieves the characters the user has upvoted.
+func (c *Client) FetchMyUpvotedCharactersContext(ctx context.Context) ([]*CharacterShort, error) {
+	urlStr := c.Endpoints.Plus + "/chat/user/characters/upvoted/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch upvoted characters, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch upvoted characters", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -236,19 +277,24 @@ func (c *Client) FetchMyUpvotedCharacters() ([]*CharacterShort, error) {
 	return result.Characters, nil
 }
 
-// FetchMyVoices retrieves the user's voices.
+// FetchMyVoices calls FetchMyVoicesContext with context.Background().
 func (c *Client) FetchMyVoices() ([]*Voice, error) {
-	urlStr := "https://neo.character.ai/multimodal/api/v1/voices/user"
+	return c.FetchMyVoicesContext(context.Background())
+}
+
+// FetchMyVoicesContext retrieves the user's voices.
+func (c *Client) FetchMyVoicesContext(ctx context.Context) ([]*Voice, error) {
+	urlStr := c.Endpoints.Neo + "/multimodal/api/v1/voices/user"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch voices, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch voices", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -267,9 +313,14 @@ func (c *Client) FetchMyVoices() ([]*Voice, error) {
 	return result.Voices, nil
 }
 
-// UpdateSettings updates the user's settings.
+// UpdateSettings calls UpdateSettingsContext with context.Background().
 func (c *Client) UpdateSettings(newSettings *Settings) (*Settings, error) {
-	urlStr := "https://plus.character.ai/chat/user/update_settings/"
+	return c.UpdateSettingsContext(context.Background(), newSettings)
+}
+
+// UpdateSettingsContext updates the user's settings.
+func (c *Client) UpdateSettingsContext(ctx context.Context, newSettings *Settings) (*Settings, error) {
+	urlStr := c.Endpoints.Plus + "/chat/user/update_settings/"
 	headers := c.GetHeaders(false)
 
 	bodyBytes, err := json.Marshal(newSettings)
@@ -277,14 +328,14 @@ func (c *Client) UpdateSettings(newSettings *Settings) (*Settings, error) {
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to update settings, status code: %d", resp.StatusCode)
+		return nil, newAPIError("update settings", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -302,14 +353,19 @@ func (c *Client) UpdateSettings(newSettings *Settings) (*Settings, error) {
 	}
 
 	if !result.Success {
-		return nil, errors.New("failed to update settings")
+		return nil, newRejectedError("update settings", resp, body, "")
 	}
 
 	return result.Settings, nil
 }
 
-// EditAccount edits the user's account information.
+// EditAccount calls EditAccountContext with context.Background().
 func (c *Client) EditAccount(name string, username string, bio string, avatarRelPath string) error {
+	return c.EditAccountContext(context.Background(), name, username, bio, avatarRelPath)
+}
+
+// EditAccountContext edits the user's account information.
+func (c *Client) EditAccountContext(ctx context.Context, name string, username string, bio string, avatarRelPath string) error {
 	if len(username) < 2 || len(username) > 20 {
 		return errors.New("username must be at least 2 characters and no more than 20")
 	}
@@ -322,7 +378,7 @@ func (c *Client) EditAccount(name string, username string, bio string, avatarRel
 		return errors.New("bio must be no more than 500 characters")
 	}
 
-	urlStr := "https://plus.character.ai/chat/user/update/"
+	urlStr := c.Endpoints.Plus + "/chat/user/update/"
 	headers := c.GetHeaders(false)
 
 	newAccountInfo := UpdateProfilePayload{
@@ -342,14 +398,14 @@ func (c *Client) EditAccount(name string, username string, bio string, avatarRel
 		return err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to edit account, status code: %d", resp.StatusCode)
+		return newAPIError("edit account", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -367,25 +423,30 @@ func (c *Client) EditAccount(name string, username string, bio string, avatarRel
 	}
 
 	if result.Status != "OK" {
-		return fmt.Errorf("failed to edit account, error: %s", result.Error)
+		return newRejectedError("edit account", resp, body, result.Error)
 	}
 
 	return nil
 }
 
-// FetchMyPersona retrieves a user's persona by ID.
+// FetchMyPersona calls FetchMyPersonaContext with context.Background().
 func (c *Client) FetchMyPersona(personaID string) (*Persona, error) {
-	urlStr := fmt.Sprintf("https://plus.character.ai/chat/persona/?id=%s", url.QueryEscape(personaID))
+	return c.FetchMyPersonaContext(context.Background(), personaID)
+}
+
+// FetchMyPersonaContext retrieves a user's persona by ID.
+func (c *Client) FetchMyPersonaContext(ctx context.Context, personaID string) (*Persona, error) {
+	urlStr := fmt.Sprintf("%s/chat/persona/?id=%s", c.Endpoints.Plus, url.QueryEscape(personaID))
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch persona, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch persona", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -402,14 +463,19 @@ func (c *Client) FetchMyPersona(personaID string) (*Persona, error) {
 	}
 
 	if result.Persona == nil {
-		return nil, errors.New("persona not found")
+		return nil, newRejectedError("fetch persona", resp, body, "persona not found")
 	}
 
 	return result.Persona, nil
 }
 
-// CreatePersona creates a new persona.
+// CreatePersona calls CreatePersonaContext with context.Background().
 func (c *Client) CreatePersona(name string, definition string, avatarRelPath string) (*Persona, error) {
+	return c.CreatePersonaContext(context.Background(), name, definition, avatarRelPath)
+}
+
+// CreatePersonaContext creates a new persona.
+func (c *Client) CreatePersonaContext(ctx context.Context, name string, definition string, avatarRelPath string) (*Persona, error) {
 	if len(name) < 3 || len(name) > 20 {
 		return nil, errors.New("name must be at least 3 characters and no more than 20")
 	}
@@ -418,7 +484,7 @@ func (c *Client) CreatePersona(name string, definition string, avatarRelPath str
 		return nil, errors.New("definition must be no more than 728 characters")
 	}
 
-	urlStr := "https://plus.character.ai/chat/character/create/"
+	urlStr := c.Endpoints.Plus + "/chat/character/create/"
 	headers := c.GetHeaders(false)
 
 	payload := CreatePersonaPayload{
@@ -444,14 +510,14 @@ func (c *Client) CreatePersona(name string, definition string, avatarRelPath str
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to create persona, status code: %d", resp.StatusCode)
+		return nil, newAPIError("create persona", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -466,20 +532,25 @@ func (c *Client) CreatePersona(name string, definition string, avatarRelPath str
 	}
 
 	if result.Status != "OK" {
-		return nil, fmt.Errorf("failed to create persona, error: %s", result.Error)
+		return nil, newRejectedError("create persona", resp, body, result.Error)
 	}
 
 	if result.Persona == nil {
-		return nil, errors.New("persona not returned in response")
+		return nil, fmt.Errorf("%w: persona not returned", ErrInvalidResponse)
 	}
 
 	return result.Persona, nil
 }
 
-// EditPersona edits an existing persona.
+// EditPersona calls EditPersonaContext with context.Background().
 func (c *Client) EditPersona(personaID string, name string, definition string, avatarRelPath string) (*Persona, error) {
+	return c.EditPersonaContext(context.Background(), personaID, name, definition, avatarRelPath)
+}
+
+// EditPersonaContext edits an existing persona.
+func (c *Client) EditPersonaContext(ctx context.Context, personaID string, name string, definition string, avatarRelPath string) (*Persona, error) {
 	// Fetch the existing persona
-	oldPersona, err := c.FetchMyPersona(personaID)
+	oldPersona, err := c.FetchMyPersonaContext(ctx, personaID)
 	if err != nil {
 		return nil, err
 	}
@@ -524,7 +595,7 @@ func (c *Client) EditPersona(personaID string, name string, definition string, a
 		payload.AvatarRelPath = avatarRelPath
 	}
 
-	urlStr := "https://plus.character.ai/chat/character/update/"
+	urlStr := c.Endpoints.Plus + "/chat/character/update/"
 	headers := c.GetHeaders(false)
 
 	bodyBytes, err := json.Marshal(payload)
@@ -532,14 +603,14 @@ func (c *Client) EditPersona(personaID string, name string, definition string, a
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to edit persona, status code: %d", resp.StatusCode)
+		return nil, newAPIError("edit persona", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -554,20 +625,25 @@ func (c *Client) EditPersona(personaID string, name string, definition string, a
 	}
 
 	if result.Status != "OK" {
-		return nil, fmt.Errorf("failed to edit persona, error: %s", result.Error)
+		return nil, newRejectedError("edit persona", resp, body, result.Error)
 	}
 
 	if result.Persona == nil {
-		return nil, errors.New("persona not returned in response")
+		return nil, fmt.Errorf("%w: persona not returned", ErrInvalidResponse)
 	}
 
 	return result.Persona, nil
 }
 
-// DeletePersona deletes a persona by marking it as archived.
+// DeletePersona calls DeletePersonaContext with context.Background().
 func (c *Client) DeletePersona(personaID string) error {
+	return c.DeletePersonaContext(context.Background(), personaID)
+}
+
+// DeletePersonaContext deletes a persona by marking it as archived.
+func (c *Client) DeletePersonaContext(ctx context.Context, personaID string) error {
 	// Fetch the existing persona
-	oldPersona, err := c.FetchMyPersona(personaID)
+	oldPersona, err := c.FetchMyPersonaContext(ctx, personaID)
 	if err != nil {
 		return err
 	}
@@ -592,7 +668,7 @@ func (c *Client) DeletePersona(personaID string) error {
 		Archived:              true,
 	}
 
-	urlStr := "https://plus.character.ai/chat/character/update/"
+	urlStr := c.Endpoints.Plus + "/chat/character/update/"
 	headers := c.GetHeaders(false)
 
 	bodyBytes, err := json.Marshal(payload)
@@ -600,14 +676,14 @@ func (c *Client) DeletePersona(personaID string) error {
 		return err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to delete persona, status code: %d", resp.StatusCode)
+		return newAPIError("delete persona", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -622,21 +698,29 @@ func (c *Client) DeletePersona(personaID string) error {
 	}
 
 	if result.Status != "OK" {
-		return fmt.Errorf("failed to delete persona, error: %s", result.Error)
+		return newRejectedError("delete persona", resp, body, result.Error)
 	}
 
 	return nil
 }
 
-// SetDefaultPersona sets the default persona for the user.
+// SetDefaultPersona calls SetDefaultPersonaContext with context.Background().
 func (c *Client) SetDefaultPersona(personaID string) error {
-	settings, err := c.FetchMySettings()
+	return c.SetDefaultPersonaContext(context.Background(), personaID)
+}
+
+// SetDefaultPersonaContext sets the default persona for the user.
+func (c *Client) SetDefaultPersonaContext(ctx context.Context, personaID string) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	settings, err := c.FetchMySettingsContext(ctx)
 	if err != nil {
 		return err
 	}
 	settings.DefaultPersonaID = personaID
 
-	_, err = c.UpdateSettings(settings)
+	_, err = c.UpdateSettingsContext(ctx, settings)
 	if err != nil {
 		return fmt.Errorf("failed to set default persona: %v", err)
 	}
@@ -644,14 +728,29 @@ func (c *Client) SetDefaultPersona(personaID string) error {
 	return nil
 }
 
-// UnsetDefaultPersona unsets the default persona for the user.
+// UnsetDefaultPersona calls UnsetDefaultPersonaContext with context.Background().
 func (c *Client) UnsetDefaultPersona() error {
-	return c.SetDefaultPersona("")
+	return c.UnsetDefaultPersonaContext(context.Background())
 }
 
-// SetPersona sets the persona override for a character.
+// UnsetDefaultPersonaContext unsets the default persona for the user.
+func (c *Client) UnsetDefaultPersonaContext(ctx context.Context) error {
+	return c.SetDefaultPersonaContext(ctx, "")
+}
+
+// SetPersona calls SetPersonaContext with context.Background().
 func (c *Client) SetPersona(characterID string, personaID string) error {
-	settings, err := c.FetchMySettings()
+	return c.SetPersonaContext(context.Background(), characterID, personaID)
+}
+
+// SetPersonaContext sets the persona override for a character, making the user chat as the persona with it.
+// The override is stored in the account settings, so it applies to all chats with the character until it is
+// changed, from any client. Settings updates of a Client are serialized, but not those of different clients.
+func (c *Client) SetPersonaContext(ctx context.Context, characterID string, personaID string) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	settings, err := c.FetchMySettingsContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -662,7 +761,7 @@ func (c *Client) SetPersona(characterID string, personaID string) error {
 
 	settings.PersonaOverrides[characterID] = personaID
 
-	_, err = c.UpdateSettings(settings)
+	_, err = c.UpdateSettingsContext(ctx, settings)
 	if err != nil {
 		return fmt.Errorf("failed to set persona override: %v", err)
 	}
@@ -670,9 +769,17 @@ func (c *Client) SetPersona(characterID string, personaID string) error {
 	return nil
 }
 
-// UnsetPersona unsets the persona override for a character.
+// UnsetPersona calls UnsetPersonaContext with context.Background().
 func (c *Client) UnsetPersona(characterID string) error {
-	settings, err := c.FetchMySettings()
+	return c.UnsetPersonaContext(context.Background(), characterID)
+}
+
+// UnsetPersonaContext unsets the persona override for a character.
+func (c *Client) UnsetPersonaContext(ctx context.Context, characterID string) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	settings, err := c.FetchMySettingsContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -683,7 +790,7 @@ func (c *Client) UnsetPersona(characterID string) error {
 
 	delete(settings.PersonaOverrides, characterID)
 
-	_, err = c.UpdateSettings(settings)
+	_, err = c.UpdateSettingsContext(ctx, settings)
 	if err != nil {
 		return fmt.Errorf("failed to unset persona override: %v", err)
 	}
@@ -691,9 +798,14 @@ func (c *Client) UnsetPersona(characterID string) error {
 	return nil
 }
 
-// SetVoice sets the voice override for a character.
+// SetVoice calls SetVoiceContext with context.Background().
 func (c *Client) SetVoice(characterID string, voiceID string) error {
-	urlStr := fmt.Sprintf("https://plus.character.ai/chat/character/%s/voice_override/update/", characterID)
+	return c.SetVoiceContext(context.Background(), characterID, voiceID)
+}
+
+// SetVoiceContext sets the voice override for a character.
+func (c *Client) SetVoiceContext(ctx context.Context, characterID string, voiceID string) error {
+	urlStr := fmt.Sprintf("%s/chat/character/%s/voice_override/update/", c.Endpoints.Plus, characterID)
 	headers := c.GetHeaders(false)
 
 	payload := SetVoicePayload{
@@ -705,14 +817,14 @@ func (c *Client) SetVoice(characterID string, voiceID string) error {
 		return err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to set voice, status code: %d", resp.StatusCode)
+		return newAPIError("set voice", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -727,25 +839,30 @@ func (c *Client) SetVoice(characterID string, voiceID string) error {
 	}
 
 	if !result.Success {
-		return fmt.Errorf("failed to set voice, error: %s", result.Error)
+		return newRejectedError("set voice", resp, body, result.Error)
 	}
 
 	return nil
 }
 
-// UnsetVoice unsets the voice override for a character.
+// UnsetVoice calls UnsetVoiceContext with context.Background().
 func (c *Client) UnsetVoice(characterID string) error {
-	urlStr := fmt.Sprintf("https://plus.character.ai/chat/character/%s/voice_override/delete/", characterID)
+	return c.UnsetVoiceContext(context.Background(), characterID)
+}
+
+// UnsetVoiceContext unsets the voice override for a character.
+func (c *Client) UnsetVoiceContext(ctx context.Context, characterID string) error {
+	urlStr := fmt.Sprintf("%s/chat/character/%s/voice_override/delete/", c.Endpoints.Plus, characterID)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Post(urlStr, headers, nil)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to unset voice, status code: %d", resp.StatusCode)
+		return newAPIError("unset voice", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -760,16 +877,21 @@ func (c *Client) UnsetVoice(characterID string) error {
 	}
 
 	if !result.Success {
-		return fmt.Errorf("failed to unset voice, error: %s", result.Error)
+		return newRejectedError("unset voice", resp, body, result.Error)
 	}
 
 	return nil
 }
 
-// UploadAvatar uploads a new avatar for the user.
+// UploadAvatar calls UploadAvatarContext with context.Background().
+func (c *Client) UploadAvatar(imagePath string, checkImage bool) (*Avatar, error) {
+	return c.UploadAvatarContext(context.Background(), imagePath, checkImage)
+}
+
+// UploadAvatarContext uploads a new avatar for the user.
 // The imagePath can be a local file path or a URL.
 // The checkImage parameter determines whether to verify the uploaded image.
-func (c *Client) UploadAvatar(imagePath string, checkImage bool) (*Avatar, error) {
+func (c *Client) UploadAvatarContext(ctx context.Context, imagePath string, checkImage bool) (*Avatar, error) {
 	var imageData []byte
 	var err error
 
@@ -781,7 +903,7 @@ func (c *Client) UploadAvatar(imagePath string, checkImage bool) (*Avatar, error
 		}
 	} else {
 		// Try to treat it as a URL
-		resp, err := c.Requester.Get(imagePath, nil)
+		resp, err := c.Requester.GetContext(ctx, imagePath, nil)
 		if err != nil {
 			return nil, errors.New("invalid image path or URL")
 		}
@@ -792,10 +914,15 @@ func (c *Client) UploadAvatar(imagePath string, checkImage bool) (*Avatar, error
 		}
 	}
 
+	return c.uploadAvatarData(ctx, imageData, checkImage)
+}
+
+// uploadAvatarData uploads avatar image data, optionally verifying the uploaded image
+func (c *Client) uploadAvatarData(ctx context.Context, imageData []byte, checkImage bool) (*Avatar, error) {
 	mimeType := http.DetectContentType(imageData)
 	dataURI := fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(imageData))
 
-	urlStr := "https://character.ai/api/trpc/user.uploadAvatar?batch=1"
+	urlStr := c.Endpoints.TRPC + "/user.uploadAvatar?batch=1"
 	headers := c.GetHeaders(true)
 
 	// Prepare the payload using the defined structs
@@ -811,14 +938,14 @@ func (c *Client) UploadAvatar(imagePath string, checkImage bool) (*Avatar, error
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to upload avatar, status code: %d", resp.StatusCode)
+		return nil, newAPIError("upload avatar", resp)
 	}
 
 	bodyResp, err := io.ReadAll(resp.Body)
@@ -833,7 +960,7 @@ func (c *Client) UploadAvatar(imagePath string, checkImage bool) (*Avatar, error
 	}
 
 	if len(response) == 0 || response[0].Result.Data.JSON == "" {
-		return nil, errors.New("failed to upload avatar, file name not returned")
+		return nil, fmt.Errorf("%w: avatar file name not returned", ErrInvalidResponse)
 	}
 
 	fileName := response[0].Result.Data.JSON
@@ -841,11 +968,11 @@ func (c *Client) UploadAvatar(imagePath string, checkImage bool) (*Avatar, error
 
 	if checkImage {
 		size := 150
-		imageURL := avatar.GetURL(size, false)
+		imageURL := c.AvatarURL(avatar, size, false)
 
-		resp, err := c.Requester.Get(imageURL, nil)
+		resp, err := c.Requester.GetContext(ctx, imageURL, nil)
 		if err != nil || resp.StatusCode != http.StatusOK {
-			return nil, errors.New("uploaded avatar did not pass the filter or is invalid")
+			return nil, fmt.Errorf("%w: uploaded avatar did not pass the filter or is invalid", ErrContentFiltered)
 		}
 	}
 
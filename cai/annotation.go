@@ -0,0 +1,142 @@
+package cai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AnnotationTag labels a quality of a candidate, given as feedback to the model.
+type AnnotationTag string
+
+// Annotation tags offered by the official client. Each has a negated form, see AnnotationTag.Negated.
+const (
+	AnnotationBadMemory      AnnotationTag = "bad_memory"
+	AnnotationBoring         AnnotationTag = "boring"
+	AnnotationEndsChatEarly  AnnotationTag = "ends_chat_early"
+	AnnotationFunny          AnnotationTag = "funny"
+	AnnotationHelpful        AnnotationTag = "helpful"
+	AnnotationInaccurate     AnnotationTag = "inaccurate"
+	AnnotationInteresting    AnnotationTag = "interesting"
+	AnnotationLong           AnnotationTag = "long"
+	AnnotationOutOfCharacter AnnotationTag = "out_of_character"
+	AnnotationRepetitive     AnnotationTag = "repetitive"
+	AnnotationShort          AnnotationTag = "short"
+)
+
+// MaxStarRating is the best rating of a candidate; 1 is the worst.
+const MaxStarRating = 4
+
+// Negated returns the tag stating the opposite, e.g. "not_boring" for "boring" and vice versa
+func (t AnnotationTag) Negated() AnnotationTag {
+	if strings.HasPrefix(string(t), "not_") {
+		return t[len("not_"):]
+	}
+	return "not_" + t
+}
+
+// RateCandidate calls RateCandidateContext with context.Background().
+func (c *Client) RateCandidate(chatID, turnID, candidateID string, stars int) error {
+	return c.RateCandidateContext(context.Background(), chatID, turnID, candidateID, stars)
+}
+
+// RateCandidateContext gives a candidate a rating from 1 to MaxStarRating stars
+func (c *Client) RateCandidateContext(ctx context.Context, chatID, turnID, candidateID string, stars int) error {
+	if stars < 1 || stars > MaxStarRating {
+		return fmt.Errorf("star rating must be between 1 and %d, got %d", MaxStarRating, stars)
+	}
+
+	return c.annotateCandidate(ctx, AnnotateCandidatePayload{
+		TurnKey:     TurnKey{ChatID: chatID, TurnID: turnID},
+		CandidateID: candidateID,
+		StarRating:  stars,
+	})
+}
+
+// AnnotateCandidate calls AnnotateCandidateContext with context.Background().
+func (c *Client) AnnotateCandidate(chatID, turnID, candidateID string, tags ...AnnotationTag) error {
+	return c.AnnotateCandidateContext(context.Background(), chatID, turnID, candidateID, tags...)
+}
+
+// AnnotateCandidateContext labels a candidate with annotation tags. Annotating a candidate again replaces its tags.
+// The tags given in a chat are sent along with later generate requests of the client in that chat,
+// so the model takes the feedback into account.
+func (c *Client) AnnotateCandidateContext(ctx context.Context, chatID, turnID, candidateID string, tags ...AnnotationTag) error {
+	if len(tags) == 0 {
+		return errors.New("no annotation tags given")
+	}
+
+	annotations := make([]string, len(tags))
+	for i, tag := range tags {
+		annotations[i] = string(tag)
+	}
+
+	err := c.annotateCandidate(ctx, AnnotateCandidatePayload{
+		TurnKey:     TurnKey{ChatID: chatID, TurnID: turnID},
+		CandidateID: candidateID,
+		Annotations: annotations,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.annotationsMutex.Lock()
+	defer c.annotationsMutex.Unlock()
+
+	if c.annotations == nil {
+		c.annotations = make(map[string]map[candidateKey][]string)
+	}
+	if c.annotations[chatID] == nil {
+		c.annotations[chatID] = make(map[candidateKey][]string)
+	}
+	c.annotations[chatID][candidateKey{turnID: turnID, candidateID: candidateID}] = annotations
+	return nil
+}
+
+// candidateKey identifies an annotated candidate within a chat
+type candidateKey struct {
+	turnID      string
+	candidateID string
+}
+
+// annotateCandidate submits feedback on a candidate
+func (c *Client) annotateCandidate(ctx context.Context, payload AnnotateCandidatePayload) error {
+	urlStr := c.Endpoints.Neo + "/annotations/"
+	headers := c.GetHeaders(false)
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("annotate candidate", resp)
+	}
+
+	return nil
+}
+
+// previousAnnotations returns the annotation counts to send with a generate request in a chat,
+// counting each tag once per candidate annotated with it
+func (c *Client) previousAnnotations(chatID string) PreviousAnnotations {
+	annotations := generatePreviousAnnotations()
+
+	c.annotationsMutex.Lock()
+	defer c.annotationsMutex.Unlock()
+
+	for _, tags := range c.annotations[chatID] {
+		for _, tag := range tags {
+			annotations[tag]++
+		}
+	}
+	return annotations
+}
@@ -0,0 +1,487 @@
+package cai
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BackupVersion is the version of the backup archive format. It is increased on incompatible changes.
+const BackupVersion = 1
+
+// Files of a backup archive
+const (
+	backupManifestFile = "manifest.json"
+	backupProfileFile  = "profile.json"
+	backupSettingsFile = "settings.json"
+)
+
+// BackupManifest describes the contents of a backup archive. It is stored as manifest.json, and the IDs it lists
+// name the files below personas/, characters/, voices/ and chats/.
+type BackupManifest struct {
+	Version    int       `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	Username   string    `json:"username"`
+	Personas   []string  `json:"personas"`
+	Characters []string  `json:"characters"`
+	Voices     []string  `json:"voices"`
+	Chats      []string  `json:"chats"`
+}
+
+// BackupVoice is a voice in a backup archive. PreviewAudioFile is the archive path of the preview audio, if any.
+type BackupVoice struct {
+	Voice            *Voice `json:"voice"`
+	PreviewAudioFile string `json:"preview_audio_file,omitempty"`
+}
+
+// BackupChat is a chat in a backup archive with all of its turns in chronological order
+type BackupChat struct {
+	Chat  *Chat   `json:"chat"`
+	Turns []*Turn `json:"turns"`
+}
+
+// RestoreOptions selects what a restore recreates in the account
+type RestoreOptions struct {
+	Characters bool // Create the backed up characters
+	Personas   bool // Create the backed up personas
+	Settings   bool // Apply the default persona, speech setting and persona overrides
+}
+
+// DefaultRestoreOptions returns options restoring everything a restore supports.
+func DefaultRestoreOptions() RestoreOptions {
+	return RestoreOptions{
+		Characters: true,
+		Personas:   true,
+		Settings:   true,
+	}
+}
+
+// RestoreResult maps the IDs of the backed up characters and personas to the IDs of their restored copies
+type RestoreResult struct {
+	Characters map[string]string
+	Personas   map[string]string
+	Settings   *Settings // Settings after the restore; nil if they were not restored
+}
+
+// Backup calls BackupContext with context.Background().
+func (c *Client) Backup(archivePath string) (*BackupManifest, error) {
+	return c.BackupContext(context.Background(), archivePath)
+}
+
+// BackupContext snapshots the account into an archive at archivePath: the profile, the settings, the personas,
+// the user's characters with their full definitions, the user's voices with their preview audio and every chat
+// with all of its turns. If archivePath ends in ".zip", a zip file is written, otherwise a directory which must
+// not exist yet or be empty.
+// Chats are found among the recent chats, the group chats and the chats with the user's characters and with each
+// character of a recent chat. Avatars are referenced by file name, not copied.
+func (c *Client) BackupContext(ctx context.Context, archivePath string) (*BackupManifest, error) {
+	archive, err := createBackupArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := c.backup(ctx, archive)
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// backup writes the account to an archive
+func (c *Client) backup(ctx context.Context, archive backupArchive) (*BackupManifest, error) {
+	manifest := &BackupManifest{
+		Version:    BackupVersion,
+		CreatedAt:  time.Now().UTC(),
+		Personas:   []string{},
+		Characters: []string{},
+		Voices:     []string{},
+		Chats:      []string{},
+	}
+
+	me, err := c.FetchMeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if me.User != nil {
+		manifest.Username = me.User.Username
+	}
+	if err = writeBackupJSON(archive, backupProfileFile, me); err != nil {
+		return nil, err
+	}
+
+	settings, err := c.FetchMySettingsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeBackupJSON(archive, backupSettingsFile, settings); err != nil {
+		return nil, err
+	}
+
+	personas, err := c.FetchMyPersonasContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range personas {
+		persona, err := c.FetchMyPersonaContext(ctx, summary.ExternalID)
+		if err != nil {
+			return nil, err
+		}
+		if err = writeBackupJSON(archive, backupPersonaFile(persona.PersonaID), persona); err != nil {
+			return nil, err
+		}
+		manifest.Personas = append(manifest.Personas, persona.PersonaID)
+	}
+
+	characters, err := c.FetchMyCharactersContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range characters {
+		character, err := c.FetchCharacterInfoContext(ctx, summary.CharacterID)
+		if err != nil {
+			return nil, err
+		}
+		if err = writeBackupJSON(archive, backupCharacterFile(character.ExternalID), character); err != nil {
+			return nil, err
+		}
+		manifest.Characters = append(manifest.Characters, character.ExternalID)
+	}
+
+	voices, err := c.FetchMyVoicesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, voice := range voices {
+		if err = c.backupVoice(ctx, archive, voice); err != nil {
+			return nil, err
+		}
+		manifest.Voices = append(manifest.Voices, voice.VoiceID)
+	}
+
+	chats, err := c.discoverChats(ctx, manifest.Characters)
+	if err != nil {
+		return nil, err
+	}
+	for _, chat := range chats {
+		turns, err := c.FetchAllMessagesContext(ctx, chat.ChatID, false)
+		if err != nil {
+			return nil, err
+		}
+		// Messages are fetched newest first
+		for i, j := 0, len(turns)-1; i < j; i, j = i+1, j-1 {
+			turns[i], turns[j] = turns[j], turns[i]
+		}
+		if turns == nil {
+			turns = []*Turn{}
+		}
+		if err = writeBackupJSON(archive, backupChatFile(chat.ChatID), BackupChat{Chat: chat, Turns: turns}); err != nil {
+			return nil, err
+		}
+		manifest.Chats = append(manifest.Chats, chat.ChatID)
+	}
+
+	if err = writeBackupJSON(archive, backupManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// backupVoice writes the metadata and the preview audio of a voice
+func (c *Client) backupVoice(ctx context.Context, archive backupArchive, voice *Voice) error {
+	entry := BackupVoice{Voice: voice}
+	if voice.PreviewAudioURL != "" {
+		audio, err := c.fetchPreviewAudio(ctx, voice.PreviewAudioURL)
+		if err != nil {
+			return fmt.Errorf("voice %s: %w", voice.VoiceID, err)
+		}
+		entry.PreviewAudioFile = "voices/" + voice.VoiceID + previewAudioExtension(voice.PreviewAudioURL)
+		if err = archive.WriteFile(entry.PreviewAudioFile, audio); err != nil {
+			return err
+		}
+	}
+	return writeBackupJSON(archive, "voices/"+voice.VoiceID+".json", entry)
+}
+
+// fetchPreviewAudio downloads the preview audio of a voice
+func (c *Client) fetchPreviewAudio(ctx context.Context, urlStr string) ([]byte, error) {
+	resp, err := c.Requester.GetContext(ctx, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("fetch voice preview", resp)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// previewAudioExtension returns the file extension of a preview audio URL, defaulting to ".mp3"
+func previewAudioExtension(urlStr string) string {
+	parsed, err := url.Parse(urlStr)
+	if err != nil {
+		return ".mp3"
+	}
+	extension := path.Ext(parsed.Path)
+	if extension == "" || extension == ".json" {
+		return ".mp3"
+	}
+	return extension
+}
+
+// Restore calls RestoreContext with context.Background().
+func (c *Client) Restore(archivePath string, options RestoreOptions) (*RestoreResult, error) {
+	return c.RestoreContext(context.Background(), archivePath, options)
+}
+
+// RestoreContext recreates the characters, personas and settings of a backup archive written by BackupContext
+// in the account of the client, which may be another one than the backed up account. Options select what is
+// restored. The restored copies get new IDs, which the result maps the backed up IDs to.
+// Persona overrides and the default persona are applied with the IDs of restored personas and characters; IDs
+// which were not restored are kept, so restoring settings into the backed up account keeps them intact.
+// Overrides are merged into the current settings. Chats and voices cannot be recreated and are not restored.
+// Once restoring has started, an error is returned along with the result so far, which maps the copies
+// created before the failure.
+func (c *Client) RestoreContext(ctx context.Context, archivePath string, options RestoreOptions) (*RestoreResult, error) {
+	archive, err := openBackupArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	var manifest BackupManifest
+	if err = readBackupJSON(archive, backupManifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+
+	result := &RestoreResult{
+		Characters: make(map[string]string),
+		Personas:   make(map[string]string),
+	}
+
+	if options.Personas {
+		for _, personaID := range manifest.Personas {
+			var persona Persona
+			if err = readBackupJSON(archive, backupPersonaFile(personaID), &persona); err != nil {
+				return result, err
+			}
+			restored, err := c.CreatePersonaContext(ctx, persona.Name, persona.Definition, persona.AvatarFileName)
+			if err != nil {
+				return result, fmt.Errorf("persona %s: %w", personaID, err)
+			}
+			result.Personas[personaID] = restored.PersonaID
+		}
+	}
+
+	if options.Characters {
+		for _, characterID := range manifest.Characters {
+			var character Character
+			if err = readBackupJSON(archive, backupCharacterFile(characterID), &character); err != nil {
+				return result, err
+			}
+			restored, err := c.CreateCharacterContext(ctx, character.Name, character.Greeting, character.Title,
+				character.Description, character.Definition, character.Copyable, character.Visibility,
+				character.AvatarFileName, character.DefaultVoiceID)
+			if err != nil {
+				return result, fmt.Errorf("character %s: %w", characterID, err)
+			}
+			result.Characters[characterID] = restored.ExternalID
+		}
+	}
+
+	if options.Settings {
+		var backedUp Settings
+		if err = readBackupJSON(archive, backupSettingsFile, &backedUp); err != nil {
+			return result, err
+		}
+		result.Settings, err = c.restoreSettings(ctx, &backedUp, result)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// restoreSettings merges backed up settings into the current ones, with the IDs of restored copies
+func (c *Client) restoreSettings(ctx context.Context, backedUp *Settings, result *RestoreResult) (*Settings, error) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	settings, err := c.FetchMySettingsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings.PersonaOverrides == nil {
+		settings.PersonaOverrides = make(map[string]string)
+	}
+
+	settings.EnableTTS = backedUp.EnableTTS
+	settings.DefaultPersonaID = restoredID(result.Personas, backedUp.DefaultPersonaID)
+	for characterID, personaID := range backedUp.PersonaOverrides {
+		settings.PersonaOverrides[restoredID(result.Characters, characterID)] = restoredID(result.Personas, personaID)
+	}
+
+	updated, err := c.UpdateSettingsContext(ctx, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore settings: %w", err)
+	}
+	return updated, nil
+}
+
+// restoredID returns the ID of the restored copy of id, or id itself if it was not restored
+func restoredID(restored map[string]string, id string) string {
+	if newID, ok := restored[id]; ok {
+		return newID
+	}
+	return id
+}
+
+// backupPersonaFile returns the archive path of a persona
+func backupPersonaFile(personaID string) string {
+	return "personas/" + personaID + ".json"
+}
+
+// backupCharacterFile returns the archive path of a character
+func backupCharacterFile(characterID string) string {
+	return "characters/" + characterID + ".json"
+}
+
+// backupChatFile returns the archive path of a chat
+func backupChatFile(chatID string) string {
+	return "chats/" + chatID + ".json"
+}
+
+// writeBackupJSON writes a value as an indented JSON file of an archive
+func writeBackupJSON(archive backupArchive, name string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return archive.WriteFile(name, data)
+}
+
+// readBackupJSON reads a JSON file of an archive
+func readBackupJSON(archive fs.FS, name string, value interface{}) error {
+	data, err := fs.ReadFile(archive, name)
+	if err != nil {
+		return fmt.Errorf("invalid backup: %w", err)
+	}
+	if err = json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("invalid backup: %s: %w", name, err)
+	}
+	return nil
+}
+
+// backupArchive is a backup archive being written. Names are slash separated paths.
+type backupArchive interface {
+	WriteFile(name string, data []byte) error
+	Close() error
+}
+
+// createBackupArchive creates a zip archive if archivePath ends in ".zip", or a directory archive otherwise
+func createBackupArchive(archivePath string) (backupArchive, error) {
+	if strings.EqualFold(filepath.Ext(archivePath), ".zip") {
+		file, err := os.Create(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		return &zipBackupArchive{file: file, writer: zip.NewWriter(file)}, nil
+	}
+
+	entries, err := os.ReadDir(archivePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("backup directory %s is not empty", archivePath)
+	}
+	if err = os.MkdirAll(archivePath, 0o755); err != nil {
+		return nil, err
+	}
+	return dirBackupArchive(archivePath), nil
+}
+
+// openBackupArchive opens a zip archive if archivePath ends in ".zip", or a directory archive otherwise
+func openBackupArchive(archivePath string) (interface {
+	fs.FS
+	io.Closer
+}, error) {
+	if strings.EqualFold(filepath.Ext(archivePath), ".zip") {
+		return zip.OpenReader(archivePath)
+	}
+
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("backup %s is not a directory or zip file", archivePath)
+	}
+	return nopCloserFS{os.DirFS(archivePath)}, nil
+}
+
+// dirBackupArchive writes the files of an archive below a directory
+type dirBackupArchive string
+
+func (d dirBackupArchive) WriteFile(name string, data []byte) error {
+	filePath := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
+}
+
+func (d dirBackupArchive) Close() error {
+	return nil
+}
+
+// zipBackupArchive writes the files of an archive to a zip file
+type zipBackupArchive struct {
+	file   *os.File
+	writer *zip.Writer
+}
+
+func (z *zipBackupArchive) WriteFile(name string, data []byte) error {
+	w, err := z.writer.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (z *zipBackupArchive) Close() error {
+	err := z.writer.Close()
+	if closeErr := z.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// nopCloserFS adds a no-op Close to a file system
+type nopCloserFS struct {
+	fs.FS
+}
+
+func (nopCloserFS) Close() error {
+	return nil
+}
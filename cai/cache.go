@@ -0,0 +1,611 @@
+package cai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChatStore is a local cache of chats and their turns, see WithChatStore. Implementations must be safe for
+// concurrent use. MemoryChatStore keeps the cache in memory; the caibolt package persists it in a file.
+//
+// Turns are kept per chat in reverse chronological order, like FetchMessages returns them. Implementations must
+// not keep references to the chats and turns passed in, nor hand out references to the ones they keep.
+type ChatStore interface {
+	// Chat returns a cached chat, or ErrNotCached
+	Chat(chatID string) (*Chat, error)
+	// Chats returns all cached chats, most recently created first
+	Chats() ([]*Chat, error)
+	// PutChat adds or replaces a chat
+	PutChat(chat *Chat) error
+
+	// Turn returns a cached turn, or ErrNotCached
+	Turn(chatID, turnID string) (*Turn, error)
+	// Turns returns the cached turns of a chat, newest first
+	Turns(chatID string) ([]*Turn, error)
+	// PutTurns adds or replaces turns given newest first. Cached turns are replaced in place; new turns are
+	// placed before all cached turns, keeping their order.
+	PutTurns(chatID string, turns []*Turn) error
+	// ReplaceTurns replaces all cached turns of a chat with turns given newest first
+	ReplaceTurns(chatID string, turns []*Turn) error
+	// DeleteTurns removes turns of a chat; unknown IDs are ignored
+	DeleteTurns(chatID string, turnIDs []string) error
+
+	// SyncTime returns when the turns of a chat were last synced completely; zero if they never were
+	SyncTime(chatID string) (time.Time, error)
+	// SetSyncTime records when the turns of a chat were synced completely
+	SetSyncTime(chatID string, syncTime time.Time) error
+	// SyncedTurn returns the ID of the newest turn fetched by the last sync of a chat; empty if there is none
+	SyncedTurn(chatID string) (string, error)
+	// SetSyncedTurn records the ID of the newest turn fetched by a sync of a chat
+	SetSyncedTurn(chatID, turnID string) error
+}
+
+// errNoChatStore is returned by reads of the cache if the client has no ChatStore
+var errNoChatStore = errors.New("no chat store configured")
+
+// SyncChat calls SyncChatContext with context.Background().
+func (c *Client) SyncChat(chatID string) ([]*Turn, error) {
+	return c.SyncChatContext(context.Background(), chatID)
+}
+
+// SyncChatContext brings the cached copy of a chat up to date and returns its turns, newest first.
+// The first sync of a chat fetches all of its turns. Later syncs page back from the newest turn only until they
+// reach the newest turn fetched by the previous sync. Turns the client recorded since do not end the sync early,
+// as turns written by other clients may have been added in between. Older turns are kept as cached, including
+// the edits and deletions the client recorded in the meantime. If the newest turn of the previous sync is not
+// found on the server anymore, the chat is fetched completely again.
+func (c *Client) SyncChatContext(ctx context.Context, chatID string) ([]*Turn, error) {
+	if c.store == nil {
+		return nil, errNoChatStore
+	}
+
+	chat, err := c.FetchChatContext(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.recorder.flush()
+	syncTime, err := c.store.SyncTime(chatID)
+	if err != nil {
+		return nil, err
+	}
+	var cached []*Turn
+	var syncedTurnID string
+	syncedIndex := -1
+	if !syncTime.IsZero() {
+		cached, err = c.store.Turns(chatID)
+		if err != nil {
+			return nil, err
+		}
+		syncedTurnID, err = c.store.SyncedTurn(chatID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, turn := range cached {
+		if turn.TurnKey.TurnID == syncedTurnID {
+			syncedIndex = i
+		}
+	}
+
+	startTime := time.Now()
+	var fetched []*Turn
+	it := c.MessagesPager(chatID, false).Iterate(ctx)
+	defer it.Close()
+	for it.Next() {
+		turn := it.Item()
+		fetched = append(fetched, turn)
+		if syncedIndex >= 0 && turn.TurnKey.TurnID == syncedTurnID {
+			// The turn may have changed since, so it is stored again along with the newer ones
+			return c.storeSync(chat, fetched, cached[:syncedIndex], startTime)
+		}
+	}
+	if err = it.Err(); err != nil {
+		return nil, err
+	}
+
+	err = c.store.PutChat(chat)
+	if err == nil {
+		err = c.store.ReplaceTurns(chatID, fetched)
+	}
+	if err == nil {
+		err = c.setSynced(chatID, fetched, startTime)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.store.Turns(chatID)
+}
+
+// setSynced records the time of a sync and the newest of the turns it fetched, given newest first
+func (c *Client) setSynced(chatID string, fetched []*Turn, syncTime time.Time) error {
+	var turnID string
+	if len(fetched) > 0 {
+		turnID = fetched[0].TurnKey.TurnID
+	}
+	err := c.store.SetSyncedTurn(chatID, turnID)
+	if err != nil {
+		return err
+	}
+	return c.store.SetSyncTime(chatID, syncTime)
+}
+
+// storeSync stores the turns of an incremental sync. The fetched turns replace the cached turns newer than the
+// newest turn of the previous sync, which drops the ones deleted from the chat and puts the turns recorded since
+// back in order with the turns of other clients.
+func (c *Client) storeSync(chat *Chat, fetched []*Turn, newer []*Turn, syncTime time.Time) ([]*Turn, error) {
+	replaced := make([]string, 0, len(newer))
+	for _, turn := range newer {
+		replaced = append(replaced, turn.TurnKey.TurnID)
+	}
+
+	err := c.store.PutChat(chat)
+	if err == nil && len(replaced) > 0 {
+		err = c.store.DeleteTurns(chat.ChatID, replaced)
+	}
+	if err == nil {
+		err = c.store.PutTurns(chat.ChatID, fetched)
+	}
+	if err == nil {
+		err = c.setSynced(chat.ChatID, fetched, syncTime)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.store.Turns(chat.ChatID)
+}
+
+// SyncAllChats calls SyncAllChatsContext with context.Background().
+func (c *Client) SyncAllChats() ([]*Chat, error) {
+	return c.SyncAllChatsContext(context.Background())
+}
+
+// SyncAllChatsContext syncs every chat of the account into the cache, see SyncChatContext, and returns the chats.
+// Chats are found among the recent chats, the group chats and the chats with the user's characters and with each
+// character of a recent chat.
+func (c *Client) SyncAllChatsContext(ctx context.Context) ([]*Chat, error) {
+	if c.store == nil {
+		return nil, errNoChatStore
+	}
+
+	characters, err := c.FetchMyCharactersContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	characterIDs := make([]string, 0, len(characters))
+	for _, character := range characters {
+		characterIDs = append(characterIDs, character.CharacterID)
+	}
+
+	chats, err := c.discoverChats(ctx, characterIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, chat := range chats {
+		if _, err = c.SyncChatContext(ctx, chat.ChatID); err != nil {
+			return nil, err
+		}
+	}
+	return chats, nil
+}
+
+// discoverChats finds the chats of the account. The service lists chats per character, so the chats with the
+// given characters and with the characters of the recent chats are collected, along with the group chats.
+func (c *Client) discoverChats(ctx context.Context, characterIDs []string) ([]*Chat, error) {
+	recent, err := c.FetchRecentChatsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := c.FetchGroupChatsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var chats []*Chat
+	seenChats := make(map[string]bool)
+	add := func(found []*Chat) {
+		for _, chat := range found {
+			if !seenChats[chat.ChatID] {
+				seenChats[chat.ChatID] = true
+				chats = append(chats, chat)
+			}
+		}
+	}
+	add(recent)
+	add(groups)
+
+	seenCharacters := make(map[string]bool)
+	for _, chat := range recent {
+		if chat.ChatType != ChatTypeGroup && chat.CharacterID != "" {
+			characterIDs = append(characterIDs, chat.CharacterID)
+		}
+	}
+	for _, characterID := range characterIDs {
+		if seenCharacters[characterID] {
+			continue
+		}
+		seenCharacters[characterID] = true
+
+		found, err := c.FetchChatsContext(ctx, characterID, 0)
+		if err != nil {
+			return nil, err
+		}
+		add(found)
+	}
+	return chats, nil
+}
+
+// CachedChat returns a chat from the cache without contacting the service, or ErrNotCached.
+func (c *Client) CachedChat(chatID string) (*Chat, error) {
+	if c.store == nil {
+		return nil, errNoChatStore
+	}
+	c.recorder.flush()
+	return c.store.Chat(chatID)
+}
+
+// CachedChats returns all chats in the cache without contacting the service, most recently created first.
+func (c *Client) CachedChats() ([]*Chat, error) {
+	if c.store == nil {
+		return nil, errNoChatStore
+	}
+	c.recorder.flush()
+	return c.store.Chats()
+}
+
+// CachedMessages returns the turns of a chat from the cache without contacting the service, newest first.
+// It returns ErrNotCached if the chat was never synced. The turns reflect the last sync and the changes the
+// client has seen since.
+func (c *Client) CachedMessages(chatID string) ([]*Turn, error) {
+	if c.store == nil {
+		return nil, errNoChatStore
+	}
+	c.recorder.flush()
+	syncTime, err := c.store.SyncTime(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if syncTime.IsZero() {
+		return nil, ErrNotCached
+	}
+	return c.store.Turns(chatID)
+}
+
+// cachedFrame holds the fields of an incoming frame which change cached turns
+type cachedFrame struct {
+	Command string   `json:"command"`
+	Turn    *Turn    `json:"turn"`
+	ChatID  string   `json:"chat_id"`
+	TurnIDs []string `json:"turn_ids"`
+}
+
+// frameRecorder applies incoming frames to the cache in order on a goroutine of its own, so that the read loop of
+// the requester never waits for the ChatStore. The goroutine runs while frames are pending.
+type frameRecorder struct {
+	client  *Client
+	mutex   sync.Mutex
+	queue   [][]byte
+	running bool
+	idle    chan struct{} // Closed once the goroutine has drained the queue
+}
+
+// add queues a frame for recording; it does not block
+func (r *frameRecorder) add(frame []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.queue = append(r.queue, frame)
+	if !r.running {
+		r.running = true
+		r.idle = make(chan struct{})
+		go r.run()
+	}
+}
+
+// run records the queued frames in batches until the queue is empty
+func (r *frameRecorder) run() {
+	for {
+		r.mutex.Lock()
+		frames := r.queue
+		r.queue = nil
+		if len(frames) == 0 {
+			r.running = false
+			close(r.idle)
+			r.mutex.Unlock()
+			return
+		}
+		r.mutex.Unlock()
+
+		for _, frame := range frames {
+			r.client.recordFrame(frame)
+		}
+	}
+}
+
+// flush waits until the frames received so far are recorded, so that reads of the cache see them
+func (r *frameRecorder) flush() {
+	r.mutex.Lock()
+	idle := r.idle
+	r.mutex.Unlock()
+
+	if idle != nil {
+		<-idle
+	}
+}
+
+// recordFrame applies the turn changes announced by an incoming frame to the cache.
+// Turns still being generated are recorded once their primary candidate is final.
+func (c *Client) recordFrame(frame []byte) {
+	var event cachedFrame
+	if err := json.Unmarshal(frame, &event); err != nil {
+		return
+	}
+
+	switch event.Command {
+	case "add_turn", "update_turn":
+		if event.Turn == nil || event.Turn.TurnKey.ChatID == "" {
+			return
+		}
+		if candidate := event.Turn.PrimaryCandidate(); candidate != nil && !candidate.IsFinal {
+			return
+		}
+		c.recordTurn(event.Turn)
+	case "remove_turns_response":
+		if event.ChatID != "" && len(event.TurnIDs) > 0 {
+			_ = c.store.DeleteTurns(event.ChatID, event.TurnIDs)
+		}
+	}
+}
+
+// recordTurn stores a turn, keeping cached candidates the update does not carry
+func (c *Client) recordTurn(turn *Turn) {
+	chatID := turn.TurnKey.ChatID
+	cached, err := c.store.Turn(chatID, turn.TurnKey.TurnID)
+	if err == nil {
+		for _, candidate := range cached.CandidatesList {
+			if _, ok := turn.Candidates[candidate.CandidateID]; !ok {
+				turn.CandidatesList = append(turn.CandidatesList, candidate)
+			}
+		}
+		turn = cloneTurn(turn)
+	}
+	_ = c.store.PutTurns(chatID, []*Turn{turn})
+}
+
+// cloneTurn returns a deep copy of a turn
+func cloneTurn(turn *Turn) *Turn {
+	clone := *turn
+	clone.CandidatesList = append([]TurnCandidate(nil), turn.CandidatesList...)
+	clone.Candidates = make(map[string]*TurnCandidate, len(clone.CandidatesList))
+	for i := range clone.CandidatesList {
+		clone.Candidates[clone.CandidatesList[i].CandidateID] = &clone.CandidatesList[i]
+	}
+	return &clone
+}
+
+// cloneChat returns a deep copy of a chat
+func cloneChat(chat *Chat) *Chat {
+	clone := *chat
+	clone.CharacterIDs = append([]string(nil), chat.CharacterIDs...)
+	clone.PreviewTurns = nil
+	for _, turn := range chat.PreviewTurns {
+		clone.PreviewTurns = append(clone.PreviewTurns, cloneTurn(turn))
+	}
+	if chat.CharacterAvatar != nil {
+		avatar := *chat.CharacterAvatar
+		clone.CharacterAvatar = &avatar
+	}
+	return &clone
+}
+
+// MemoryChatStore is a ChatStore keeping the cache in memory, e.g. for the lifetime of a process.
+// The zero value is not usable; create one with NewMemoryChatStore.
+type MemoryChatStore struct {
+	mutex sync.Mutex
+	chats map[string]*memoryChat
+}
+
+// memoryChat is a chat held by a MemoryChatStore
+type memoryChat struct {
+	chat         *Chat
+	turns        []*Turn // Newest first
+	syncTime     time.Time
+	syncedTurnID string
+}
+
+// NewMemoryChatStore returns an empty MemoryChatStore.
+func NewMemoryChatStore() *MemoryChatStore {
+	return &MemoryChatStore{chats: make(map[string]*memoryChat)}
+}
+
+// entry returns the state of a chat, creating it if needed; the caller must hold the mutex
+func (s *MemoryChatStore) entry(chatID string) *memoryChat {
+	entry, ok := s.chats[chatID]
+	if !ok {
+		entry = &memoryChat{}
+		s.chats[chatID] = entry
+	}
+	return entry
+}
+
+// Chat returns a cached chat, or ErrNotCached
+func (s *MemoryChatStore) Chat(chatID string) (*Chat, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.chats[chatID]
+	if !ok || entry.chat == nil {
+		return nil, ErrNotCached
+	}
+	return cloneChat(entry.chat), nil
+}
+
+// Chats returns all cached chats, most recently created first
+func (s *MemoryChatStore) Chats() ([]*Chat, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var chats []*Chat
+	for _, entry := range s.chats {
+		if entry.chat != nil {
+			chats = append(chats, cloneChat(entry.chat))
+		}
+	}
+	SortChats(chats)
+	return chats, nil
+}
+
+// PutChat adds or replaces a chat
+func (s *MemoryChatStore) PutChat(chat *Chat) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entry(chat.ChatID).chat = cloneChat(chat)
+	return nil
+}
+
+// Turn returns a cached turn, or ErrNotCached
+func (s *MemoryChatStore) Turn(chatID, turnID string) (*Turn, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, ok := s.chats[chatID]; ok {
+		for _, turn := range entry.turns {
+			if turn.TurnKey.TurnID == turnID {
+				return cloneTurn(turn), nil
+			}
+		}
+	}
+	return nil, ErrNotCached
+}
+
+// Turns returns the cached turns of a chat, newest first
+func (s *MemoryChatStore) Turns(chatID string) ([]*Turn, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.chats[chatID]
+	if !ok {
+		return nil, nil
+	}
+	turns := make([]*Turn, len(entry.turns))
+	for i, turn := range entry.turns {
+		turns[i] = cloneTurn(turn)
+	}
+	return turns, nil
+}
+
+// PutTurns adds or replaces turns given newest first
+func (s *MemoryChatStore) PutTurns(chatID string, turns []*Turn) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry := s.entry(chatID)
+	index := make(map[string]int, len(entry.turns))
+	for i, turn := range entry.turns {
+		index[turn.TurnKey.TurnID] = i
+	}
+	var added []*Turn
+	for _, turn := range turns {
+		if i, ok := index[turn.TurnKey.TurnID]; ok {
+			entry.turns[i] = cloneTurn(turn)
+		} else {
+			added = append(added, cloneTurn(turn))
+		}
+	}
+	entry.turns = append(added, entry.turns...)
+	return nil
+}
+
+// ReplaceTurns replaces all cached turns of a chat with turns given newest first
+func (s *MemoryChatStore) ReplaceTurns(chatID string, turns []*Turn) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry := s.entry(chatID)
+	entry.turns = make([]*Turn, len(turns))
+	for i, turn := range turns {
+		entry.turns[i] = cloneTurn(turn)
+	}
+	return nil
+}
+
+// DeleteTurns removes turns of a chat; unknown IDs are ignored
+func (s *MemoryChatStore) DeleteTurns(chatID string, turnIDs []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.chats[chatID]
+	if !ok {
+		return nil
+	}
+	turns := entry.turns[:0]
+	for _, turn := range entry.turns {
+		if !containsID(turnIDs, turn.TurnKey.TurnID) {
+			turns = append(turns, turn)
+		}
+	}
+	entry.turns = turns
+	return nil
+}
+
+// SyncTime returns when the turns of a chat were last synced completely
+func (s *MemoryChatStore) SyncTime(chatID string) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, ok := s.chats[chatID]; ok {
+		return entry.syncTime, nil
+	}
+	return time.Time{}, nil
+}
+
+// SetSyncTime records when the turns of a chat were synced completely
+func (s *MemoryChatStore) SetSyncTime(chatID string, syncTime time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entry(chatID).syncTime = syncTime
+	return nil
+}
+
+// SyncedTurn returns the ID of the newest turn fetched by the last sync of a chat
+func (s *MemoryChatStore) SyncedTurn(chatID string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, ok := s.chats[chatID]; ok {
+		return entry.syncedTurnID, nil
+	}
+	return "", nil
+}
+
+// SetSyncedTurn records the ID of the newest turn fetched by a sync of a chat
+func (s *MemoryChatStore) SetSyncedTurn(chatID, turnID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entry(chatID).syncedTurnID = turnID
+	return nil
+}
+
+// SortChats orders chats most recently created first, as ChatStore.Chats returns them.
+func SortChats(chats []*Chat) {
+	sort.SliceStable(chats, func(i, j int) bool {
+		return chats[i].CreateTime.After(chats[j].CreateTime)
+	})
+}
+
+// containsID reports whether ids contains id
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
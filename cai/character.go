@@ -1,6 +1,7 @@
 package cai
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,9 +11,14 @@ import (
 	"strings"
 )
 
-// FetchCharacterInfo retrieves information about a character.
+// FetchCharacterInfo calls FetchCharacterInfoContext with context.Background().
 func (c *Client) FetchCharacterInfo(characterID string) (*Character, error) {
-	urlStr := "https://plus.character.ai/chat/character/info/"
+	return c.FetchCharacterInfoContext(context.Background(), characterID)
+}
+
+// FetchCharacterInfoContext retrieves information about a character.
+func (c *Client) FetchCharacterInfoContext(ctx context.Context, characterID string) (*Character, error) {
+	urlStr := c.Endpoints.Plus + "/chat/character/info/"
 	headers := c.GetHeaders(false)
 
 	payload := CharacterInfoPayload{
@@ -23,14 +29,14 @@ func (c *Client) FetchCharacterInfo(characterID string) (*Character, error) {
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch character info, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch character info", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -45,25 +51,30 @@ func (c *Client) FetchCharacterInfo(characterID string) (*Character, error) {
 	}
 
 	if result.Status != "OK" {
-		return nil, fmt.Errorf("error fetching character info: %s", result.Error)
+		return nil, newRejectedError("fetch character info", resp, body, result.Error)
 	}
 
 	return result.Character, nil
 }
 
-// FetchCharactersByCategory retrieves characters categorized by curated categories.
+// FetchCharactersByCategory calls FetchCharactersByCategoryContext with context.Background().
 func (c *Client) FetchCharactersByCategory() (map[string][]*CharacterShort, error) {
-	urlStr := "https://plus.character.ai/chat/curated_categories/characters/"
+	return c.FetchCharactersByCategoryContext(context.Background())
+}
+
+// FetchCharactersByCategoryContext retrieves characters categorized by curated categories.
+func (c *Client) FetchCharactersByCategoryContext(ctx context.Context) (map[string][]*CharacterShort, error) {
+	urlStr := c.Endpoints.Plus + "/chat/curated_categories/characters/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch characters by category, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch characters by category", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -83,19 +94,24 @@ func (c *Client) FetchCharactersByCategory() (map[string][]*CharacterShort, erro
 	return result.CharactersByCategory, nil
 }
 
-// FetchRecommendedCharacters retrieves recommended characters for the user.
+// FetchRecommendedCharacters calls FetchRecommendedCharactersContext with context.Background().
 func (c *Client) FetchRecommendedCharacters() ([]*CharacterShort, error) {
-	urlStr := "https://neo.character.ai/recommendation/v1/user"
+	return c.FetchRecommendedCharactersContext(context.Background())
+}
+
+// FetchRecommendedCharactersContext retrieves recommended characters for the user.
+func (c *Client) FetchRecommendedCharactersContext(ctx context.Context) ([]*CharacterShort, error) {
+	urlStr := c.Endpoints.Neo + "/recommendation/v1/user"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch recommended characters, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch recommended characters", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -114,19 +130,24 @@ func (c *Client) FetchRecommendedCharacters() ([]*CharacterShort, error) {
 	return result.Characters, nil
 }
 
-// FetchFeaturedCharacters retrieves featured characters.
+// FetchFeaturedCharacters calls FetchFeaturedCharactersContext with context.Background().
 func (c *Client) FetchFeaturedCharacters() ([]*CharacterShort, error) {
-	urlStr := "https://plus.character.ai/chat/characters/featured_v2/"
+	return c.FetchFeaturedCharactersContext(context.Background())
+}
+
+// FetchFeaturedCharactersContext retrieves featured characters.
+func (c *Client) FetchFeaturedCharactersContext(ctx context.Context) ([]*CharacterShort, error) {
+	urlStr := c.Endpoints.Plus + "/chat/characters/featured_v2/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch featured characters, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch featured characters", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -145,19 +166,24 @@ func (c *Client) FetchFeaturedCharacters() ([]*CharacterShort, error) {
 	return result.Characters, nil
 }
 
-// FetchSimilarCharacters retrieves characters similar to the given character.
+// FetchSimilarCharacters calls FetchSimilarCharactersContext with context.Background().
 func (c *Client) FetchSimilarCharacters(characterID string) ([]*CharacterShort, error) {
-	urlStr := fmt.Sprintf("https://neo.character.ai/recommendation/v1/character/%s", characterID)
+	return c.FetchSimilarCharactersContext(context.Background(), characterID)
+}
+
+// FetchSimilarCharactersContext retrieves characters similar to the given character.
+func (c *Client) FetchSimilarCharactersContext(ctx context.Context, characterID string) ([]*CharacterShort, error) {
+	urlStr := fmt.Sprintf("%s/recommendation/v1/character/%s", c.Endpoints.Neo, characterID)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch similar characters, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch similar characters", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -176,73 +202,119 @@ func (c *Client) FetchSimilarCharacters(characterID string) ([]*CharacterShort,
 	return result.Characters, nil
 }
 
-// SearchCharacters searches for characters by name.
+// SearchCharacters calls SearchCharactersContext with context.Background().
 func (c *Client) SearchCharacters(query string) ([]*CharacterSearchResult, error) {
-	urlStr := fmt.Sprintf("https://plus.character.ai/chat/characters/search/?query=%s", url.QueryEscape(query))
+	return c.SearchCharactersContext(context.Background(), query)
+}
+
+// SearchCharactersContext searches for characters by name, returning the first page of results.
+// See SearchCharactersPager for the others.
+func (c *Client) SearchCharactersContext(ctx context.Context, query string) ([]*CharacterSearchResult, error) {
+	characters, _, err := c.searchCharactersPage(ctx, query, "")
+	return characters, err
+}
+
+// SearchCharactersPager returns a pager over the results of a search for characters by name.
+func (c *Client) SearchCharactersPager(query string) *Pager[*CharacterSearchResult] {
+	return NewPager(func(ctx context.Context, token string) ([]*CharacterSearchResult, string, error) {
+		return c.searchCharactersPage(ctx, query, token)
+	})
+}
+
+// searchCharactersPage retrieves a page of the results of a search for characters
+func (c *Client) searchCharactersPage(ctx context.Context, query string, token string) ([]*CharacterSearchResult, string, error) {
+	page := pageNumber(token)
+	urlStr := fmt.Sprintf("%s/chat/characters/search/?query=%s&page=%d", c.Endpoints.Plus, url.QueryEscape(query), page)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to search characters, status code: %d", resp.StatusCode)
+		return nil, "", newAPIError("search characters", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var result struct {
 		Characters []*CharacterSearchResult `json:"characters"`
+		HasMore    bool                     `json:"has_more"`
 	}
 	err = json.Unmarshal(body, &result)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return result.Characters, nil
+	return result.Characters, nextPageToken(page, result.HasMore), nil
 }
 
-// SearchCreators searches for creators by name.
+// SearchCreators calls SearchCreatorsContext with context.Background().
 func (c *Client) SearchCreators(query string) ([]Creator, error) {
-	urlStr := fmt.Sprintf("https://plus.character.ai/chat/creators/search/?query=%s", url.QueryEscape(query))
+	return c.SearchCreatorsContext(context.Background(), query)
+}
+
+// SearchCreatorsContext searches for creators by name, returning the first page of results.
+// See SearchCreatorsPager for the others.
+func (c *Client) SearchCreatorsContext(ctx context.Context, query string) ([]Creator, error) {
+	creators, _, err := c.searchCreatorsPage(ctx, query, "")
+	return creators, err
+}
+
+// SearchCreatorsPager returns a pager over the results of a search for creators by name.
+func (c *Client) SearchCreatorsPager(query string) *Pager[Creator] {
+	return NewPager(func(ctx context.Context, token string) ([]Creator, string, error) {
+		return c.searchCreatorsPage(ctx, query, token)
+	})
+}
+
+// searchCreatorsPage retrieves a page of the results of a search for creators
+func (c *Client) searchCreatorsPage(ctx context.Context, query string, token string) ([]Creator, string, error) {
+	page := pageNumber(token)
+	urlStr := fmt.Sprintf("%s/chat/creators/search/?query=%s&page=%d", c.Endpoints.Plus, url.QueryEscape(query), page)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to search creators, status code: %d", resp.StatusCode)
+		return nil, "", newAPIError("search creators", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var result SearchCreatorsResponse
 	err = json.Unmarshal(body, &result)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if result.Status != "OK" {
-		return nil, fmt.Errorf("error searching creators: %s", result.Error)
+		return nil, "", newRejectedError("search creators", resp, body, result.Error)
 	}
 
-	return result.Creators, nil
+	return result.Creators, nextPageToken(page, result.HasMore), nil
 }
 
-// CharacterVote casts a vote for a character. Use 'nil' for removing a vote.
+// CharacterVote calls CharacterVoteContext with context.Background().
 func (c *Client) CharacterVote(characterID string, vote *bool) error {
-	urlStr := "https://plus.character.ai/chat/character/vote/"
+	return c.CharacterVoteContext(context.Background(), characterID, vote)
+}
+
+// CharacterVoteContext casts a vote for a character. Use 'nil' for removing a vote.
+func (c *Client) CharacterVoteContext(ctx context.Context, characterID string, vote *bool) error {
+	urlStr := c.Endpoints.Plus + "/chat/character/vote/"
 	headers := c.GetHeaders(false)
 
 	payload := CharacterVotePayload{
@@ -254,14 +326,14 @@ func (c *Client) CharacterVote(characterID string, vote *bool) error {
 		return err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to vote on character, status code: %d", resp.StatusCode)
+		return newAPIError("vote on character", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -276,14 +348,19 @@ func (c *Client) CharacterVote(characterID string, vote *bool) error {
 	}
 
 	if result.Status != "OK" {
-		return fmt.Errorf("error voting on character: %s", result.Error)
+		return newRejectedError("vote on character", resp, body, result.Error)
 	}
 
 	return nil
 }
 
-// CreateCharacter creates a new character.
+// CreateCharacter calls CreateCharacterContext with context.Background().
 func (c *Client) CreateCharacter(name, greeting string, title, description, definition string, copyable bool, visibility, avatarRelPath, defaultVoiceID string) (*Character, error) {
+	return c.CreateCharacterContext(context.Background(), name, greeting, title, description, definition, copyable, visibility, avatarRelPath, defaultVoiceID)
+}
+
+// CreateCharacterContext creates a new character.
+func (c *Client) CreateCharacterContext(ctx context.Context, name, greeting string, title, description, definition string, copyable bool, visibility, avatarRelPath, defaultVoiceID string) (*Character, error) {
 	// Validate inputs
 	if len(name) < 3 || len(name) > 20 {
 		return nil, errors.New("name must be at least 3 characters and no more than 20")
@@ -305,7 +382,7 @@ func (c *Client) CreateCharacter(name, greeting string, title, description, defi
 		return nil, errors.New("definition must be no more than 32000 characters")
 	}
 
-	urlStr := "https://plus.character.ai/chat/character/create/"
+	urlStr := c.Endpoints.Plus + "/chat/character/create/"
 	headers := c.GetHeaders(false)
 
 	payload := CreateCharacterPayload{
@@ -331,14 +408,14 @@ func (c *Client) CreateCharacter(name, greeting string, title, description, defi
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to create character, status code: %d", resp.StatusCode)
+		return nil, newAPIError("create character", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -353,18 +430,23 @@ func (c *Client) CreateCharacter(name, greeting string, title, description, defi
 	}
 
 	if result.Status != "OK" {
-		return nil, fmt.Errorf("failed to create character, error: %s", result.Error)
+		return nil, newRejectedError("create character", resp, body, result.Error)
 	}
 
 	if result.Character == nil {
-		return nil, errors.New("character not returned in response")
+		return nil, fmt.Errorf("%w: character not returned", ErrInvalidResponse)
 	}
 
 	return result.Character, nil
 }
 
-// EditCharacter edits an existing character.
+// EditCharacter calls EditCharacterContext with context.Background().
 func (c *Client) EditCharacter(characterID, name, greeting string, title, description, definition string, copyable bool, visibility, avatarRelPath, defaultVoiceID string) (*Character, error) {
+	return c.EditCharacterContext(context.Background(), characterID, name, greeting, title, description, definition, copyable, visibility, avatarRelPath, defaultVoiceID)
+}
+
+// EditCharacterContext edits an existing character.
+func (c *Client) EditCharacterContext(ctx context.Context, characterID, name, greeting string, title, description, definition string, copyable bool, visibility, avatarRelPath, defaultVoiceID string) (*Character, error) {
 	// Validate inputs
 	if len(name) < 3 || len(name) > 20 {
 		return nil, errors.New("name must be at least 3 characters and no more than 20")
@@ -386,7 +468,7 @@ func (c *Client) EditCharacter(characterID, name, greeting string, title, descri
 		return nil, errors.New("definition must be no more than 32000 characters")
 	}
 
-	urlStr := "https://plus.character.ai/chat/character/update/"
+	urlStr := c.Endpoints.Plus + "/chat/character/update/"
 	headers := c.GetHeaders(false)
 
 	payload := EditCharacterPayload{
@@ -413,14 +495,14 @@ func (c *Client) EditCharacter(characterID, name, greeting string, title, descri
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to edit character, status code: %d", resp.StatusCode)
+		return nil, newAPIError("edit character", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -435,11 +517,11 @@ func (c *Client) EditCharacter(characterID, name, greeting string, title, descri
 	}
 
 	if result.Status != "OK" {
-		return nil, fmt.Errorf("failed to edit character, error: %s", result.Error)
+		return nil, newRejectedError("edit character", resp, body, result.Error)
 	}
 
 	if result.Character == nil {
-		return nil, errors.New("character not returned in response")
+		return nil, fmt.Errorf("%w: character not returned", ErrInvalidResponse)
 	}
 
 	return result.Character, nil
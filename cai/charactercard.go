@@ -0,0 +1,451 @@
+package cai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	_ "image/gif"  // Avatars may be GIF images
+	_ "image/jpeg" // Avatars may be JPEG images
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Character Card V2 constants
+const (
+	CharacterCardSpec        = "chara_card_v2"
+	CharacterCardSpecVersion = "2.0"
+)
+
+// characterCardKeyword is the keyword of the PNG tEXt chunk holding a card
+const characterCardKeyword = "chara"
+
+// characterCardExtension is the key of the card extension preserving character.ai fields
+const characterCardExtension = "characterai"
+
+// characterCardAvatarSize is the size in pixels of the avatar embedding a card
+const characterCardAvatarSize = 400
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// CharacterCard is a character in the Character Card V2 format used by SillyTavern and other frontends
+type CharacterCard struct {
+	Spec        string            `json:"spec"`
+	SpecVersion string            `json:"spec_version"`
+	Data        CharacterCardData `json:"data"`
+}
+
+// CharacterCardData holds the fields of a Character Card V2
+type CharacterCardData struct {
+	Name                    string                 `json:"name"`
+	Description             string                 `json:"description"`
+	Personality             string                 `json:"personality"`
+	Scenario                string                 `json:"scenario"`
+	FirstMes                string                 `json:"first_mes"`
+	MesExample              string                 `json:"mes_example"`
+	CreatorNotes            string                 `json:"creator_notes"`
+	SystemPrompt            string                 `json:"system_prompt"`
+	PostHistoryInstructions string                 `json:"post_history_instructions"`
+	AlternateGreetings      []string               `json:"alternate_greetings"`
+	Tags                    []string               `json:"tags"`
+	Creator                 string                 `json:"creator"`
+	CharacterVersion        string                 `json:"character_version"`
+	Extensions              map[string]interface{} `json:"extensions"`
+}
+
+// characterCardV1 holds the fields of a Character Card V1, which are the top-level fields of the card
+type characterCardV1 struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Personality string `json:"personality"`
+	Scenario    string `json:"scenario"`
+	FirstMes    string `json:"first_mes"`
+	MesExample  string `json:"mes_example"`
+}
+
+// characterCardExtensionData preserves the character.ai fields without a Character Card V2 equivalent
+type characterCardExtensionData struct {
+	ExternalID string `json:"external_id,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Visibility string `json:"visibility,omitempty"`
+	Copyable   bool   `json:"copyable"`
+}
+
+// NewCharacterCard converts a character to a Character Card V2.
+// The greeting becomes the first message, the definition the example messages and the title the creator notes.
+// Fields without a card equivalent are kept in the "characterai" extension, so importing the card restores them.
+func NewCharacterCard(character *Character) *CharacterCard {
+	return &CharacterCard{
+		Spec:        CharacterCardSpec,
+		SpecVersion: CharacterCardSpecVersion,
+		Data: CharacterCardData{
+			Name:               character.Name,
+			Description:        character.Description,
+			FirstMes:           character.Greeting,
+			MesExample:         character.Definition,
+			CreatorNotes:       character.Title,
+			AlternateGreetings: []string{},
+			Tags:               []string{},
+			Creator:            character.AuthorUsername,
+			Extensions: map[string]interface{}{
+				characterCardExtension: characterCardExtensionData{
+					ExternalID: character.ExternalID,
+					Title:      character.Title,
+					Visibility: character.Visibility,
+					Copyable:   character.Copyable,
+				},
+			},
+		},
+	}
+}
+
+// ParseCharacterCard reads a character card from its JSON or from a PNG image embedding it.
+// Character Card V1 cards are converted to V2.
+func ParseCharacterCard(data []byte) (*CharacterCard, error) {
+	if bytes.HasPrefix(data, pngSignature) {
+		text, err := readPNGText(data, characterCardKeyword)
+		if err != nil {
+			return nil, err
+		}
+		data, err = base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid character card encoding: %w", err)
+		}
+	}
+
+	var card CharacterCard
+	err := json.Unmarshal(data, &card)
+	if err != nil {
+		return nil, fmt.Errorf("invalid character card: %w", err)
+	}
+	if card.Spec == CharacterCardSpec {
+		return &card, nil
+	}
+	if card.Spec != "" {
+		return nil, fmt.Errorf("unsupported character card spec %q", card.Spec)
+	}
+
+	var v1 characterCardV1
+	err = json.Unmarshal(data, &v1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid character card: %w", err)
+	}
+	if v1.Name == "" {
+		return nil, errors.New("invalid character card: name missing")
+	}
+	return &CharacterCard{
+		Spec:        CharacterCardSpec,
+		SpecVersion: CharacterCardSpecVersion,
+		Data: CharacterCardData{
+			Name:        v1.Name,
+			Description: v1.Description,
+			Personality: v1.Personality,
+			Scenario:    v1.Scenario,
+			FirstMes:    v1.FirstMes,
+			MesExample:  v1.MesExample,
+			Extensions:  map[string]interface{}{},
+		},
+	}, nil
+}
+
+// CreateCharacterPayload converts the card for creating a character.
+// Cards exported by NewCharacterCard map back to the original fields. For other cards, a description too long
+// for character.ai, the personality and the scenario are moved into the definition, ahead of the example messages.
+// Without a visibility stored in the card, the character is private.
+func (card *CharacterCard) CreateCharacterPayload() CreateCharacterPayload {
+	data := card.Data
+	payload := CreateCharacterPayload{
+		Name:        data.Name,
+		Greeting:    data.FirstMes,
+		Description: data.Description,
+		Visibility:  "PRIVATE",
+		Categories:  []string{},
+		Identifier:  fmt.Sprintf("id:%s", generateUUID()),
+	}
+
+	var extension characterCardExtensionData
+	found := false
+	if raw, ok := data.Extensions[characterCardExtension]; ok {
+		if encoded, err := json.Marshal(raw); err == nil && json.Unmarshal(encoded, &extension) == nil {
+			found = true
+		}
+	}
+	if found {
+		payload.Title = extension.Title
+		payload.Copyable = extension.Copyable
+		if extension.Visibility != "" {
+			payload.Visibility = extension.Visibility
+		}
+	} else if len(data.CreatorNotes) >= 3 && len(data.CreatorNotes) <= 50 {
+		payload.Title = data.CreatorNotes
+	}
+
+	var definition []string
+	if len(payload.Description) > 500 {
+		definition = append(definition, payload.Description)
+		payload.Description = ""
+	}
+	if data.Personality != "" {
+		definition = append(definition, "Personality: "+data.Personality)
+	}
+	if data.Scenario != "" {
+		definition = append(definition, "Scenario: "+data.Scenario)
+	}
+	if data.MesExample != "" {
+		definition = append(definition, data.MesExample)
+	}
+	payload.Definition = strings.Join(definition, "\n\n")
+	return payload
+}
+
+// WriteCharacterCardPNG writes a PNG image with the card embedded in a tEXt chunk.
+// img must be a PNG, JPEG or GIF image; JPEG and GIF images are re-encoded as PNG.
+func WriteCharacterCardPNG(w io.Writer, card *CharacterCard, img []byte) error {
+	if !bytes.HasPrefix(img, pngSignature) {
+		decoded, _, err := image.Decode(bytes.NewReader(img))
+		if err != nil {
+			return fmt.Errorf("unsupported card image: %w", err)
+		}
+		var buffer bytes.Buffer
+		if err = png.Encode(&buffer, decoded); err != nil {
+			return err
+		}
+		img = buffer.Bytes()
+	}
+
+	cardBytes, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+	text := base64.StdEncoding.EncodeToString(cardBytes)
+
+	result, err := writePNGText(img, characterCardKeyword, text)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(result)
+	return err
+}
+
+// FetchCharacterCard calls FetchCharacterCardContext with context.Background().
+func (c *Client) FetchCharacterCard(characterID string) (*CharacterCard, error) {
+	return c.FetchCharacterCardContext(context.Background(), characterID)
+}
+
+// FetchCharacterCardContext fetches a character and converts it to a Character Card V2
+func (c *Client) FetchCharacterCardContext(ctx context.Context, characterID string) (*CharacterCard, error) {
+	character, err := c.FetchCharacterInfoContext(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	return NewCharacterCard(character), nil
+}
+
+// ExportCharacterCardPNG calls ExportCharacterCardPNGContext with context.Background().
+func (c *Client) ExportCharacterCardPNG(characterID string, w io.Writer) error {
+	return c.ExportCharacterCardPNGContext(context.Background(), characterID, w)
+}
+
+// ExportCharacterCardPNGContext fetches a character and writes it as a Character Card V2 PNG, using its avatar
+// as image. Characters without an avatar, or with an avatar in a format that cannot be decoded, get a plain
+// placeholder image instead.
+func (c *Client) ExportCharacterCardPNGContext(ctx context.Context, characterID string, w io.Writer) error {
+	character, err := c.FetchCharacterInfoContext(ctx, characterID)
+	if err != nil {
+		return err
+	}
+
+	var img []byte
+	if character.Avatar != nil {
+		img, err = c.fetchCardImage(ctx, character.Avatar)
+		if err != nil {
+			return err
+		}
+	}
+	if img == nil {
+		img, err = placeholderCardImage()
+		if err != nil {
+			return err
+		}
+	}
+
+	return WriteCharacterCardPNG(w, NewCharacterCard(character), img)
+}
+
+// fetchCardImage returns the avatar image if it is in a format a card can embed, or nil otherwise
+func (c *Client) fetchCardImage(ctx context.Context, avatar *Avatar) ([]byte, error) {
+	resp, err := c.Requester.GetContext(ctx, c.AvatarURL(avatar, characterCardAvatarSize, false), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("fetch avatar", resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err = image.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// placeholderCardImage returns a plain square PNG image for cards of characters without a usable avatar
+func placeholderCardImage() ([]byte, error) {
+	img := image.NewGray(image.Rect(0, 0, characterCardAvatarSize, characterCardAvatarSize))
+	for i := range img.Pix {
+		img.Pix[i] = 0xc0
+	}
+	var buffer bytes.Buffer
+	err := png.Encode(&buffer, img)
+	return buffer.Bytes(), err
+}
+
+// ImportCharacterCard calls ImportCharacterCardContext with context.Background().
+func (c *Client) ImportCharacterCard(data []byte) (*Character, error) {
+	return c.ImportCharacterCardContext(context.Background(), data)
+}
+
+// ImportCharacterCardContext creates a character from a Character Card V2 or V1, given as JSON or PNG.
+// The image of a PNG card is uploaded as the avatar of the character, without the card and other text chunks.
+func (c *Client) ImportCharacterCardContext(ctx context.Context, data []byte) (*Character, error) {
+	card, err := ParseCharacterCard(data)
+	if err != nil {
+		return nil, err
+	}
+	payload := card.CreateCharacterPayload()
+
+	if bytes.HasPrefix(data, pngSignature) {
+		img, err := stripPNGText(data)
+		if err != nil {
+			return nil, err
+		}
+		avatar, err := c.uploadAvatarData(ctx, img, false)
+		if err != nil {
+			return nil, err
+		}
+		payload.AvatarRelPath = avatar.FileName
+	}
+
+	return c.CreateCharacterContext(ctx, payload.Name, payload.Greeting, payload.Title, payload.Description,
+		payload.Definition, payload.Copyable, payload.Visibility, payload.AvatarRelPath, payload.DefaultVoiceID)
+}
+
+// readPNGText returns the text of the first tEXt chunk with the given keyword
+func readPNGText(data []byte, keyword string) (string, error) {
+	chunks, err := pngChunks(data)
+	if err != nil {
+		return "", err
+	}
+	for _, chunk := range chunks {
+		if chunk.kind != "tEXt" {
+			continue
+		}
+		name, text, found := bytes.Cut(chunk.data, []byte{0})
+		if found && string(name) == keyword {
+			return string(text), nil
+		}
+	}
+	return "", fmt.Errorf("%w: no %q text chunk in image", ErrNotFound, keyword)
+}
+
+// writePNGText returns the PNG with a tEXt chunk for keyword holding text, replacing any existing one.
+// The chunk is placed right before the image end.
+func writePNGText(data []byte, keyword, text string) ([]byte, error) {
+	chunks, err := pngChunks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write(pngSignature)
+	for _, chunk := range chunks {
+		if chunk.kind == "tEXt" && bytes.HasPrefix(chunk.data, []byte(keyword+"\x00")) {
+			continue
+		}
+		if chunk.kind == "IEND" {
+			writePNGChunk(&buffer, "tEXt", []byte(keyword+"\x00"+text))
+		}
+		writePNGChunk(&buffer, chunk.kind, chunk.data)
+	}
+	return buffer.Bytes(), nil
+}
+
+// stripPNGText returns the PNG without its text chunks, which hold the card and other metadata
+func stripPNGText(data []byte) ([]byte, error) {
+	chunks, err := pngChunks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write(pngSignature)
+	for _, chunk := range chunks {
+		switch chunk.kind {
+		case "tEXt", "zTXt", "iTXt":
+			continue
+		}
+		writePNGChunk(&buffer, chunk.kind, chunk.data)
+	}
+	return buffer.Bytes(), nil
+}
+
+// pngChunk is a chunk of a PNG file
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+// pngChunks splits a PNG file into its chunks, up to and including IEND
+func pngChunks(data []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errors.New("not a PNG image")
+	}
+
+	var chunks []pngChunk
+	rest := data[len(pngSignature):]
+	for {
+		if len(rest) < 12 {
+			return nil, errors.New("truncated PNG image")
+		}
+		length := binary.BigEndian.Uint32(rest[:4])
+		if uint64(length) > uint64(len(rest)-12) {
+			return nil, errors.New("truncated PNG image")
+		}
+		chunk := pngChunk{kind: string(rest[4:8]), data: rest[8 : 8+length]}
+		chunks = append(chunks, chunk)
+		rest = rest[12+length:]
+		if chunk.kind == "IEND" {
+			return chunks, nil
+		}
+	}
+}
+
+// writePNGChunk writes a PNG chunk with its length and checksum
+func writePNGChunk(w *bytes.Buffer, kind string, data []byte) {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	w.Write(header[:])
+
+	checksum := crc32.NewIEEE()
+	checksum.Write([]byte(kind))
+	checksum.Write(data)
+	w.WriteString(kind)
+	w.Write(data)
+
+	var trailer [4]byte
+	binary.BigEndian.PutUint32(trailer[:], checksum.Sum32())
+	w.Write(trailer[:])
+}
@@ -1,6 +1,7 @@
 package cai
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,12 +11,87 @@ import (
 	"time"
 )
 
+// SendMessage calls SendMessageContext with context.Background().
 func (c *Client) SendMessage(characterID, chatID, text string) (*Turn, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.SendMessageContext(context.Background(), characterID, chatID, text)
+}
+
+// SendMessageContext sends a message to a character and waits for the final reply turn.
+// If ctx is done before the reply is final, its generation is aborted and the partial reply received so far,
+// if any, is returned along with the context's error.
+func (c *Client) SendMessageContext(ctx context.Context, characterID, chatID, text string) (*Turn, error) {
+	events, err := c.SendMessageStreamContext(ctx, characterID, chatID, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return awaitFinalTurn(ctx, events)
+}
+
+// awaitFinalTurn consumes a turn stream and returns the final turn.
+// If ctx is done first, the latest partial turn is returned along with the context's error.
+func awaitFinalTurn(ctx context.Context, events <-chan TurnEvent) (*Turn, error) {
+	var partial *Turn
+	for event := range events {
+		if event.Err != nil {
+			if ctx.Err() != nil {
+				return partial, ctx.Err()
+			}
+			return nil, event.Err
+		}
+		if event.Final {
+			return event.Turn, nil
+		}
+		partial = event.Turn
+	}
+
+	// The stream only ends without a final event if ctx is done
+	return partial, ctx.Err()
+}
+
+// SendMessageStream calls SendMessageStreamContext with context.Background().
+func (c *Client) SendMessageStream(characterID, chatID, text string) (<-chan TurnEvent, error) {
+	return c.SendMessageStreamContext(context.Background(), characterID, chatID, text)
+}
+
+// SendMessageStreamContext sends a message to a character and streams the reply turn as it is generated.
+// Every partial update of the reply is emitted as an event; the last event is either final or carries an error.
+// The channel is closed afterwards. Callers must drain the channel or cancel ctx; cancelling ctx before the reply
+// is final aborts its generation.
+func (c *Client) SendMessageStreamContext(ctx context.Context, characterID, chatID, text string) (<-chan TurnEvent, error) {
+	return c.sendMessageStream(ctx, characterID, chatID, text, GenerationOptions{}, nil)
+}
+
+// SendMessageCandidates calls SendMessageCandidatesContext with context.Background().
+func (c *Client) SendMessageCandidates(characterID, chatID, text string, numCandidates int) (*Turn, error) {
+	return c.SendMessageCandidatesContext(context.Background(), characterID, chatID, text, numCandidates)
+}
+
+// SendMessageCandidatesContext sends a message to a character, having numCandidates alternative replies generated.
+// It waits until all candidates are final. Turn.OrderedCandidates lists them, and UpdatePrimaryCandidate selects one.
+func (c *Client) SendMessageCandidatesContext(ctx context.Context, characterID, chatID, text string, numCandidates int) (*Turn, error) {
+	if numCandidates < 1 {
+		return nil, fmt.Errorf("invalid number of candidates: %d", numCandidates)
+	}
+
+	events, err := c.sendMessageStream(ctx, characterID, chatID, text, GenerationOptions{NumCandidates: numCandidates}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return awaitFinalTurn(ctx, events)
+}
+
+// sendMessageStream sends a message to a character and streams the reply turn generated according to options.
+// If onUserTurn is set, it is called with the turn of the message once the server created it.
+func (c *Client) sendMessageStream(ctx context.Context, characterID, chatID, text string, options GenerationOptions, onUserTurn func(*Turn)) (<-chan TurnEvent, error) {
+	numCandidates, err := options.numCandidates()
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize WebSocket connection if not connected
-	err := c.Requester.InitializeWebSocket()
+	err = c.Requester.InitializeWebSocketContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -31,16 +107,16 @@ func (c *Client) SendMessage(characterID, chatID, text string) (*Turn, error) {
 		RequestID: requestID,
 		Payload: CreateAndGenerateTurnPayload{
 			CharacterID:         characterID,
-			NumCandidates:       1,
-			PreviousAnnotations: generatePreviousAnnotations(),
-			SelectedLanguage:    "",
-			TTSEnabled:          false,
-			UserName:            "",
+			NumCandidates:       numCandidates,
+			PreviousAnnotations: c.previousAnnotations(chatID),
+			SelectedLanguage:    options.Language,
+			TTSEnabled:          options.TTS,
+			UserName:            options.UserName,
 			Turn: TurnPayload{
 				Author: AuthorPayload{
 					AuthorID: c.UserAccountID,
 					IsHuman:  true,
-					Name:     "",
+					Name:     options.UserName,
 				},
 				Candidates: []CandidatePayload{
 					{
@@ -58,58 +134,93 @@ func (c *Client) SendMessage(characterID, chatID, text string) (*Turn, error) {
 	}
 
 	// Send the message
-	err = c.Requester.SendWebSocketMessage(message)
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID})
 	if err != nil {
 		return nil, err
 	}
 
-	// Receive response
+	events := make(chan TurnEvent)
+	go streamTurn(ctx, request, events, onUserTurn)
+
+	return events, nil
+}
+
+// streamTurn forwards the character turn updates received by request as events until the turn is final.
+// Turns of the user are passed to onUserTurn instead, if set. If ctx is done first, the generation is aborted.
+func streamTurn(ctx context.Context, request *WebSocketRequest, events chan<- TurnEvent, onUserTurn func(*Turn)) {
+	defer close(events)
+	defer request.Close()
+	final := false
+	defer func() {
+		if !final && ctx.Err() != nil {
+			request.abortGeneration()
+		}
+	}()
+
+	emit := func(event TurnEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var previousText string
 	for {
-		responseBytes, err := c.Requester.ReceiveRawWebSocketMessage()
+		responseBytes, err := request.Receive(ctx)
 		if err != nil {
-			return nil, err
+			emit(TurnEvent{Err: err})
+			return
 		}
 
 		var response WebSocketResponse
 		err = json.Unmarshal(responseBytes, &response)
 		if err != nil {
-			return nil, err
+			emit(TurnEvent{Err: err})
+			return
 		}
 
 		switch response.Command {
 		case "neo_error":
-			return nil, errors.New(response.Comment)
+			emit(TurnEvent{Err: newNeoError(request, response, responseBytes)})
+			return
 		case "add_turn", "update_turn":
 			var result TurnResponsePayload
 			err = json.Unmarshal(responseBytes, &result)
 			if err != nil {
-				return nil, err
+				emit(TurnEvent{Err: err})
+				return
 			}
 			if result.Turn.Author.IsHuman {
 				// Skip initial response by the user
+				if onUserTurn != nil {
+					onUserTurn(&result.Turn)
+				}
 				continue
 			}
-			// TODO: This only works for 1on1 conversations currently
-			var isFinal = false
-			for _, candidate := range result.Turn.Candidates {
-				if candidate.IsFinal {
-					isFinal = true
-				}
+			// Each request generates a single character turn; group chats request one per speaker
+			event := newTurnEvent(&result.Turn, previousText)
+			if candidate := result.Turn.PrimaryCandidate(); candidate != nil {
+				previousText = candidate.Text
 			}
-			if isFinal {
-				return &result.Turn, nil
+			final = event.Final
+			if !emit(event) || final {
+				return
 			}
 		}
 	}
 }
 
-// CreateChat creates a new chat with a character
+// CreateChat calls CreateChatContext with context.Background().
 func (c *Client) CreateChat(characterID string, greeting bool) (*Chat, *Turn, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.CreateChatContext(context.Background(), characterID, greeting)
+}
 
+// CreateChatContext creates a new chat with a character
+func (c *Client) CreateChatContext(ctx context.Context, characterID string, greeting bool) (*Chat, *Turn, error) {
 	// Initialize WebSocket connection if not connected
-	err := c.Requester.InitializeWebSocket()
+	err := c.Requester.InitializeWebSocketContext(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -127,24 +238,25 @@ func (c *Client) CreateChat(characterID string, greeting bool) (*Chat, *Turn, er
 				CreatorID:   c.UserAccountID,
 				Visibility:  "VISIBILITY_PRIVATE",
 				CharacterID: characterID,
-				Type:        "TYPE_ONE_ON_ONE",
+				Type:        ChatTypeOneOnOne,
 			},
 			WithGreeting: greeting,
 		},
 	}
 
 	// Send the message
-	err = c.Requester.SendWebSocketMessage(message)
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID})
 	if err != nil {
 		return nil, nil, err
 	}
+	defer request.Close()
 
 	var newChat *Chat
 	var greetingTurn *Turn
 
 	// Receive response
 	for {
-		responseBytes, err := c.Requester.ReceiveRawWebSocketMessage()
+		responseBytes, err := request.Receive(ctx)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -157,7 +269,7 @@ func (c *Client) CreateChat(characterID string, greeting bool) (*Chat, *Turn, er
 
 		switch response.Command {
 		case "neo_error":
-			return nil, nil, errors.New(response.Comment)
+			return nil, nil, newNeoError(request, response, responseBytes)
 		case "create_chat_response":
 			var payload CreateChatResponsePayload
 			err = json.Unmarshal(responseBytes, &payload)
@@ -182,39 +294,59 @@ func (c *Client) CreateChat(characterID string, greeting bool) (*Chat, *Turn, er
 	}
 }
 
-// FetchHistories retrieves chat histories for a character
+// FetchHistories calls FetchHistoriesContext with context.Background().
 func (c *Client) FetchHistories(characterID string, amount int) ([]ChatHistory, error) {
-	urlStr := "https://plus.character.ai/chat/character/histories/"
+	return c.FetchHistoriesContext(context.Background(), characterID, amount)
+}
+
+// FetchHistoriesContext retrieves the first amount chat histories for a character, see HistoriesPager for the others
+func (c *Client) FetchHistoriesContext(ctx context.Context, characterID string, amount int) ([]ChatHistory, error) {
+	histories, _, err := c.fetchHistoriesPage(ctx, characterID, amount, "")
+	return histories, err
+}
+
+// HistoriesPager returns a pager over the chat histories for a character, fetching pageSize histories per page.
+func (c *Client) HistoriesPager(characterID string, pageSize int) *Pager[ChatHistory] {
+	return NewPager(func(ctx context.Context, token string) ([]ChatHistory, string, error) {
+		return c.fetchHistoriesPage(ctx, characterID, pageSize, token)
+	})
+}
+
+// fetchHistoriesPage retrieves a page of chat histories for a character
+func (c *Client) fetchHistoriesPage(ctx context.Context, characterID string, amount int, token string) ([]ChatHistory, string, error) {
+	page := pageNumber(token)
+	urlStr := c.Endpoints.Plus + "/chat/character/histories/"
 	headers := c.GetHeaders(false)
 
 	payload := FetchHistoriesRequest{
 		ExternalID: characterID,
 		Number:     amount,
+		Page:       page,
 	}
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch histories, status code: %d", resp.StatusCode)
+		return nil, "", newAPIError("fetch histories", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var result FetchHistoriesResponse
 	err = json.Unmarshal(body, &result)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Parse time strings to time.Time
@@ -222,30 +354,35 @@ func (c *Client) FetchHistories(characterID string, amount int) ([]ChatHistory,
 		history := &result.Histories[i]
 		history.CreateTime, err = time.Parse(time.RFC3339Nano, history.CreateTimeStr)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		history.LastInteraction, err = time.Parse(time.RFC3339Nano, history.LastInteractionStr)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 	}
 
-	return result.Histories, nil
+	return result.Histories, nextPageToken(page, result.HasMore), nil
 }
 
-// FetchChats retrieves chats for a character
+// FetchChats calls FetchChatsContext with context.Background().
 func (c *Client) FetchChats(characterID string, numPreviewTurns int) ([]*Chat, error) {
-	urlStr := fmt.Sprintf("https://neo.character.ai/chats/?character_ids=%s&num_preview_turns=%d", characterID, numPreviewTurns)
+	return c.FetchChatsContext(context.Background(), characterID, numPreviewTurns)
+}
+
+// FetchChatsContext retrieves chats for a character
+func (c *Client) FetchChatsContext(ctx context.Context, characterID string, numPreviewTurns int) ([]*Chat, error) {
+	urlStr := fmt.Sprintf("%s/chats/?character_ids=%s&num_preview_turns=%d", c.Endpoints.Neo, characterID, numPreviewTurns)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch chats, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch chats", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -272,19 +409,24 @@ func (c *Client) FetchChats(characterID string, numPreviewTurns int) ([]*Chat, e
 	return result.Chats, nil
 }
 
-// FetchChat retrieves a chat by its ID
+// FetchChat calls FetchChatContext with context.Background().
 func (c *Client) FetchChat(chatID string) (*Chat, error) {
-	urlStr := fmt.Sprintf("https://neo.character.ai/chat/%s/", chatID)
+	return c.FetchChatContext(context.Background(), chatID)
+}
+
+// FetchChatContext retrieves a chat by its ID
+func (c *Client) FetchChatContext(ctx context.Context, chatID string) (*Chat, error) {
+	urlStr := fmt.Sprintf("%s/chat/%s/", c.Endpoints.Neo, chatID)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch chat, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch chat", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -318,19 +460,24 @@ func (c *Client) FetchChat(chatID string) (*Chat, error) {
 	return chat, nil
 }
 
-// FetchRecentChats retrieves recent chats for the user
+// FetchRecentChats calls FetchRecentChatsContext with context.Background().
 func (c *Client) FetchRecentChats() ([]*Chat, error) {
-	urlStr := "https://neo.character.ai/chats/recent/"
+	return c.FetchRecentChatsContext(context.Background())
+}
+
+// FetchRecentChatsContext retrieves recent chats for the user
+func (c *Client) FetchRecentChatsContext(ctx context.Context) ([]*Chat, error) {
+	urlStr := c.Endpoints.Neo + "/chats/recent/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch recent chats, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch recent chats", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -357,22 +504,27 @@ func (c *Client) FetchRecentChats() ([]*Chat, error) {
 	return result.Chats, nil
 }
 
-// FetchMessages retrieves messages from a chat
+// FetchMessages calls FetchMessagesContext with context.Background().
 func (c *Client) FetchMessages(chatID string, pinnedOnly bool, nextToken string) ([]*Turn, string, error) {
-	urlStr := fmt.Sprintf("https://neo.character.ai/turns/%s/", chatID)
+	return c.FetchMessagesContext(context.Background(), chatID, pinnedOnly, nextToken)
+}
+
+// FetchMessagesContext retrieves messages from a chat
+func (c *Client) FetchMessagesContext(ctx context.Context, chatID string, pinnedOnly bool, nextToken string) ([]*Turn, string, error) {
+	urlStr := fmt.Sprintf("%s/turns/%s/", c.Endpoints.Neo, chatID)
 	if nextToken != "" {
 		urlStr += fmt.Sprintf("?next_token=%s", url.QueryEscape(nextToken))
 	}
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("failed to fetch messages, status code: %d", resp.StatusCode)
+		return nil, "", newAPIError("fetch messages", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -397,27 +549,37 @@ func (c *Client) FetchMessages(chatID string, pinnedOnly bool, nextToken string)
 	return turns, result.Meta.NextToken, nil
 }
 
-// FetchAllMessages retrieves all messages from a chat
+// MessagesPager returns a pager over the messages of a chat, newest first. Pages are fetched as the iteration
+// reaches them, so it can stop at any turn without fetching older ones.
+func (c *Client) MessagesPager(chatID string, pinnedOnly bool) *Pager[*Turn] {
+	return NewPager(func(ctx context.Context, token string) ([]*Turn, string, error) {
+		return c.FetchMessagesContext(ctx, chatID, pinnedOnly, token)
+	})
+}
+
+// FetchAllMessages calls FetchAllMessagesContext with context.Background().
 func (c *Client) FetchAllMessages(chatID string, pinnedOnly bool) ([]*Turn, error) {
-	var allTurns []*Turn
-	var nextToken string
-	for {
-		turns, token, err := c.FetchMessages(chatID, pinnedOnly, nextToken)
-		if err != nil {
-			return nil, err
-		}
-		allTurns = append(allTurns, turns...)
-		if token == "" {
-			break
-		}
-		nextToken = token
+	return c.FetchAllMessagesContext(context.Background(), chatID, pinnedOnly)
+}
+
+// FetchAllMessagesContext retrieves all messages from a chat, newest first.
+// If the client has a ChatStore, all messages are served from the cache after syncing it, see SyncChatContext.
+func (c *Client) FetchAllMessagesContext(ctx context.Context, chatID string, pinnedOnly bool) ([]*Turn, error) {
+	if c.store != nil && !pinnedOnly {
+		return c.SyncChatContext(ctx, chatID)
 	}
-	return allTurns, nil
+
+	return c.MessagesPager(chatID, pinnedOnly).Collect(ctx)
 }
 
-// UpdateChatName updates the name of a chat
+// UpdateChatName calls UpdateChatNameContext with context.Background().
 func (c *Client) UpdateChatName(chatID string, name string) error {
-	urlStr := fmt.Sprintf("https://neo.character.ai/chat/%s/update_name", chatID)
+	return c.UpdateChatNameContext(context.Background(), chatID, name)
+}
+
+// UpdateChatNameContext updates the name of a chat
+func (c *Client) UpdateChatNameContext(ctx context.Context, chatID string, name string) error {
+	urlStr := fmt.Sprintf("%s/chat/%s/update_name", c.Endpoints.Neo, chatID)
 	headers := c.GetHeaders(false)
 
 	payload := UpdateChatNamePayload{
@@ -428,58 +590,73 @@ func (c *Client) UpdateChatName(chatID string, name string) error {
 		return err
 	}
 
-	resp, err := c.Requester.DoRequest("PATCH", urlStr, headers, bodyBytes)
+	resp, err := c.Requester.DoRequestContext(ctx, "PATCH", urlStr, headers, bodyBytes)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to update chat name, status code: %d", resp.StatusCode)
+		return newAPIError("update chat name", resp)
 	}
 
 	return nil
 }
 
-// ArchiveChat archives a chat
+// ArchiveChat calls ArchiveChatContext with context.Background().
 func (c *Client) ArchiveChat(chatID string) error {
-	urlStr := fmt.Sprintf("https://neo.character.ai/chat/%s/archive", chatID)
+	return c.ArchiveChatContext(context.Background(), chatID)
+}
+
+// ArchiveChatContext archives a chat
+func (c *Client) ArchiveChatContext(ctx context.Context, chatID string) error {
+	urlStr := fmt.Sprintf("%s/chat/%s/archive", c.Endpoints.Neo, chatID)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.DoRequest("PATCH", urlStr, headers, nil)
+	resp, err := c.Requester.DoRequestContext(ctx, "PATCH", urlStr, headers, nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to archive chat, status code: %d", resp.StatusCode)
+		return newAPIError("archive chat", resp)
 	}
 
 	return nil
 }
 
-// UnarchiveChat unarchives a chat
+// UnarchiveChat calls UnarchiveChatContext with context.Background().
 func (c *Client) UnarchiveChat(chatID string) error {
-	urlStr := fmt.Sprintf("https://neo.character.ai/chat/%s/unarchive", chatID)
+	return c.UnarchiveChatContext(context.Background(), chatID)
+}
+
+// UnarchiveChatContext unarchives a chat
+func (c *Client) UnarchiveChatContext(ctx context.Context, chatID string) error {
+	urlStr := fmt.Sprintf("%s/chat/%s/unarchive", c.Endpoints.Neo, chatID)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.DoRequest("PATCH", urlStr, headers, nil)
+	resp, err := c.Requester.DoRequestContext(ctx, "PATCH", urlStr, headers, nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to unarchive chat, status code: %d", resp.StatusCode)
+		return newAPIError("unarchive chat", resp)
 	}
 
 	return nil
 }
 
-// CopyChat copies a chat up to a specific turn
+// CopyChat calls CopyChatContext with context.Background().
 func (c *Client) CopyChat(chatID string, endTurnID string) (string, error) {
-	urlStr := fmt.Sprintf("https://neo.character.ai/chat/%s/copy", chatID)
+	return c.CopyChatContext(context.Background(), chatID, endTurnID)
+}
+
+// CopyChatContext copies a chat up to a specific turn
+func (c *Client) CopyChatContext(ctx context.Context, chatID string, endTurnID string) (string, error) {
+	urlStr := fmt.Sprintf("%s/chat/%s/copy", c.Endpoints.Neo, chatID)
 	headers := c.GetHeaders(false)
 
 	payload := CopyChatRequest{
@@ -490,14 +667,14 @@ func (c *Client) CopyChat(chatID string, endTurnID string) (string, error) {
 		return "", err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to copy chat, status code: %d", resp.StatusCode)
+		return "", newAPIError("copy chat", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -514,13 +691,15 @@ func (c *Client) CopyChat(chatID string, endTurnID string) (string, error) {
 	return result.NewChatID, nil
 }
 
-// UpdatePrimaryCandidate updates the primary candidate of a turn
+// UpdatePrimaryCandidate calls UpdatePrimaryCandidateContext with context.Background().
 func (c *Client) UpdatePrimaryCandidate(chatID string, turnID string, candidateID string) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.UpdatePrimaryCandidateContext(context.Background(), chatID, turnID, candidateID)
+}
 
+// UpdatePrimaryCandidateContext updates the primary candidate of a turn
+func (c *Client) UpdatePrimaryCandidateContext(ctx context.Context, chatID string, turnID string, candidateID string) error {
 	// Initialize WebSocket connection if not connected
-	err := c.Requester.InitializeWebSocket()
+	err := c.Requester.InitializeWebSocketContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -542,14 +721,15 @@ func (c *Client) UpdatePrimaryCandidate(chatID string, turnID string, candidateI
 	}
 
 	// Send the message
-	err = c.Requester.SendWebSocketMessage(message)
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID, TurnID: turnID})
 	if err != nil {
 		return err
 	}
+	defer request.Close()
 
 	// Receive response
 	for {
-		responseBytes, err := c.Requester.ReceiveRawWebSocketMessage()
+		responseBytes, err := request.Receive(ctx)
 		if err != nil {
 			return err
 		}
@@ -562,20 +742,22 @@ func (c *Client) UpdatePrimaryCandidate(chatID string, turnID string, candidateI
 
 		switch response.Command {
 		case "neo_error":
-			return errors.New(response.Comment)
+			return newNeoError(request, response, responseBytes)
 		case "ok":
 			return nil
 		}
 	}
 }
 
-// EditMessage edits a message in a turn
+// EditMessage calls EditMessageContext with context.Background().
 func (c *Client) EditMessage(chatID string, turnID string, candidateID string, text string) (*Turn, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.EditMessageContext(context.Background(), chatID, turnID, candidateID, text)
+}
 
+// EditMessageContext edits a message in a turn
+func (c *Client) EditMessageContext(ctx context.Context, chatID string, turnID string, candidateID string, text string) (*Turn, error) {
 	// Initialize WebSocket connection if not connected
-	err := c.Requester.InitializeWebSocket()
+	err := c.Requester.InitializeWebSocketContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -598,14 +780,15 @@ func (c *Client) EditMessage(chatID string, turnID string, candidateID string, t
 	}
 
 	// Send the message
-	err = c.Requester.SendWebSocketMessage(message)
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID, TurnID: turnID})
 	if err != nil {
 		return nil, err
 	}
+	defer request.Close()
 
 	// Receive response
 	for {
-		responseBytes, err := c.Requester.ReceiveRawWebSocketMessage()
+		responseBytes, err := request.Receive(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -618,7 +801,7 @@ func (c *Client) EditMessage(chatID string, turnID string, candidateID string, t
 
 		switch response.Command {
 		case "neo_error":
-			return nil, errors.New(response.Comment)
+			return nil, newNeoError(request, response, responseBytes)
 		case "update_turn":
 			var payload TurnResponsePayload
 			err = json.Unmarshal(responseBytes, &payload)
@@ -630,13 +813,15 @@ func (c *Client) EditMessage(chatID string, turnID string, candidateID string, t
 	}
 }
 
-// DeleteMessages deletes messages from a chat
+// DeleteMessages calls DeleteMessagesContext with context.Background().
 func (c *Client) DeleteMessages(chatID string, turnIDs []string) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.DeleteMessagesContext(context.Background(), chatID, turnIDs)
+}
 
+// DeleteMessagesContext deletes messages from a chat
+func (c *Client) DeleteMessagesContext(ctx context.Context, chatID string, turnIDs []string) error {
 	// Initialize WebSocket connection if not connected
-	err := c.Requester.InitializeWebSocket()
+	err := c.Requester.InitializeWebSocketContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -655,14 +840,15 @@ func (c *Client) DeleteMessages(chatID string, turnIDs []string) error {
 	}
 
 	// Send the message
-	err = c.Requester.SendWebSocketMessage(message)
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID})
 	if err != nil {
 		return err
 	}
+	defer request.Close()
 
 	// Receive response
 	for {
-		responseBytes, err := c.Requester.ReceiveRawWebSocketMessage()
+		responseBytes, err := request.Receive(ctx)
 		if err != nil {
 			return err
 		}
@@ -675,24 +861,37 @@ func (c *Client) DeleteMessages(chatID string, turnIDs []string) error {
 
 		switch response.Command {
 		case "neo_error":
-			return errors.New(response.Comment)
+			return newNeoError(request, response, responseBytes)
 		case "remove_turns_response":
+			if c.store != nil {
+				// Recorded frames of the turns must not bring them back
+				c.recorder.flush()
+				_ = c.store.DeleteTurns(chatID, turnIDs)
+			}
 			return nil
 		}
 	}
 }
 
+// DeleteMessage calls DeleteMessageContext with context.Background().
 func (c *Client) DeleteMessage(chatID string, turnID string) error {
-	return c.DeleteMessages(chatID, []string{turnID})
+	return c.DeleteMessageContext(context.Background(), chatID, turnID)
+}
+
+// DeleteMessageContext deletes a single message from a chat.
+func (c *Client) DeleteMessageContext(ctx context.Context, chatID string, turnID string) error {
+	return c.DeleteMessagesContext(ctx, chatID, []string{turnID})
 }
 
-// PinMessage pins a message in a chat
+// PinMessage calls PinMessageContext with context.Background().
 func (c *Client) PinMessage(chatID string, turnID string) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.PinMessageContext(context.Background(), chatID, turnID)
+}
 
+// PinMessageContext pins a message in a chat
+func (c *Client) PinMessageContext(ctx context.Context, chatID string, turnID string) error {
 	// Initialize WebSocket connection if not connected
-	err := c.Requester.InitializeWebSocket()
+	err := c.Requester.InitializeWebSocketContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -714,14 +913,15 @@ func (c *Client) PinMessage(chatID string, turnID string) error {
 	}
 
 	// Send the message
-	err = c.Requester.SendWebSocketMessage(message)
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID, TurnID: turnID})
 	if err != nil {
 		return err
 	}
+	defer request.Close()
 
 	// Receive response
 	for {
-		responseBytes, err := c.Requester.ReceiveRawWebSocketMessage()
+		responseBytes, err := request.Receive(ctx)
 		if err != nil {
 			return err
 		}
@@ -734,7 +934,7 @@ func (c *Client) PinMessage(chatID string, turnID string) error {
 
 		switch response.Command {
 		case "neo_error":
-			return errors.New(response.Comment)
+			return newNeoError(request, response, responseBytes)
 		case "update_turn":
 			var payload TurnResponsePayload
 			err = json.Unmarshal(responseBytes, &payload)
@@ -749,13 +949,15 @@ func (c *Client) PinMessage(chatID string, turnID string) error {
 	}
 }
 
-// UnpinMessage unpins a message in a chat
+// UnpinMessage calls UnpinMessageContext with context.Background().
 func (c *Client) UnpinMessage(chatID string, turnID string) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.UnpinMessageContext(context.Background(), chatID, turnID)
+}
 
+// UnpinMessageContext unpins a message in a chat
+func (c *Client) UnpinMessageContext(ctx context.Context, chatID string, turnID string) error {
 	// Initialize WebSocket connection if not connected
-	err := c.Requester.InitializeWebSocket()
+	err := c.Requester.InitializeWebSocketContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -777,14 +979,15 @@ func (c *Client) UnpinMessage(chatID string, turnID string) error {
 	}
 
 	// Send the message
-	err = c.Requester.SendWebSocketMessage(message)
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID, TurnID: turnID})
 	if err != nil {
 		return err
 	}
+	defer request.Close()
 
 	// Receive response
 	for {
-		responseBytes, err := c.Requester.ReceiveRawWebSocketMessage()
+		responseBytes, err := request.Receive(ctx)
 		if err != nil {
 			return err
 		}
@@ -797,7 +1000,7 @@ func (c *Client) UnpinMessage(chatID string, turnID string) error {
 
 		switch response.Command {
 		case "neo_error":
-			return errors.New(response.Comment)
+			return newNeoError(request, response, responseBytes)
 		case "update_turn":
 			var payload TurnResponsePayload
 			err = json.Unmarshal(responseBytes, &payload)
@@ -812,12 +1015,22 @@ func (c *Client) UnpinMessage(chatID string, turnID string) error {
 	}
 }
 
+// AnotherResponse calls AnotherResponseContext with context.Background().
 func (c *Client) AnotherResponse(characterID, chatID, turnID string) (*Turn, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.AnotherResponseContext(context.Background(), characterID, chatID, turnID)
+}
+
+// AnotherResponseContext requests an alternative candidate for a character turn and waits until it is complete.
+// The new candidate becomes the primary candidate of the returned turn. If ctx is done first, the generation is
+// aborted and the turn with the partial candidate received so far, if any, is returned along with the context's error.
+func (c *Client) AnotherResponseContext(ctx context.Context, characterID, chatID, turnID string) (*Turn, error) {
+	return c.anotherResponse(ctx, characterID, chatID, turnID, GenerationOptions{})
+}
 
+// anotherResponse requests an alternative candidate for a character turn, generated according to options
+func (c *Client) anotherResponse(ctx context.Context, characterID, chatID, turnID string, options GenerationOptions) (*Turn, error) {
 	// Initialize WebSocket connection if not connected
-	err := c.Requester.InitializeWebSocket()
+	err := c.Requester.InitializeWebSocketContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -831,10 +1044,10 @@ func (c *Client) AnotherResponse(characterID, chatID, turnID string) (*Turn, err
 		RequestID: requestID,
 		Payload: GenerateTurnCandidatePayload{
 			CharacterID:         characterID,
-			TTSEnabled:          false,
-			PreviousAnnotations: generatePreviousAnnotations(),
-			SelectedLanguage:    "",
-			UserName:            "",
+			TTSEnabled:          options.TTS,
+			PreviousAnnotations: c.previousAnnotations(chatID),
+			SelectedLanguage:    options.Language,
+			UserName:            options.UserName,
 			TurnKey: TurnKey{
 				ChatID: chatID,
 				TurnID: turnID,
@@ -843,15 +1056,21 @@ func (c *Client) AnotherResponse(characterID, chatID, turnID string) (*Turn, err
 	}
 
 	// Send the message
-	err = c.Requester.SendWebSocketMessage(message)
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID, TurnID: turnID})
 	if err != nil {
 		return nil, err
 	}
+	defer request.Close()
 
 	// Receive response
+	var partial *Turn
 	for {
-		responseBytes, err := c.Requester.ReceiveRawWebSocketMessage()
+		responseBytes, err := request.Receive(ctx)
 		if err != nil {
+			if ctx.Err() != nil {
+				request.abortGeneration()
+				return partial, err
+			}
 			return nil, err
 		}
 
@@ -863,13 +1082,18 @@ func (c *Client) AnotherResponse(characterID, chatID, turnID string) (*Turn, err
 
 		switch response.Command {
 		case "neo_error":
-			return nil, errors.New(response.Comment)
+			return nil, newNeoError(request, response, responseBytes)
 		case "update_turn":
 			var payload TurnResponsePayload
 			err = json.Unmarshal(responseBytes, &payload)
 			if err != nil {
 				return nil, err
 			}
+			if candidate := payload.Turn.PrimaryCandidate(); candidate == nil || !candidate.IsFinal {
+				// Wait for the new candidate to be complete
+				partial = &payload.Turn
+				continue
+			}
 			return &payload.Turn, nil
 		}
 	}
@@ -1,6 +1,7 @@
 package cai
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"sync"
@@ -11,26 +12,65 @@ type Client struct {
 	Token         string
 	WebNextAuth   string
 	UserAccountID string
+	Endpoints     Endpoints
 	Requester     *Requester
-	mutex         sync.Mutex
+
+	annotations      map[string]map[candidateKey][]string // Annotation tags given per chat and candidate, sent along with generate requests
+	annotationsMutex sync.Mutex
+	settingsMutex    sync.Mutex     // Serializes updates of the account settings and generations with a persona
+	store            ChatStore      // Cache of chats and turns; nil if caching is disabled
+	recorder         *frameRecorder // Records incoming frames in store; nil if caching is disabled
 }
 
-// NewClient creates a new Client instance
-func NewClient(token string, webNextAuth string, proxy string) *Client {
-	requester := NewRequester(token, proxy)
-	return &Client{
+// NewClient creates a new Client instance.
+// The proxy, if set, is used for all HTTP and WebSocket traffic; see NewRequester for the supported URLs.
+func NewClient(token string, webNextAuth string, proxy string, options ...ClientOption) (*Client, error) {
+	clientOptions := defaultClientOptions()
+	for _, option := range options {
+		option(&clientOptions)
+	}
+
+	requester, err := NewRequester(token, proxy)
+	if err != nil {
+		return nil, err
+	}
+	requester.SetWebSocketURL(clientOptions.Endpoints.WebSocket)
+	if clientOptions.HTTPClient != nil {
+		requester.SetHTTPClient(clientOptions.HTTPClient)
+	}
+	if clientOptions.Transport != nil {
+		requester.SetTransport(clientOptions.Transport)
+	}
+	if clientOptions.UserAgent != "" {
+		requester.SetUserAgent(clientOptions.UserAgent)
+	}
+	requester.Use(clientOptions.Middleware...)
+
+	client := &Client{
 		Token:       token,
 		WebNextAuth: webNextAuth,
+		Endpoints:   clientOptions.Endpoints,
 		Requester:   requester,
+		store:       clientOptions.ChatStore,
 	}
+	if client.store != nil {
+		client.recorder = &frameRecorder{client: client}
+		requester.observer = client.recorder.add
+	}
+	return client, nil
 }
 
-// Authenticate retrieves the account ID
+// Authenticate calls AuthenticateContext with context.Background().
 func (c *Client) Authenticate() error {
+	return c.AuthenticateContext(context.Background())
+}
+
+// AuthenticateContext retrieves the account ID
+func (c *Client) AuthenticateContext(ctx context.Context) error {
 	if c.Token == "" {
-		return fmt.Errorf("token not provided")
+		return fmt.Errorf("%w: token not provided", ErrAuthenticationFailed)
 	}
-	account, err := c.FetchMe()
+	account, err := c.FetchMeContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -50,6 +90,11 @@ func (c *Client) GetHeaders(includeWebNextAuth bool) map[string]string {
 	return headers
 }
 
+// AvatarURL returns the URL of an avatar image on the configured media server.
+func (c *Client) AvatarURL(avatar *Avatar, size int, animated bool) string {
+	return avatar.GetURLWithBase(c.Endpoints.Media, size, animated)
+}
+
 // Close cleans up the client, closing any open connections
 func (c *Client) Close() error {
 	return c.Requester.CloseWebSocket()
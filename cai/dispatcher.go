@@ -0,0 +1,238 @@
+package cai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// unsolicitedBufferSize is the number of frames not belonging to any pending request which are kept
+// for ReceiveRawWebSocketMessage. Further frames are dropped until the buffer is drained.
+const unsolicitedBufferSize = 64
+
+// closedRequestsMemory is the number of closed request IDs remembered, so that late frames
+// of a finished request are not mistaken for frames of another request on the same chat.
+const closedRequestsMemory = 256
+
+// ErrRequestClosed is returned when receiving from a WebSocketRequest which has been closed
+var ErrRequestClosed = errors.New("websocket request closed")
+
+// WebSocketRequest is a WebSocket command in flight, receiving the frames routed to it by the Requester.
+type WebSocketRequest struct {
+	RequestID string
+	Command   string
+	Key       TurnKey
+	requester *Requester
+	message   WebSocketMessage
+	attempt   int
+	mutex     sync.Mutex
+	queue     [][]byte
+	notify    chan struct{}
+	err       error
+	closed    bool
+}
+
+// Receive returns the next frame routed to the request, waiting until one arrives, the connection fails or ctx is done.
+// A neo_error frame which the retry policy considers transient is not returned; the command is sent again instead.
+func (w *WebSocketRequest) Receive(ctx context.Context) ([]byte, error) {
+	for {
+		frame, err := w.next(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		delay, retry := w.requester.neoErrorRetryDelay(frame, w.attempt)
+		if !retry {
+			return frame, nil
+		}
+		w.attempt++
+
+		err = sleepContext(ctx, delay)
+		if err != nil {
+			return nil, err
+		}
+		err = w.requester.SendWebSocketMessageContext(ctx, w.message)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// next returns the next queued frame, waiting until one arrives, the connection fails or ctx is done
+func (w *WebSocketRequest) next(ctx context.Context) ([]byte, error) {
+	for {
+		w.mutex.Lock()
+		if len(w.queue) > 0 {
+			frame := w.queue[0]
+			w.queue[0] = nil
+			w.queue = w.queue[1:]
+			w.mutex.Unlock()
+			return frame, nil
+		}
+		if w.closed {
+			w.mutex.Unlock()
+			return nil, ErrRequestClosed
+		}
+		if w.err != nil {
+			err := w.err
+			w.mutex.Unlock()
+			return nil, err
+		}
+		w.mutex.Unlock()
+
+		select {
+		case <-w.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close unregisters the request. Frames arriving afterwards are no longer routed to it.
+func (w *WebSocketRequest) Close() {
+	w.requester.pendingMutex.Lock()
+	if w.requester.pending[w.RequestID] == w {
+		delete(w.requester.pending, w.RequestID)
+		w.requester.rememberClosed(w.RequestID)
+	}
+	w.requester.pendingMutex.Unlock()
+
+	w.mutex.Lock()
+	w.closed = true
+	w.queue = nil
+	w.mutex.Unlock()
+	w.wake()
+}
+
+// deliver queues a frame for the request
+func (w *WebSocketRequest) deliver(frame []byte) {
+	w.mutex.Lock()
+	if w.closed {
+		w.mutex.Unlock()
+		return
+	}
+	w.queue = append(w.queue, frame)
+	w.mutex.Unlock()
+	w.wake()
+}
+
+// fail makes Receive return err once all queued frames are consumed
+func (w *WebSocketRequest) fail(err error) {
+	w.mutex.Lock()
+	if w.err == nil {
+		w.err = err
+	}
+	w.mutex.Unlock()
+	w.wake()
+}
+
+func (w *WebSocketRequest) wake() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// matches reports whether a frame without a known request ID belongs to the request
+func (w *WebSocketRequest) matches(key TurnKey) bool {
+	if w.Key.ChatID == "" || w.Key.ChatID != key.ChatID {
+		return false
+	}
+	return w.Key.TurnID == "" || w.Key.TurnID == key.TurnID
+}
+
+// webSocketRouting holds the fields of an incoming frame used for routing it
+type webSocketRouting struct {
+	RequestID string `json:"request_id"`
+	Turn      *struct {
+		TurnKey TurnKey `json:"turn_key"`
+	} `json:"turn"`
+	Chat *struct {
+		ChatID string `json:"chat_id"`
+	} `json:"chat"`
+	ChatID string `json:"chat_id"`
+}
+
+// key returns the turn key a frame refers to, as far as it is known
+func (w *webSocketRouting) key() TurnKey {
+	switch {
+	case w.Turn != nil:
+		return w.Turn.TurnKey
+	case w.Chat != nil:
+		return TurnKey{ChatID: w.Chat.ChatID}
+	default:
+		return TurnKey{ChatID: w.ChatID}
+	}
+}
+
+// dispatch routes an incoming frame to the pending request it belongs to.
+// Frames are matched by request ID first and by turn key second.
+// Frames matching no request are handed to ReceiveRawWebSocketMessage.
+func (r *Requester) dispatch(frame []byte) {
+	var routing webSocketRouting
+	if err := json.Unmarshal(frame, &routing); err != nil {
+		r.deliverUnsolicited(frame)
+		return
+	}
+
+	r.pendingMutex.Lock()
+	if routing.RequestID != "" {
+		if request, ok := r.pending[routing.RequestID]; ok {
+			r.pendingMutex.Unlock()
+			request.deliver(frame)
+			return
+		}
+		if _, ok := r.closedRequests[routing.RequestID]; ok {
+			r.pendingMutex.Unlock()
+			return
+		}
+	}
+	var matched []*WebSocketRequest
+	key := routing.key()
+	if key.ChatID != "" {
+		for _, request := range r.pending {
+			if request.matches(key) {
+				matched = append(matched, request)
+			}
+		}
+	}
+	r.pendingMutex.Unlock()
+
+	if len(matched) == 0 {
+		r.deliverUnsolicited(frame)
+		return
+	}
+	for _, request := range matched {
+		request.deliver(frame)
+	}
+}
+
+// rememberClosed records a closed request ID, forgetting the oldest one if the memory is full.
+// The caller must hold pendingMutex.
+func (r *Requester) rememberClosed(requestID string) {
+	if len(r.closedOrder) >= closedRequestsMemory {
+		delete(r.closedRequests, r.closedOrder[0])
+		r.closedOrder = r.closedOrder[1:]
+	}
+	r.closedRequests[requestID] = struct{}{}
+	r.closedOrder = append(r.closedOrder, requestID)
+}
+
+// deliverUnsolicited buffers a frame for ReceiveRawWebSocketMessage, dropping it if the buffer is full
+func (r *Requester) deliverUnsolicited(frame []byte) {
+	select {
+	case r.unsolicited <- frame:
+	default:
+	}
+}
+
+// failPending fails all pending requests with err
+func (r *Requester) failPending(err error) {
+	r.pendingMutex.Lock()
+	defer r.pendingMutex.Unlock()
+
+	for _, request := range r.pending {
+		request.fail(err)
+	}
+}
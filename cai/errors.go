@@ -1,11 +1,151 @@
 package cai
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
 
 var (
 	// ErrAuthenticationFailed indicates authentication failure
 	ErrAuthenticationFailed = errors.New("authentication failed")
 	// ErrInvalidResponse indicates an invalid response from the server
 	ErrInvalidResponse = errors.New("invalid response from server")
+	// ErrConnectionLost indicates the WebSocket connection dropped while a request was in flight
+	ErrConnectionLost = errors.New("websocket connection lost")
+	// ErrRateLimited indicates the request was rejected for exceeding the rate limit
+	ErrRateLimited = errors.New("rate limited")
+	// ErrRateLimitExceeded indicates a request was held back by the client-side rate limiter; it matches ErrRateLimited
+	ErrRateLimitExceeded = fmt.Errorf("client-side %w", ErrRateLimited)
+	// ErrNotFound indicates the requested resource does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrContentFiltered indicates content was rejected by the safety filter
+	ErrContentFiltered = errors.New("content filtered")
+	// ErrServerError indicates the server failed to handle the request
+	ErrServerError = errors.New("server error")
+	// ErrNotCached indicates the chat store holds no synced copy of the requested chat
+	ErrNotCached = errors.New("not cached")
 	// Define other custom errors as needed
 )
+
+// contentFilteredMessages are the error messages the service rejects filtered content with. Messages match
+// exactly, ignoring case and surrounding space, as other messages merely mentioning a filter are unrelated.
+var contentFilteredMessages = []string{"content filtered", "safety filter"}
+
+// APIError is a failure reported by the service, either by an HTTP response or by a neo_error WebSocket frame.
+// Depending on status code and message it matches ErrAuthenticationFailed, ErrRateLimited, ErrNotFound,
+// ErrContentFiltered or ErrServerError with errors.Is.
+type APIError struct {
+	Operation  string // What the client attempted, e.g. "fetch chat"
+	Endpoint   string // URL of the REST endpoint without query, or the WebSocket command
+	StatusCode int    // HTTP status code; 0 for WebSocket errors
+	Message    string // Error message of the response body, or the neo_error comment
+	Body       []byte // Raw response body or WebSocket frame
+	RequestID  string // ID of the WebSocket request, or the X-Request-Id header of the HTTP response
+}
+
+// Error returns a description of the failure
+func (e *APIError) Error() string {
+	message := "failed to " + e.Operation
+	if e.StatusCode >= http.StatusBadRequest {
+		message += fmt.Sprintf(", status code: %d", e.StatusCode)
+	}
+	if e.Message != "" {
+		message += ", error: " + e.Message
+	}
+	return message
+}
+
+// Is reports whether the error falls into the category of a sentinel error
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuthenticationFailed:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden ||
+			e.messageContains("unauthorized", "authentication")
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.messageContains("rate limit", "too many requests")
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.messageContains("not found", "does not exist")
+	case ErrContentFiltered:
+		return e.messageIs(contentFilteredMessages...)
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// messageContains reports whether the message contains any of the given phrases, ignoring case
+func (e *APIError) messageContains(phrases ...string) bool {
+	message := strings.ToLower(e.Message)
+	for _, phrase := range phrases {
+		if strings.Contains(message, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageIs reports whether the message equals any of the given messages, ignoring case and surrounding space
+func (e *APIError) messageIs(messages ...string) bool {
+	message := strings.TrimSpace(e.Message)
+	for _, candidate := range messages {
+		if strings.EqualFold(message, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// newAPIError creates the error for an HTTP response with an unexpected status code, consuming its body
+func newAPIError(operation string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(resp.Body)
+
+	apiErr := &APIError{
+		Operation:  operation,
+		Endpoint:   endpointOf(resp),
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+	var errorResp ErrorResponse
+	if json.Unmarshal(body, &errorResp) == nil {
+		apiErr.Message = errorResp.Error.Message
+	}
+	return apiErr
+}
+
+// newRejectedError creates the error for an HTTP response whose body reports a failure despite a successful status
+func newRejectedError(operation string, resp *http.Response, body []byte, message string) *APIError {
+	return &APIError{
+		Operation:  operation,
+		Endpoint:   endpointOf(resp),
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		Body:       body,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+}
+
+// newNeoError creates the error for a neo_error frame received for a WebSocket request
+func newNeoError(request *WebSocketRequest, response WebSocketResponse, frame []byte) *APIError {
+	return &APIError{
+		Operation: strings.ReplaceAll(request.Command, "_", " "),
+		Endpoint:  request.Command,
+		Message:   response.Comment,
+		Body:      frame,
+		RequestID: request.RequestID,
+	}
+}
+
+// endpointOf returns the URL a response was received from, without query
+func endpointOf(resp *http.Response) string {
+	if resp.Request == nil || resp.Request.URL == nil {
+		return ""
+	}
+	endpoint := *resp.Request.URL
+	endpoint.RawQuery = ""
+	return endpoint.String()
+}
@@ -0,0 +1,449 @@
+package cai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ChatExportVersion is the version of the JSON export format. It is increased on incompatible changes.
+const ChatExportVersion = 1
+
+// exportAvatarSize is the size in pixels of the avatars referenced and embedded by exports
+const exportAvatarSize = 80
+
+// ExportFormat is a format a chat can be exported to
+type ExportFormat string
+
+// Supported export formats
+const (
+	ExportJSON     ExportFormat = "json"
+	ExportMarkdown ExportFormat = "markdown"
+	ExportHTML     ExportFormat = "html"
+	ExportText     ExportFormat = "text"
+)
+
+// ExportOptions selects what an export includes besides the primary text of each turn
+type ExportOptions struct {
+	Candidates bool // Include the alternate candidates of each turn
+	Pins       bool // Mark pinned turns
+}
+
+// ChatExport is a chat with its turns and participants, ready to be written in any ExportFormat.
+// Its JSON encoding is the versioned JSON export format.
+type ChatExport struct {
+	Version      int                   `json:"version"`
+	ExportedAt   time.Time             `json:"exported_at"`
+	Chat         ExportedChat          `json:"chat"`
+	Participants []ExportedParticipant `json:"participants"`
+	Turns        []ExportedTurn        `json:"turns"` // Chronological order
+}
+
+// ExportedChat holds the metadata of an exported chat
+type ExportedChat struct {
+	ChatID     string    `json:"chat_id"`
+	Name       string    `json:"name,omitempty"`
+	Type       string    `json:"type"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+// ExportedParticipant is a character or the user taking part in an exported chat
+type ExportedParticipant struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	IsHuman     bool   `json:"is_human"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	Avatar      []byte `json:"-"` // Avatar image embedded by HTML exports, if it could be fetched
+}
+
+// ExportedTurn is a turn of an exported chat
+type ExportedTurn struct {
+	TurnID     string              `json:"turn_id"`
+	AuthorID   string              `json:"author_id"`
+	AuthorName string              `json:"author_name"`
+	IsHuman    bool                `json:"is_human"`
+	CreateTime time.Time           `json:"create_time"`
+	Text       string              `json:"text"` // Text of the primary candidate
+	IsPinned   bool                `json:"is_pinned,omitempty"`
+	Candidates []ExportedCandidate `json:"candidates,omitempty"` // All candidates in the order they were generated
+}
+
+// ExportedCandidate is a candidate of an exported turn
+type ExportedCandidate struct {
+	CandidateID string    `json:"candidate_id"`
+	Text        string    `json:"text"`
+	CreateTime  time.Time `json:"create_time"`
+	IsPrimary   bool      `json:"is_primary,omitempty"`
+}
+
+// Alternatives returns the candidates of the turn other than the primary one
+func (t *ExportedTurn) Alternatives() []ExportedCandidate {
+	var alternatives []ExportedCandidate
+	for _, candidate := range t.Candidates {
+		if !candidate.IsPrimary {
+			alternatives = append(alternatives, candidate)
+		}
+	}
+	return alternatives
+}
+
+// ExportChat calls ExportChatContext with context.Background().
+func (c *Client) ExportChat(chatID string, w io.Writer, format ExportFormat, options ExportOptions) error {
+	return c.ExportChatContext(context.Background(), chatID, w, format, options)
+}
+
+// ExportChatContext fetches a chat with all of its turns and writes it to w in the given format
+func (c *Client) ExportChatContext(ctx context.Context, chatID string, w io.Writer, format ExportFormat, options ExportOptions) error {
+	export, err := c.FetchChatExportContext(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	return export.Write(w, format, options)
+}
+
+// FetchChatExport calls FetchChatExportContext with context.Background().
+func (c *Client) FetchChatExport(chatID string) (*ChatExport, error) {
+	return c.FetchChatExportContext(context.Background(), chatID)
+}
+
+// FetchChatExportContext fetches a chat with all of its turns, candidates and participants for exporting.
+// Avatars which cannot be fetched are left out, so exports fall back to placeholders.
+func (c *Client) FetchChatExportContext(ctx context.Context, chatID string) (*ChatExport, error) {
+	chat, err := c.FetchChatContext(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	turns, err := c.FetchAllMessagesContext(ctx, chatID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &ChatExport{
+		Version:    ChatExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Chat: ExportedChat{
+			ChatID:     chat.ChatID,
+			Name:       chat.ChatName,
+			Type:       chat.ChatType,
+			CreateTime: chat.CreateTime,
+		},
+	}
+
+	me, err := c.FetchMeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	export.Participants = append(export.Participants, c.exportUser(ctx, me))
+
+	characterIDs := chat.CharacterIDs
+	if len(characterIDs) == 0 {
+		characterIDs = []string{chat.CharacterID}
+	}
+	for _, characterID := range characterIDs {
+		character, err := c.FetchCharacterInfoContext(ctx, characterID)
+		if err != nil {
+			return nil, err
+		}
+		participant := ExportedParticipant{
+			ID:          characterID,
+			Name:        character.Name,
+			Title:       character.Title,
+			Description: character.Description,
+		}
+		if character.Avatar != nil {
+			participant.AvatarURL, participant.Avatar = c.exportAvatar(ctx, character.Avatar)
+		}
+		export.Participants = append(export.Participants, participant)
+	}
+
+	// Messages are fetched newest first
+	for i := len(turns) - 1; i >= 0; i-- {
+		export.Turns = append(export.Turns, exportTurn(turns[i]))
+	}
+	return export, nil
+}
+
+// exportUser returns the participant for the user's own turns
+func (c *Client) exportUser(ctx context.Context, me *UserAccount) ExportedParticipant {
+	participant := ExportedParticipant{ID: c.UserAccountID, Name: me.Name, IsHuman: true}
+	if me.User == nil {
+		return participant
+	}
+	if participant.ID == "" {
+		participant.ID = fmt.Sprint(me.User.ID)
+	}
+	if participant.Name == "" {
+		participant.Name = me.User.Username
+	}
+	if me.User.Account != nil && me.User.Account.AvatarFileName != "" {
+		participant.AvatarURL, participant.Avatar = c.exportAvatar(ctx, &Avatar{FileName: me.User.Account.AvatarFileName})
+	}
+	return participant
+}
+
+// exportAvatar returns the URL of an avatar and its image, or nil if it cannot be fetched
+func (c *Client) exportAvatar(ctx context.Context, avatar *Avatar) (string, []byte) {
+	urlStr := avatar.GetURLWithBase(c.Endpoints.Media, exportAvatarSize, false)
+
+	resp, err := c.Requester.GetContext(ctx, urlStr, nil)
+	if err != nil {
+		return urlStr, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return urlStr, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return urlStr, nil
+	}
+	return urlStr, data
+}
+
+// exportTurn converts a turn including all of its candidates
+func exportTurn(turn *Turn) ExportedTurn {
+	exported := ExportedTurn{
+		TurnID:     turn.TurnKey.TurnID,
+		AuthorID:   turn.Author.AuthorID,
+		AuthorName: turn.Author.Name,
+		IsHuman:    turn.Author.IsHuman,
+		CreateTime: turn.CreateTime,
+		IsPinned:   turn.IsPinned,
+	}
+	if candidate := turn.PrimaryCandidate(); candidate != nil {
+		exported.Text = candidate.Text
+	}
+	for _, candidate := range turn.OrderedCandidates() {
+		exported.Candidates = append(exported.Candidates, ExportedCandidate{
+			CandidateID: candidate.CandidateID,
+			Text:        candidate.Text,
+			CreateTime:  candidate.CreateTime,
+			IsPrimary:   candidate.CandidateID == turn.PrimaryCandidateID,
+		})
+	}
+	return exported
+}
+
+// Participant returns the participant with the given ID, or nil if there is none
+func (e *ChatExport) Participant(id string) *ExportedParticipant {
+	for i := range e.Participants {
+		if e.Participants[i].ID == id {
+			return &e.Participants[i]
+		}
+	}
+	return nil
+}
+
+// Title returns a title for the chat: its name, or the names of the characters taking part
+func (e *ChatExport) Title() string {
+	if e.Chat.Name != "" {
+		return e.Chat.Name
+	}
+	var names []string
+	for _, participant := range e.Participants {
+		if !participant.IsHuman {
+			names = append(names, participant.Name)
+		}
+	}
+	return "Chat with " + strings.Join(names, ", ")
+}
+
+// authorName returns the name to show for the author of a turn
+func (e *ChatExport) authorName(turn ExportedTurn) string {
+	if participant := e.Participant(turn.AuthorID); participant != nil && participant.Name != "" {
+		return participant.Name
+	}
+	if turn.AuthorName != "" {
+		return turn.AuthorName
+	}
+	if turn.IsHuman {
+		return "User"
+	}
+	return turn.AuthorID
+}
+
+// Write writes the chat to w in the given format, including what options select
+func (e *ChatExport) Write(w io.Writer, format ExportFormat, options ExportOptions) error {
+	filtered := e.filter(options)
+
+	switch format {
+	case ExportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(filtered)
+	case ExportMarkdown:
+		return filtered.writeMarkdown(w)
+	case ExportHTML:
+		return filtered.writeHTML(w)
+	case ExportText:
+		return filtered.writeText(w)
+	default:
+		return fmt.Errorf("unsupported export format: %q", format)
+	}
+}
+
+// filter returns a copy of the export without the turn details not selected by options
+func (e *ChatExport) filter(options ExportOptions) *ChatExport {
+	filtered := *e
+	filtered.Turns = make([]ExportedTurn, len(e.Turns))
+	for i, turn := range e.Turns {
+		if !options.Candidates {
+			turn.Candidates = nil
+		}
+		if !options.Pins {
+			turn.IsPinned = false
+		}
+		filtered.Turns[i] = turn
+	}
+	return &filtered
+}
+
+// exportTime formats the time of a turn for the human-readable formats
+func exportTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04")
+}
+
+// writeMarkdown writes the chat as Markdown
+func (e *ChatExport) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", e.Title())
+	for _, participant := range e.Participants {
+		if participant.IsHuman {
+			continue
+		}
+		fmt.Fprintf(&b, "- **%s**", participant.Name)
+		if participant.Title != "" {
+			fmt.Fprintf(&b, ": %s", participant.Title)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n---\n")
+
+	for _, turn := range e.Turns {
+		fmt.Fprintf(&b, "\n**%s**", e.authorName(turn))
+		if turn.IsPinned {
+			b.WriteString(" 📌")
+		}
+		fmt.Fprintf(&b, " · %s\n\n%s\n", exportTime(turn.CreateTime), turn.Text)
+		for i, candidate := range turn.Alternatives() {
+			fmt.Fprintf(&b, "\n> *Alternative %d:*\n", i+1)
+			for _, line := range strings.Split(candidate.Text, "\n") {
+				fmt.Fprintf(&b, "> %s\n", line)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeText writes the chat as plain text
+func (e *ChatExport) writeText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", e.Title())
+	for _, turn := range e.Turns {
+		pin := ""
+		if turn.IsPinned {
+			pin = " [pinned]"
+		}
+		fmt.Fprintf(&b, "[%s] %s%s:\n%s\n", exportTime(turn.CreateTime), e.authorName(turn), pin, turn.Text)
+		for i, candidate := range turn.Alternatives() {
+			fmt.Fprintf(&b, "  (alternative %d) %s\n", i+1, strings.ReplaceAll(candidate.Text, "\n", "\n  "))
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// htmlTurn is a turn prepared for the HTML template
+type htmlTurn struct {
+	ExportedTurn
+	Author       string
+	Time         string
+	Avatar       template.URL
+	Initial      string
+	Alternatives []htmlAlternative
+}
+
+// htmlAlternative is an alternate candidate prepared for the HTML template
+type htmlAlternative struct {
+	Number int
+	Text   string
+}
+
+// writeHTML writes the chat as a standalone HTML page, with the avatars embedded
+func (e *ChatExport) writeHTML(w io.Writer) error {
+	avatars := make(map[string]template.URL)
+	for _, participant := range e.Participants {
+		if len(participant.Avatar) > 0 {
+			contentType := http.DetectContentType(participant.Avatar)
+			avatars[participant.ID] = template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(participant.Avatar))
+		}
+	}
+
+	turns := make([]htmlTurn, len(e.Turns))
+	for i, turn := range e.Turns {
+		author := e.authorName(turn)
+		turns[i] = htmlTurn{
+			ExportedTurn: turn,
+			Author:       author,
+			Time:         exportTime(turn.CreateTime),
+			Avatar:       avatars[turn.AuthorID],
+			Initial:      strings.ToUpper(string([]rune(author + "?")[0])),
+		}
+		for j, candidate := range turn.Alternatives() {
+			turns[i].Alternatives = append(turns[i].Alternatives, htmlAlternative{Number: j + 1, Text: candidate.Text})
+		}
+	}
+
+	return exportHTMLTemplate.Execute(w, struct {
+		Title string
+		Turns []htmlTurn
+	}{e.Title(), turns})
+}
+
+var exportHTMLTemplate = template.Must(template.New("chat").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: 2em auto; background: #f6f6f6; color: #222; }
+.turn { display: flex; gap: 0.75em; margin: 1em 0; }
+.avatar { width: 40px; height: 40px; border-radius: 50%; flex-shrink: 0; background: #bbb; color: #fff;
+  display: flex; align-items: center; justify-content: center; font-weight: bold; }
+.bubble { background: #fff; border-radius: 0.5em; padding: 0.5em 0.75em; flex-grow: 1; }
+.human .bubble { background: #dcecff; }
+.meta { font-size: 0.8em; color: #666; }
+.text { white-space: pre-wrap; margin-top: 0.25em; }
+.pinned { border-left: 3px solid #e0a000; }
+.alternative { white-space: pre-wrap; border-top: 1px dashed #ccc; margin-top: 0.5em; padding-top: 0.5em; color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Turns}}<div class="turn{{if .IsHuman}} human{{end}}">
+{{if .Avatar}}<img class="avatar" src="{{.Avatar}}" alt="{{.Author}}">{{else}}<div class="avatar">{{.Initial}}</div>{{end}}
+<div class="bubble{{if .IsPinned}} pinned{{end}}">
+<div class="meta"><strong>{{.Author}}</strong> · {{.Time}}{{if .IsPinned}} · 📌 pinned{{end}}</div>
+<div class="text">{{.Text}}</div>
+{{range .Alternatives}}<div class="alternative"><span class="meta">Alternative {{.Number}}</span>
+{{.Text}}</div>
+{{end}}</div>
+</div>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,184 @@
+package cai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// GenerationOptions configures how the replies of a character are generated.
+// The zero value generates a single candidate without any of the options applied, like SendMessage does.
+type GenerationOptions struct {
+	// Language the character should reply in, e.g. "de"; empty leaves the choice to the service
+	Language string
+	// UserName is the name the character addresses the user with; it is also the author name of the user's message
+	UserName string
+	// PersonaID is a persona of the user to chat as for this generation only. The service takes the persona from
+	// the persona override of the character, so the override is set while the reply is generated and restored
+	// afterwards, see SetPersona. Changes of the override by other clients in the meantime are undone.
+	PersonaID string
+	// TTS enables speech; the audio of the primary candidate is returned with the reply
+	TTS bool
+	// VoiceID is the voice used for speech; empty uses the voice of the character
+	VoiceID string
+	// NumCandidates is the number of alternative replies generated for a new message; 0 means 1
+	NumCandidates int
+}
+
+// Reply is the final reply turn of a character generated with GenerationOptions
+type Reply struct {
+	Turn  *Turn
+	Audio []byte // Speech audio of the primary candidate, if GenerationOptions.TTS was set
+}
+
+// numCandidates returns the number of candidates to generate
+func (o GenerationOptions) numCandidates() (int, error) {
+	if o.NumCandidates < 0 {
+		return 0, fmt.Errorf("invalid number of candidates: %d", o.NumCandidates)
+	}
+	if o.NumCandidates == 0 {
+		return 1, nil
+	}
+	return o.NumCandidates, nil
+}
+
+// SendMessageWithOptions calls SendMessageWithOptionsContext with context.Background().
+func (c *Client) SendMessageWithOptions(characterID, chatID, text string, options GenerationOptions) (*Reply, error) {
+	return c.SendMessageWithOptionsContext(context.Background(), characterID, chatID, text, options)
+}
+
+// SendMessageWithOptionsContext sends a message to a character and waits until all candidates of the reply are final.
+// The reply is generated according to options, including its speech audio if TTS is enabled.
+// If ctx is done first, the generation is aborted and the partial reply, if any, is returned along with the
+// context's error.
+func (c *Client) SendMessageWithOptionsContext(ctx context.Context, characterID, chatID, text string, options GenerationOptions) (*Reply, error) {
+	var turn *Turn
+	err := c.withPersona(ctx, characterID, options.PersonaID, func() error {
+		events, err := c.sendMessageStream(ctx, characterID, chatID, text, options, nil)
+		if err != nil {
+			return err
+		}
+		turn, err = awaitFinalTurn(ctx, events)
+		return err
+	})
+	if err != nil {
+		if turn != nil {
+			// Partial reply of an aborted generation
+			return &Reply{Turn: turn}, err
+		}
+		return nil, err
+	}
+	return c.newReply(ctx, characterID, turn, options)
+}
+
+// AnotherResponseWithOptions calls AnotherResponseWithOptionsContext with context.Background().
+func (c *Client) AnotherResponseWithOptions(characterID, chatID, turnID string, options GenerationOptions) (*Reply, error) {
+	return c.AnotherResponseWithOptionsContext(context.Background(), characterID, chatID, turnID, options)
+}
+
+// AnotherResponseWithOptionsContext requests an alternative candidate for a character turn, generated according
+// to options, and waits until it is complete. The new candidate becomes the primary candidate of the returned turn.
+// A single candidate is generated per call, so options.NumCandidates must not be above 1. If ctx is done first,
+// the generation is aborted as with AnotherResponseContext.
+func (c *Client) AnotherResponseWithOptionsContext(ctx context.Context, characterID, chatID, turnID string, options GenerationOptions) (*Reply, error) {
+	numCandidates, err := options.numCandidates()
+	if err != nil {
+		return nil, err
+	}
+	if numCandidates > 1 {
+		return nil, errors.New("another response generates a single candidate")
+	}
+
+	var turn *Turn
+	err = c.withPersona(ctx, characterID, options.PersonaID, func() (err error) {
+		turn, err = c.anotherResponse(ctx, characterID, chatID, turnID, options)
+		return err
+	})
+	if err != nil {
+		if turn != nil {
+			// Partial reply of an aborted generation
+			return &Reply{Turn: turn}, err
+		}
+		return nil, err
+	}
+	return c.newReply(ctx, characterID, turn, options)
+}
+
+// withPersona runs generate with the persona override of a character set to personaID, restoring the previous
+// override afterwards, even if ctx is done. Without a persona, generate runs as it is.
+func (c *Client) withPersona(ctx context.Context, characterID, personaID string, generate func() error) error {
+	if personaID == "" {
+		return generate()
+	}
+
+	// The override must not change until the reply is generated
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	previous, err := c.swapPersonaOverride(ctx, characterID, personaID)
+	if err != nil {
+		return fmt.Errorf("failed to set persona override: %w", err)
+	}
+
+	err = generate()
+
+	_, restoreErr := c.swapPersonaOverride(context.WithoutCancel(ctx), characterID, previous)
+	if err == nil && restoreErr != nil {
+		err = fmt.Errorf("failed to restore persona override: %w", restoreErr)
+	}
+	return err
+}
+
+// swapPersonaOverride sets the persona override of a character, removing it if personaID is empty, and returns the
+// previous one; the caller must hold settingsMutex
+func (c *Client) swapPersonaOverride(ctx context.Context, characterID, personaID string) (string, error) {
+	settings, err := c.FetchMySettingsContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	previous := settings.PersonaOverrides[characterID]
+	if previous == personaID {
+		return previous, nil
+	}
+
+	if settings.PersonaOverrides == nil {
+		settings.PersonaOverrides = make(map[string]string)
+	}
+	if personaID == "" {
+		delete(settings.PersonaOverrides, characterID)
+	} else {
+		settings.PersonaOverrides[characterID] = personaID
+	}
+	_, err = c.UpdateSettingsContext(ctx, settings)
+	return previous, err
+}
+
+// newReply completes a final turn with the speech audio requested by options
+func (c *Client) newReply(ctx context.Context, characterID string, turn *Turn, options GenerationOptions) (*Reply, error) {
+	reply := &Reply{Turn: turn}
+	if !options.TTS {
+		return reply, nil
+	}
+
+	voiceID := options.VoiceID
+	if voiceID == "" {
+		character, err := c.FetchCharacterInfoContext(ctx, characterID)
+		if err != nil {
+			return nil, err
+		}
+		voiceID = character.VoiceID
+		if voiceID == "" {
+			voiceID = character.DefaultVoiceID
+		}
+		if voiceID == "" {
+			return nil, fmt.Errorf("character %s has no voice for speech", characterID)
+		}
+	}
+
+	audio, err := c.GenerateSpeechContext(ctx, turn.TurnKey.ChatID, turn.TurnKey.TurnID, turn.PrimaryCandidateID, voiceID)
+	if err != nil {
+		return nil, err
+	}
+	reply.Audio = audio
+	return reply, nil
+}
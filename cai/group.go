@@ -0,0 +1,299 @@
+package cai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CreateGroupChat calls CreateGroupChatContext with context.Background().
+func (c *Client) CreateGroupChat(name string, characterIDs []string) (*Chat, error) {
+	return c.CreateGroupChatContext(context.Background(), name, characterIDs)
+}
+
+// CreateGroupChatContext creates a group chat (room) with several characters.
+// The chat starts empty; use SendGroupMessage or GenerateGroupTurn to start the conversation.
+func (c *Client) CreateGroupChatContext(ctx context.Context, name string, characterIDs []string) (*Chat, error) {
+	if len(characterIDs) == 0 {
+		return nil, errors.New("group chat needs at least one character")
+	}
+
+	// Initialize WebSocket connection if not connected
+	err := c.Requester.InitializeWebSocketContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID := generateUUID()
+	chatID := generateUUID()
+
+	// Construct the message
+	message := WebSocketMessage{
+		Command:   "create_chat",
+		RequestID: requestID,
+		Payload: CreateChatPayload{
+			Chat: ChatPayload{
+				ChatID:       chatID,
+				CreatorID:    c.UserAccountID,
+				Visibility:   "VISIBILITY_PRIVATE",
+				CharacterID:  characterIDs[0],
+				CharacterIDs: characterIDs,
+				Name:         name,
+				Type:         ChatTypeGroup,
+			},
+		},
+	}
+
+	// Send the message
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID})
+	if err != nil {
+		return nil, err
+	}
+	defer request.Close()
+
+	// Receive response
+	for {
+		responseBytes, err := request.Receive(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var response WebSocketResponse
+		err = json.Unmarshal(responseBytes, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		switch response.Command {
+		case "neo_error":
+			return nil, newNeoError(request, response, responseBytes)
+		case "create_chat_response":
+			var payload CreateChatResponsePayload
+			err = json.Unmarshal(responseBytes, &payload)
+			if err != nil {
+				return nil, err
+			}
+			return &payload.Chat, nil
+		}
+	}
+}
+
+// FetchGroupChats calls FetchGroupChatsContext with context.Background().
+func (c *Client) FetchGroupChats() ([]*Chat, error) {
+	return c.FetchGroupChatsContext(context.Background())
+}
+
+// FetchGroupChatsContext lists the group chats (rooms) of the account, most recently active first
+func (c *Client) FetchGroupChatsContext(ctx context.Context) ([]*Chat, error) {
+	urlStr := c.Endpoints.Neo + "/rooms/"
+	headers := c.GetHeaders(false)
+
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("fetch group chats", resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result FetchChatsResponse
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, chat := range result.Chats {
+		chat.CreateTime, err = time.Parse(time.RFC3339Nano, chat.CreateTimeStr)
+		if err != nil {
+			return nil, err
+		}
+		if chat.CharacterAvatarURI != "" {
+			chat.CharacterAvatar = &Avatar{FileName: chat.CharacterAvatarURI}
+		}
+	}
+
+	return result.Chats, nil
+}
+
+// SendGroupMessage calls SendGroupMessageContext with context.Background().
+func (c *Client) SendGroupMessage(chatID, text string, speakerIDs ...string) ([]*Turn, error) {
+	return c.SendGroupMessageContext(context.Background(), chatID, text, speakerIDs...)
+}
+
+// SendGroupMessageContext sends a message to a group chat and collects the replies of the characters.
+// The characters given as speakerIDs reply one after another in that order; without speakerIDs every
+// participant of the chat replies once. The final reply turns are returned in the order they were generated.
+func (c *Client) SendGroupMessageContext(ctx context.Context, chatID, text string, speakerIDs ...string) ([]*Turn, error) {
+	if len(speakerIDs) == 0 {
+		chat, err := c.FetchChatContext(ctx, chatID)
+		if err != nil {
+			return nil, err
+		}
+		speakerIDs = chat.CharacterIDs
+	}
+
+	_, err := c.CreateUserTurnContext(ctx, chatID, text)
+	if err != nil {
+		return nil, err
+	}
+
+	replies := make([]*Turn, 0, len(speakerIDs))
+	for _, characterID := range speakerIDs {
+		turn, err := c.GenerateGroupTurnContext(ctx, chatID, characterID)
+		if err != nil {
+			return replies, err
+		}
+		replies = append(replies, turn)
+	}
+
+	return replies, nil
+}
+
+// CreateUserTurn calls CreateUserTurnContext with context.Background().
+func (c *Client) CreateUserTurn(chatID, text string) (*Turn, error) {
+	return c.CreateUserTurnContext(context.Background(), chatID, text)
+}
+
+// CreateUserTurnContext adds a message of the user to a chat without any character replying to it
+func (c *Client) CreateUserTurnContext(ctx context.Context, chatID, text string) (*Turn, error) {
+	return c.createTurn(ctx, chatID, AuthorPayload{AuthorID: c.UserAccountID, IsHuman: true}, text)
+}
+
+// createTurn adds a turn with the given author and text to a chat without generating anything
+func (c *Client) createTurn(ctx context.Context, chatID string, author AuthorPayload, text string) (*Turn, error) {
+	// Initialize WebSocket connection if not connected
+	err := c.Requester.InitializeWebSocketContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateID := generateUUID()
+	turnID := generateUUID()
+	requestID := generateUUID()
+
+	// Construct the message
+	message := WebSocketMessage{
+		Command:   "create_turn",
+		OriginID:  "web-next",
+		RequestID: requestID,
+		Payload: CreateTurnPayload{
+			Turn: TurnPayload{
+				Author: author,
+				Candidates: []CandidatePayload{
+					{
+						CandidateID: candidateID,
+						RawContent:  text,
+					},
+				},
+				PrimaryCandidateID: candidateID,
+				TurnKey: TurnKey{
+					ChatID: chatID,
+					TurnID: turnID,
+				},
+			},
+		},
+	}
+
+	// Send the message
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID, TurnID: turnID})
+	if err != nil {
+		return nil, err
+	}
+	defer request.Close()
+
+	// Receive response
+	for {
+		responseBytes, err := request.Receive(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var response WebSocketResponse
+		err = json.Unmarshal(responseBytes, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		switch response.Command {
+		case "neo_error":
+			return nil, newNeoError(request, response, responseBytes)
+		case "add_turn":
+			var payload TurnResponsePayload
+			err = json.Unmarshal(responseBytes, &payload)
+			if err != nil {
+				return nil, err
+			}
+			return &payload.Turn, nil
+		}
+	}
+}
+
+// GenerateGroupTurn calls GenerateGroupTurnContext with context.Background().
+func (c *Client) GenerateGroupTurn(chatID, characterID string) (*Turn, error) {
+	return c.GenerateGroupTurnContext(context.Background(), chatID, characterID)
+}
+
+// GenerateGroupTurnContext makes the given character speak next in a group chat and waits for its final turn
+func (c *Client) GenerateGroupTurnContext(ctx context.Context, chatID, characterID string) (*Turn, error) {
+	events, err := c.GenerateGroupTurnStreamContext(ctx, chatID, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return awaitFinalTurn(ctx, events)
+}
+
+// GenerateGroupTurnStream calls GenerateGroupTurnStreamContext with context.Background().
+func (c *Client) GenerateGroupTurnStream(chatID, characterID string) (<-chan TurnEvent, error) {
+	return c.GenerateGroupTurnStreamContext(context.Background(), chatID, characterID)
+}
+
+// GenerateGroupTurnStreamContext makes the given character speak next in a group chat, streaming its turn
+// as it is generated. The events follow the same rules as those of SendMessageStreamContext.
+func (c *Client) GenerateGroupTurnStreamContext(ctx context.Context, chatID, characterID string) (<-chan TurnEvent, error) {
+	// Initialize WebSocket connection if not connected
+	err := c.Requester.InitializeWebSocketContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	turnID := generateUUID()
+	requestID := generateUUID()
+
+	// Construct the message
+	message := WebSocketMessage{
+		Command:   "generate_turn",
+		OriginID:  "web-next",
+		RequestID: requestID,
+		Payload: GenerateTurnPayload{
+			CharacterID:         characterID,
+			NumCandidates:       1,
+			PreviousAnnotations: c.previousAnnotations(chatID),
+			TurnKey: TurnKey{
+				ChatID: chatID,
+				TurnID: turnID,
+			},
+		},
+	}
+
+	// Send the message
+	request, err := c.Requester.SendWebSocketRequest(ctx, message, TurnKey{ChatID: chatID, TurnID: turnID})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan TurnEvent)
+	go streamTurn(ctx, request, events, nil)
+
+	return events, nil
+}
@@ -1,15 +1,20 @@
 package cai
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 )
 
-// GenerateImage generates images based on a prompt.
+// GenerateImage calls GenerateImageContext with context.Background().
 func (c *Client) GenerateImage(prompt string, numCandidates int) ([]string, error) {
-	urlStr := "https://plus.character.ai/chat/character/generate-avatar-options"
+	return c.GenerateImageContext(context.Background(), prompt, numCandidates)
+}
+
+// GenerateImageContext generates images based on a prompt.
+func (c *Client) GenerateImageContext(ctx context.Context, prompt string, numCandidates int) ([]string, error) {
+	urlStr := c.Endpoints.Plus + "/chat/character/generate-avatar-options"
 	headers := c.GetHeaders(false)
 
 	payload := GenerateImageRequest{
@@ -22,14 +27,14 @@ func (c *Client) GenerateImage(prompt string, numCandidates int) ([]string, erro
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to generate image, status code: %d", resp.StatusCode)
+		return nil, newAPIError("generate image", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
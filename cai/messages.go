@@ -39,9 +39,10 @@ type CandidatePayload struct {
 }
 
 type WebSocketResponse struct {
-	Command string          `json:"command"`
-	Payload json.RawMessage `json:"payload,omitempty"`
-	Comment string          `json:"comment,omitempty"`
+	Command   string          `json:"command"`
+	RequestID string          `json:"request_id,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Comment   string          `json:"comment,omitempty"`
 }
 
 type ChatInfo struct {
@@ -59,11 +60,42 @@ type CreateChatPayload struct {
 }
 
 type ChatPayload struct {
-	ChatID      string `json:"chat_id"`
-	CreatorID   string `json:"creator_id"`
-	Visibility  string `json:"visibility"`
-	CharacterID string `json:"character_id"`
-	Type        string `json:"type"`
+	ChatID       string   `json:"chat_id"`
+	CreatorID    string   `json:"creator_id"`
+	Visibility   string   `json:"visibility"`
+	CharacterID  string   `json:"character_id"`
+	CharacterIDs []string `json:"character_ids,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Type         string   `json:"type"`
+}
+
+// AnnotateCandidatePayload represents the payload for giving feedback on a turn candidate.
+type AnnotateCandidatePayload struct {
+	TurnKey     TurnKey  `json:"turn_key"`
+	CandidateID string   `json:"candidate_id"`
+	StarRating  int      `json:"star_rating,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// AbortGenerationPayload represents the payload for stopping the generation of a turn in a chat.
+type AbortGenerationPayload struct {
+	ChatID string `json:"chat_id"`
+}
+
+// CreateTurnPayload represents the payload for adding a user turn without generating a reply.
+type CreateTurnPayload struct {
+	Turn TurnPayload `json:"turn"`
+}
+
+// GenerateTurnPayload represents the payload for generating a new character turn, used in group chats.
+type GenerateTurnPayload struct {
+	CharacterID         string              `json:"character_id"`
+	NumCandidates       int                 `json:"num_candidates"`
+	PreviousAnnotations PreviousAnnotations `json:"previous_annotations"`
+	SelectedLanguage    string              `json:"selected_language"`
+	TTSEnabled          bool                `json:"tts_enabled"`
+	UserName            string              `json:"user_name"`
+	TurnKey             TurnKey             `json:"turn_key"`
 }
 
 type CreateChatResponsePayload struct {
@@ -100,10 +132,12 @@ type FetchChatsResponse struct {
 type FetchHistoriesRequest struct {
 	ExternalID string `json:"external_id"`
 	Number     int    `json:"number"`
+	Page       int    `json:"page,omitempty"`
 }
 
 type FetchHistoriesResponse struct {
 	Histories []ChatHistory `json:"histories"`
+	HasMore   bool          `json:"has_more"`
 }
 
 type CopyChatRequest struct {
@@ -143,6 +177,7 @@ type UpdateProfilePayload struct {
 }
 
 type NotificationsResponsePayload struct {
+	Meta          Meta           `json:"meta"`
 	Notifications []Notification `json:"notifications"`
 }
 
@@ -314,7 +349,8 @@ type Creator struct {
 
 // SearchVoicesResponse represents the response for searching voices.
 type SearchVoicesResponse struct {
-	Voices []*Voice `json:"voices"`
+	Voices        []*Voice `json:"voices"`
+	NextPageToken string   `json:"nextPageToken,omitempty"`
 }
 
 // FetchVoiceResponse represents the response for fetching a voice.
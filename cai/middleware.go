@@ -0,0 +1,170 @@
+package cai
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// defaultUserAgent is the User-Agent sent unless another one is set
+const defaultUserAgent = "Mozilla/5.0"
+
+// defaultHTTPTimeout bounds HTTP requests of the default HTTP client
+const defaultHTTPTimeout = 30 * time.Second
+
+// Middleware hooks into the traffic of a Requester, e.g. to add headers, sign or trace requests, refresh credentials
+// or record traffic. Any of its hooks may be nil. Hooks are called concurrently and must not block for long.
+type Middleware struct {
+	// BeforeRequest is called before each attempt of an HTTP request is sent and may modify the request. An error
+	// aborts the request without retrying it.
+	BeforeRequest func(req *http.Request) error
+	// AfterResponse is called with the outcome of each attempt of an HTTP request, before it is checked for retries.
+	// It returns the outcome to use instead, e.g. the response of a request it repeated itself. A response it
+	// replaces must be closed by it.
+	AfterResponse func(req *http.Request, resp *http.Response, err error) (*http.Response, error)
+	// SendFrame is called with each outgoing WebSocket frame and returns the frame to send instead. An error aborts
+	// sending the frame.
+	SendFrame func(frame []byte) ([]byte, error)
+	// ReceiveFrame is called with each incoming WebSocket frame and returns the frame to process instead. A nil
+	// frame is dropped.
+	ReceiveFrame func(frame []byte) []byte
+}
+
+// middlewareError is an error returned by a BeforeRequest hook, which is not retried
+type middlewareError struct {
+	err error
+}
+
+func (e *middlewareError) Error() string {
+	return e.err.Error()
+}
+
+func (e *middlewareError) Unwrap() error {
+	return e.err
+}
+
+// errNoResponse is returned if AfterResponse hooks discard both the response and the error
+var errNoResponse = errors.New("middleware returned neither a response nor an error")
+
+// Use registers middleware. BeforeRequest and SendFrame hooks are called in the order of registration,
+// AfterResponse and ReceiveFrame hooks in reverse order, so the first middleware registered is the outermost.
+func (r *Requester) Use(middleware ...Middleware) {
+	r.transportMutex.Lock()
+	defer r.transportMutex.Unlock()
+
+	// Requests in flight keep the slice they started with
+	r.middleware = append(r.middleware[:len(r.middleware):len(r.middleware)], middleware...)
+}
+
+// SetHTTPClient makes the Requester send HTTP requests through client, which is used as is, e.g. with its own
+// timeout and transport. The proxy passed to NewRequester only applies to the default client.
+func (r *Requester) SetHTTPClient(client *http.Client) {
+	r.transportMutex.Lock()
+	defer r.transportMutex.Unlock()
+
+	r.client = client
+}
+
+// SetTransport makes the HTTP client of the Requester send requests through transport, e.g. to wrap the default
+// transport. The proxy passed to NewRequester only applies to the default transport.
+func (r *Requester) SetTransport(transport http.RoundTripper) {
+	r.transportMutex.Lock()
+	defer r.transportMutex.Unlock()
+
+	client := *r.client
+	client.Transport = transport
+	r.client = &client
+}
+
+// Transport returns the transport of the HTTP client of the Requester, e.g. to wrap it.
+func (r *Requester) Transport() http.RoundTripper {
+	r.transportMutex.Lock()
+	defer r.transportMutex.Unlock()
+
+	if r.client.Transport == nil {
+		return http.DefaultTransport
+	}
+	return r.client.Transport
+}
+
+// SetUserAgent sets the User-Agent of HTTP requests and of the WebSocket handshake, which defaults to Mozilla/5.0.
+// Headers passed with a request take precedence. It takes effect with the next connection for WebSockets.
+func (r *Requester) SetUserAgent(userAgent string) {
+	r.transportMutex.Lock()
+	r.userAgent = userAgent
+	r.transportMutex.Unlock()
+
+	r.wsMutex.Lock()
+	defer r.wsMutex.Unlock()
+
+	r.wsHeaders.Set("User-Agent", userAgent)
+}
+
+// httpTransport returns the HTTP client, the User-Agent and the middleware in effect
+func (r *Requester) httpTransport() (*http.Client, string, []Middleware) {
+	r.transportMutex.Lock()
+	defer r.transportMutex.Unlock()
+
+	return r.client, r.userAgent, r.middleware
+}
+
+// currentMiddleware returns the middleware in effect
+func (r *Requester) currentMiddleware() []Middleware {
+	r.transportMutex.Lock()
+	defer r.transportMutex.Unlock()
+
+	return r.middleware
+}
+
+// send performs a single attempt of an HTTP request, passing it through the middleware
+func (r *Requester) send(req *http.Request) (*http.Response, error) {
+	client, userAgent, middleware := r.httpTransport()
+	if userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	for _, m := range middleware {
+		if m.BeforeRequest == nil {
+			continue
+		}
+		if err := m.BeforeRequest(req); err != nil {
+			return nil, &middlewareError{err: err}
+		}
+	}
+
+	resp, err := client.Do(req)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i].AfterResponse != nil {
+			resp, err = middleware[i].AfterResponse(req, resp, err)
+		}
+	}
+	if resp == nil && err == nil {
+		err = errNoResponse
+	}
+	return resp, err
+}
+
+// sendingFrame passes an outgoing WebSocket frame through the middleware
+func sendingFrame(middleware []Middleware, frame []byte) ([]byte, error) {
+	for _, m := range middleware {
+		if m.SendFrame == nil {
+			continue
+		}
+		var err error
+		frame, err = m.SendFrame(frame)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return frame, nil
+}
+
+// receivedFrame passes an incoming WebSocket frame through the middleware, returning nil if it is dropped
+func receivedFrame(middleware []Middleware, frame []byte) []byte {
+	for i := len(middleware) - 1; i >= 0 && frame != nil; i-- {
+		if middleware[i].ReceiveFrame != nil {
+			frame = middleware[i].ReceiveFrame(frame)
+		}
+	}
+	return frame
+}
@@ -0,0 +1,227 @@
+package cai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// FetchNotifications calls FetchNotificationsContext with context.Background().
+func (c *Client) FetchNotifications(unreadOnly bool, nextToken string) ([]*Notification, string, error) {
+	return c.FetchNotificationsContext(context.Background(), unreadOnly, nextToken)
+}
+
+// FetchNotificationsContext retrieves a page of the account's notifications, newest first.
+// It returns the token of the next page, which is empty on the last page.
+func (c *Client) FetchNotificationsContext(ctx context.Context, unreadOnly bool, nextToken string) ([]*Notification, string, error) {
+	query := url.Values{}
+	if unreadOnly {
+		query.Set("unread_only", "true")
+	}
+	if nextToken != "" {
+		query.Set("next_token", nextToken)
+	}
+	urlStr := c.Endpoints.Neo + "/notifications/"
+	if len(query) > 0 {
+		urlStr += "?" + query.Encode()
+	}
+	headers := c.GetHeaders(false)
+
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", newAPIError("fetch notifications", resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var result NotificationsResponsePayload
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, "", err
+	}
+
+	notifications := make([]*Notification, len(result.Notifications))
+	for i := range result.Notifications {
+		notifications[i] = &result.Notifications[i]
+	}
+
+	return notifications, result.Meta.NextToken, nil
+}
+
+// FetchAllNotifications calls FetchAllNotificationsContext with context.Background().
+func (c *Client) FetchAllNotifications(unreadOnly bool) ([]*Notification, error) {
+	return c.FetchAllNotificationsContext(context.Background(), unreadOnly)
+}
+
+// FetchAllNotificationsContext retrieves all notifications of the account, newest first
+func (c *Client) FetchAllNotificationsContext(ctx context.Context, unreadOnly bool) ([]*Notification, error) {
+	return c.NotificationsPager(unreadOnly).Collect(ctx)
+}
+
+// NotificationsPager returns a pager over the account's notifications, newest first.
+func (c *Client) NotificationsPager(unreadOnly bool) *Pager[*Notification] {
+	return NewPager(func(ctx context.Context, token string) ([]*Notification, string, error) {
+		return c.FetchNotificationsContext(ctx, unreadOnly, token)
+	})
+}
+
+// MarkNotificationsRead calls MarkNotificationsReadContext with context.Background().
+func (c *Client) MarkNotificationsRead(notificationIDs ...string) error {
+	return c.MarkNotificationsReadContext(context.Background(), notificationIDs...)
+}
+
+// MarkNotificationsReadContext marks the given notifications as read
+func (c *Client) MarkNotificationsReadContext(ctx context.Context, notificationIDs ...string) error {
+	urlStr := c.Endpoints.Neo + "/notifications/mark_read/"
+	headers := c.GetHeaders(false)
+
+	payload := MarkNotificationsReadPayload{
+		NotificationIDs: notificationIDs,
+	}
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("mark notifications read", resp)
+	}
+
+	return nil
+}
+
+// MarkAllNotificationsRead calls MarkAllNotificationsReadContext with context.Background().
+func (c *Client) MarkAllNotificationsRead() error {
+	return c.MarkAllNotificationsReadContext(context.Background())
+}
+
+// MarkAllNotificationsReadContext marks all notifications of the account as read
+func (c *Client) MarkAllNotificationsReadContext(ctx context.Context) error {
+	urlStr := c.Endpoints.Neo + "/notifications/mark_all_read/"
+	headers := c.GetHeaders(false)
+
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("mark all notifications read", resp)
+	}
+
+	return nil
+}
+
+// NotificationEvent is emitted by WatchNotifications. Either Notification or Err is set.
+type NotificationEvent struct {
+	Notification *Notification // Notification received since the previous poll
+	Err          error         // Error of a failed poll; polling continues with the next interval
+}
+
+// DefaultNotificationInterval is the polling interval of WatchNotifications for an interval which is not positive
+const DefaultNotificationInterval = 30 * time.Second
+
+// WatchNotifications polls the account's notifications every interval until ctx is done, emitting each notification
+// arriving after the watch started, oldest first. An interval which is not positive is replaced by
+// DefaultNotificationInterval. Failed polls are emitted as events carrying Err.
+// The returned channel is closed once ctx is done.
+func (c *Client) WatchNotifications(ctx context.Context, interval time.Duration) <-chan NotificationEvent {
+	if interval <= 0 {
+		interval = DefaultNotificationInterval
+	}
+	events := make(chan NotificationEvent)
+
+	go func() {
+		defer close(events)
+
+		// The first successful poll only records the notifications existing already
+		var seen map[string]bool
+		var newest time.Time
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			fresh, current, err := c.pollNotifications(ctx, seen, newest)
+			if err == nil {
+				if seen != nil {
+					for i := len(fresh) - 1; i >= 0; i-- {
+						if !emitNotificationEvent(ctx, events, NotificationEvent{Notification: fresh[i]}) {
+							return
+						}
+					}
+				}
+				if len(fresh) > 0 {
+					newest = fresh[0].CreateTime
+				}
+				seen = current
+			} else if ctx.Err() == nil {
+				if !emitNotificationEvent(ctx, events, NotificationEvent{Err: err}) {
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
+
+// pollNotifications fetches the notifications not in seen, newest first, following pages until a seen one is found.
+// Paging also stops at the first notification created before newest, the creation time of the newest notification
+// seen so far, so that the whole history is not fetched again once the seen notifications have been deleted.
+// It also returns the IDs of all fetched notifications, which are the ones to recognize on the next poll.
+func (c *Client) pollNotifications(ctx context.Context, seen map[string]bool, newest time.Time) ([]*Notification, map[string]bool, error) {
+	var fresh []*Notification
+	current := make(map[string]bool)
+	var nextToken string
+	for {
+		notifications, token, err := c.FetchNotificationsContext(ctx, false, nextToken)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, notification := range notifications {
+			current[notification.NotificationID] = true
+			if seen[notification.NotificationID] || notification.CreateTime.Before(newest) {
+				return fresh, current, nil
+			}
+			fresh = append(fresh, notification)
+		}
+		if token == "" || seen == nil {
+			return fresh, current, nil
+		}
+		nextToken = token
+	}
+}
+
+// emitNotificationEvent sends an event unless ctx is done first
+func emitNotificationEvent(ctx context.Context, events chan<- NotificationEvent, event NotificationEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
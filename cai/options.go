@@ -0,0 +1,90 @@
+package cai
+
+import "net/http"
+
+// Endpoints holds the base URLs of all services the client talks to.
+// Base URLs carry no trailing slash.
+type Endpoints struct {
+	Neo       string // Neo REST API, e.g. chats, turns and multimodal
+	Plus      string // Legacy REST API, e.g. characters, users and settings
+	Beta      string // Beta REST API used for account information
+	TRPC      string // tRPC API of the web frontend, e.g. avatar uploads
+	Media     string // Media server hosting avatars
+	WebSocket string // Neo WebSocket API
+}
+
+// DefaultEndpoints returns the endpoints of the public character.ai services.
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		Neo:       "https://neo.character.ai",
+		Plus:      "https://plus.character.ai",
+		Beta:      "https://beta.character.ai",
+		TRPC:      "https://character.ai/api/trpc",
+		Media:     "https://characterai.io",
+		WebSocket: "wss://neo.character.ai/ws/",
+	}
+}
+
+// ClientOptions holds the optional settings of a Client.
+type ClientOptions struct {
+	Endpoints  Endpoints
+	ChatStore  ChatStore
+	HTTPClient *http.Client      // Replaces the default HTTP client, see Requester.SetHTTPClient
+	Transport  http.RoundTripper // Replaces the transport of the HTTP client, see Requester.SetTransport
+	UserAgent  string
+	Middleware []Middleware
+}
+
+// ClientOption changes an optional setting of a Client, see NewClient.
+type ClientOption func(*ClientOptions)
+
+// WithEndpoints makes the client use the given endpoints instead of the public character.ai services.
+func WithEndpoints(endpoints Endpoints) ClientOption {
+	return func(options *ClientOptions) {
+		options.Endpoints = endpoints
+	}
+}
+
+// WithChatStore makes the client cache chats and turns in store, see ChatStore.
+func WithChatStore(store ChatStore) ClientOption {
+	return func(options *ClientOptions) {
+		options.ChatStore = store
+	}
+}
+
+// WithHTTPClient makes the client send HTTP requests through httpClient, see Requester.SetHTTPClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(options *ClientOptions) {
+		options.HTTPClient = httpClient
+	}
+}
+
+// WithTransport makes the client send HTTP requests through transport, see Requester.SetTransport.
+// It applies on top of WithHTTPClient.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(options *ClientOptions) {
+		options.Transport = transport
+	}
+}
+
+// WithUserAgent sets the User-Agent the client sends, see Requester.SetUserAgent.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(options *ClientOptions) {
+		options.UserAgent = userAgent
+	}
+}
+
+// WithMiddleware registers middleware hooking into the traffic of the client, see Requester.Use.
+// The option may be given repeatedly; middleware is registered in the order given.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(options *ClientOptions) {
+		options.Middleware = append(options.Middleware, middleware...)
+	}
+}
+
+// defaultClientOptions returns the settings of a Client without options
+func defaultClientOptions() ClientOptions {
+	return ClientOptions{
+		Endpoints: DefaultEndpoints(),
+	}
+}
@@ -0,0 +1,180 @@
+package cai
+
+import (
+	"context"
+	"strconv"
+)
+
+// PageFunc fetches the page of a listing at token, where the empty token selects the first page. It returns the
+// items of the page and the token of the next page, which is empty on the last page.
+type PageFunc[T any] func(ctx context.Context, token string) ([]T, string, error)
+
+// Pager lists the items of a paginated endpoint, fetching pages as the iteration reaches them.
+// A Pager holds no state of its own, so it may be iterated any number of times.
+type Pager[T any] struct {
+	fetch    PageFunc[T]
+	prefetch int
+}
+
+// NewPager returns a pager over the pages returned by fetch.
+func NewPager[T any](fetch PageFunc[T]) *Pager[T] {
+	return &Pager[T]{fetch: fetch}
+}
+
+// Prefetch returns a copy of the pager fetching up to pages pages ahead of the iteration in the background.
+// With 0 pages, the default, each page is fetched when the iteration reaches it.
+func (p *Pager[T]) Prefetch(pages int) *Pager[T] {
+	if pages < 0 {
+		pages = 0
+	}
+	return &Pager[T]{fetch: p.fetch, prefetch: pages}
+}
+
+// Iterate starts an iteration over the items. ctx applies to all fetches of the iteration.
+// The iterator must be closed if it is abandoned before the end when prefetching.
+func (p *Pager[T]) Iterate(ctx context.Context) *Iterator[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Iterator[T]{ctx: ctx, cancel: cancel, fetch: p.fetch, prefetch: p.prefetch}
+}
+
+// Collect returns all items of all pages.
+func (p *Pager[T]) Collect(ctx context.Context) ([]T, error) {
+	it := p.Iterate(ctx)
+	defer it.Close()
+
+	var items []T
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// page is a fetched page of a listing
+type page[T any] struct {
+	items []T
+	next  string
+	err   error
+}
+
+// Iterator steps through the items of a Pager. It is not safe for concurrent use.
+//
+// Usage:
+//
+//	it := pager.Iterate(ctx)
+//	defer it.Close()
+//	for it.Next() {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator[T any] struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	fetch    PageFunc[T]
+	prefetch int
+	pages    chan page[T] // Pages fetched ahead; nil until the first page is needed or without prefetching
+
+	items  []T    // Remaining items of the current page
+	item   T      // Current item
+	token  string // Token of the next page
+	last   bool   // Whether the current page is the last
+	closed bool
+	err    error
+}
+
+// Next advances to the next item, fetching the next page if needed. It returns false at the end of the listing, on
+// errors and once the iterator is closed.
+func (it *Iterator[T]) Next() bool {
+	for len(it.items) == 0 {
+		if it.closed || it.last || it.err != nil {
+			return false
+		}
+		next := it.nextPage()
+		if next.err != nil {
+			it.err = next.err
+			it.Close()
+			return false
+		}
+		it.items, it.token, it.last = next.items, next.next, next.next == ""
+	}
+	it.item = it.items[0]
+	it.items = it.items[1:]
+	return true
+}
+
+// Item returns the current item.
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error that ended the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Close ends the iteration and stops fetching pages ahead.
+func (it *Iterator[T]) Close() {
+	it.closed = true
+	it.items = nil
+	it.cancel()
+}
+
+// nextPage returns the next page, either fetching it or taking it from the pages fetched ahead
+func (it *Iterator[T]) nextPage() page[T] {
+	if it.prefetch == 0 {
+		if err := it.ctx.Err(); err != nil {
+			return page[T]{err: err}
+		}
+		items, next, err := it.fetch(it.ctx, it.token)
+		return page[T]{items: items, next: next, err: err}
+	}
+
+	if it.pages == nil {
+		// The channel holds all but the page being fetched, bounding the pages fetched ahead
+		it.pages = make(chan page[T], it.prefetch-1)
+		go it.fetchAhead(it.token)
+	}
+	next, ok := <-it.pages
+	if !ok {
+		return page[T]{err: it.ctx.Err()}
+	}
+	return next
+}
+
+// fetchAhead fetches pages starting at token until the last page, an error or cancellation
+func (it *Iterator[T]) fetchAhead(token string) {
+	defer close(it.pages)
+	for {
+		items, next, err := it.fetch(it.ctx, token)
+		select {
+		case it.pages <- page[T]{items: items, next: next, err: err}:
+		case <-it.ctx.Done():
+			return
+		}
+		if err != nil || next == "" {
+			return
+		}
+		token = next
+	}
+}
+
+// pageNumber returns the number of the page selected by the token of a page-numbered listing, starting at 1
+func pageNumber(token string) int {
+	number, err := strconv.Atoi(token)
+	if err != nil || number < 1 {
+		return 1
+	}
+	return number
+}
+
+// nextPageToken returns the token of the page following page number in a page-numbered listing
+func nextPageToken(number int, hasMore bool) string {
+	if !hasMore {
+		return ""
+	}
+	return strconv.Itoa(number + 1)
+}
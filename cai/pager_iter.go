@@ -0,0 +1,34 @@
+//go:build go1.23
+
+package cai
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns the items for use in range loops. An error ends the iteration and is yielded with the zero item.
+//
+// Usage:
+//
+//	for turn, err := range client.MessagesPager(chatID, false).All(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//	}
+func (p *Pager[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		it := p.Iterate(ctx)
+		defer it.Close()
+
+		for it.Next() {
+			if !yield(it.Item(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package cai
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointFamily groups requests which share a client-side rate limit.
+type EndpointFamily string
+
+const (
+	// FamilyNeoTurns covers all commands sent over the neo WebSocket, e.g. sending messages and generating turns
+	FamilyNeoTurns EndpointFamily = "neo_turns"
+	// FamilyREST covers all REST requests except multimodal ones
+	FamilyREST EndpointFamily = "rest"
+	// FamilyVoice covers REST requests to the multimodal API, e.g. voices and speech generation
+	FamilyVoice EndpointFamily = "voice"
+)
+
+// RateLimit configures the token bucket of an endpoint family.
+// The zero RateLimit leaves the family unlimited.
+type RateLimit struct {
+	Rate     float64 // Requests allowed per second on average; 0 disables the limit
+	Burst    int     // Requests allowed at once before the rate applies; values below 1 count as 1
+	FailFast bool    // Fail with ErrRateLimitExceeded instead of waiting when no request is available
+}
+
+// RateLimitStats are the metrics of an endpoint family's rate limiter.
+type RateLimitStats struct {
+	Allowed   int64         // Requests which passed the limiter, with or without waiting
+	Delayed   int64         // Requests which had to wait before passing
+	Rejected  int64         // Requests rejected in fail-fast mode, or whose context ended while waiting
+	TotalWait time.Duration // Sum of the time waited by passed requests
+	MaxWait   time.Duration // Longest time a single passed request waited
+}
+
+// tokenBucket is the rate limiter of a single endpoint family
+type tokenBucket struct {
+	mutex  sync.Mutex
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+	stats  RateLimitStats
+}
+
+// burst returns the capacity of the bucket, which holds at least one token; the caller must hold the mutex
+func (b *tokenBucket) burst() float64 {
+	return float64(max(b.limit.Burst, 1))
+}
+
+// refill adds the tokens accrued since the last update; the caller must hold the mutex
+func (b *tokenBucket) refill(now time.Time) {
+	burst := b.burst()
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+}
+
+// wait takes a token from the bucket, waiting until one is available unless the limit is fail-fast
+func (b *tokenBucket) wait(ctx context.Context, family EndpointFamily) error {
+	b.mutex.Lock()
+	if b.limit.Rate <= 0 {
+		b.stats.Allowed++
+		b.mutex.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		b.stats.Allowed++
+		b.mutex.Unlock()
+		return nil
+	}
+
+	delay := time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+	deadline, hasDeadline := ctx.Deadline()
+	if b.limit.FailFast || (hasDeadline && deadline.Before(now.Add(delay))) {
+		b.stats.Rejected++
+		b.mutex.Unlock()
+		return fmt.Errorf("%w: %s requests would have to wait %v", ErrRateLimitExceeded, family, delay)
+	}
+
+	// Reserve the token now, so that concurrent waiters queue up behind each other
+	b.tokens--
+	b.mutex.Unlock()
+
+	err := sleepContext(ctx, delay)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err != nil {
+		b.tokens++
+		b.stats.Rejected++
+		return err
+	}
+	b.stats.Allowed++
+	b.stats.Delayed++
+	b.stats.TotalWait += delay
+	if delay > b.stats.MaxWait {
+		b.stats.MaxWait = delay
+	}
+	return nil
+}
+
+// rateLimiter holds the token buckets of all endpoint families
+type rateLimiter struct {
+	mutex   sync.Mutex
+	buckets map[EndpointFamily]*tokenBucket
+}
+
+// bucket returns the token bucket of a family, creating an unlimited one if needed
+func (l *rateLimiter) bucket(family EndpointFamily) *tokenBucket {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = make(map[EndpointFamily]*tokenBucket)
+	}
+	bucket, ok := l.buckets[family]
+	if !ok {
+		bucket = &tokenBucket{}
+		l.buckets[family] = bucket
+	}
+	return bucket
+}
+
+// familyOf returns the endpoint family of a REST request URL
+func familyOf(urlStr string) EndpointFamily {
+	parsed, err := url.Parse(urlStr)
+	if err == nil && strings.Contains(parsed.Path, "/multimodal/") {
+		return FamilyVoice
+	}
+	return FamilyREST
+}
+
+// SetRateLimit sets the rate limit of an endpoint family, shared by all requests made through the Requester.
+// The bucket starts out full.
+func (r *Requester) SetRateLimit(family EndpointFamily, limit RateLimit) {
+	bucket := r.limiter.bucket(family)
+
+	bucket.mutex.Lock()
+	defer bucket.mutex.Unlock()
+
+	bucket.limit = limit
+	bucket.tokens = bucket.burst()
+	bucket.last = time.Now()
+	bucket.refill(bucket.last)
+}
+
+// RateLimitStats returns the metrics of an endpoint family's rate limiter.
+func (r *Requester) RateLimitStats(family EndpointFamily) RateLimitStats {
+	bucket := r.limiter.bucket(family)
+
+	bucket.mutex.Lock()
+	defer bucket.mutex.Unlock()
+
+	return bucket.stats
+}
+
+// waitRateLimit takes a request from the rate limit of an endpoint family
+func (r *Requester) waitRateLimit(ctx context.Context, family EndpointFamily) error {
+	return r.limiter.bucket(family).wait(ctx, family)
+}
@@ -0,0 +1,162 @@
+package cai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ReconnectPolicy controls how the Requester redials a WebSocket connection which dropped.
+type ReconnectPolicy struct {
+	Enabled        bool
+	InitialBackoff time.Duration // Delay before the first redial attempt
+	MaxBackoff     time.Duration // Upper bound for the delay between attempts
+	Multiplier     float64       // Factor the delay grows by after each failed attempt
+	Jitter         float64       // Random deviation of each delay, as a fraction of it (0 to 1)
+	MaxAttempts    int           // Attempts before giving up; 0 means no limit
+	PingInterval   time.Duration // Interval of keepalive pings used to detect dead connections; 0 disables them
+}
+
+// DefaultReconnectPolicy returns the reconnect policy used by new Requesters.
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		Enabled:        true,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		MaxAttempts:    10,
+		PingInterval:   30 * time.Second,
+	}
+}
+
+// backoff returns the delay before the given redial attempt, starting at 1
+func (p ReconnectPolicy) backoff(attempt int) time.Duration {
+	return exponentialBackoff(p.InitialBackoff, p.MaxBackoff, p.Multiplier, p.Jitter, attempt)
+}
+
+// ReconnectEventType identifies the kind of a ReconnectEvent
+type ReconnectEventType int
+
+const (
+	// Disconnected is reported when a connection drops unexpectedly
+	Disconnected ReconnectEventType = iota
+	// Reconnecting is reported before each redial attempt
+	Reconnecting
+	// Reconnected is reported once a redial attempt succeeded
+	Reconnected
+	// ReconnectFailed is reported when a redial attempt failed
+	ReconnectFailed
+	// ReconnectAbandoned is reported when the policy's attempts are exhausted.
+	// The next WebSocket call dials again on demand.
+	ReconnectAbandoned
+)
+
+// String returns the name of the event type
+func (t ReconnectEventType) String() string {
+	switch t {
+	case Disconnected:
+		return "disconnected"
+	case Reconnecting:
+		return "reconnecting"
+	case Reconnected:
+		return "reconnected"
+	case ReconnectFailed:
+		return "reconnect failed"
+	case ReconnectAbandoned:
+		return "reconnect abandoned"
+	default:
+		return fmt.Sprintf("ReconnectEventType(%d)", int(t))
+	}
+}
+
+// ReconnectEvent reports a change of the WebSocket connection state.
+type ReconnectEvent struct {
+	Type    ReconnectEventType
+	Attempt int           // Redial attempt the event belongs to, starting at 1
+	Delay   time.Duration // Delay before the attempt, set for Reconnecting
+	Err     error         // Cause of Disconnected and ReconnectFailed
+}
+
+// SetReconnectPolicy sets the policy used to redial dropped WebSocket connections.
+func (r *Requester) SetReconnectPolicy(policy ReconnectPolicy) {
+	r.wsMutex.Lock()
+	defer r.wsMutex.Unlock()
+
+	r.reconnectPolicy = policy
+}
+
+// OnReconnect registers a callback receiving connection state changes.
+// The callback is called synchronously from the connection handling and must not block.
+func (r *Requester) OnReconnect(callback func(ReconnectEvent)) {
+	r.wsMutex.Lock()
+	defer r.wsMutex.Unlock()
+
+	r.onReconnect = callback
+}
+
+// notifyReconnect passes an event to the registered callback, if any
+func (r *Requester) notifyReconnect(event ReconnectEvent) {
+	r.wsMutex.Lock()
+	callback := r.onReconnect
+	r.wsMutex.Unlock()
+
+	if callback != nil {
+		callback(event)
+	}
+}
+
+// reconnect redials after a connection dropped, following the reconnect policy.
+// It stops once connected, when the attempts are exhausted or lifetime ends as the Requester is closed.
+func (r *Requester) reconnect(lifetime context.Context) {
+	r.wsMutex.Lock()
+	policy := r.reconnectPolicy
+	r.wsMutex.Unlock()
+
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		delay := policy.backoff(attempt)
+		r.notifyReconnect(ReconnectEvent{Type: Reconnecting, Attempt: attempt, Delay: delay})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-lifetime.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		err := r.InitializeWebSocketContext(lifetime)
+		if err == nil {
+			r.notifyReconnect(ReconnectEvent{Type: Reconnected, Attempt: attempt})
+			return
+		}
+		if lifetime.Err() != nil {
+			return
+		}
+		r.notifyReconnect(ReconnectEvent{Type: ReconnectFailed, Attempt: attempt, Err: err})
+	}
+
+	r.notifyReconnect(ReconnectEvent{Type: ReconnectAbandoned})
+}
+
+// keepAlive pings conn periodically until done is closed.
+// A connection not answering within two intervals runs into its read deadline and is dropped.
+func (r *Requester) keepAlive(conn *websocket.Conn, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval))
+			if err != nil {
+				// The read loop notices the broken connection as well
+				return
+			}
+		}
+	}
+}
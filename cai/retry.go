@@ -0,0 +1,189 @@
+package cai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how the Requester retries REST requests and WebSocket commands which failed transiently.
+//
+// Rate limited requests (HTTP 429) are retried for any method, since the server rejected them unprocessed.
+// Network errors and server errors (HTTP 500, 502, 503, 504) are only retried for idempotent methods.
+// WebSocket commands are retried when they fail with a neo_error whose comment contains a retryable phrase.
+type RetryPolicy struct {
+	MaxAttempts       int           // Attempts per request including the first one; values below 2 disable retries
+	InitialBackoff    time.Duration // Delay before the first retry
+	MaxBackoff        time.Duration // Upper bound for the delay between attempts
+	Multiplier        float64       // Factor the delay grows by after each failed attempt
+	Jitter            float64       // Random deviation of each delay, as a fraction of it (0 to 1)
+	MaxRetryAfter     time.Duration // Longest Retry-After delay honored; requests asking for longer are not retried
+	IdempotentMethods []string      // HTTP methods safe to repeat after a failure which may have reached the server
+	RetryableComments []string      // Phrases of neo_error comments which are retried, matched ignoring case
+}
+
+// DefaultRetryPolicy returns the retry policy used by new Requesters.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    500 * time.Millisecond,
+		MaxBackoff:        10 * time.Second,
+		Multiplier:        2,
+		Jitter:            0.2,
+		MaxRetryAfter:     time.Minute,
+		IdempotentMethods: []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete},
+		RetryableComments: []string{"rate limit", "too many requests", "try again"},
+	}
+}
+
+// backoff returns the delay before the given retry, starting at 1
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	return exponentialBackoff(p.InitialBackoff, p.MaxBackoff, p.Multiplier, p.Jitter, retry)
+}
+
+// idempotent reports whether requests of the given HTTP method may be repeated
+func (p RetryPolicy) idempotent(method string) bool {
+	for _, idempotent := range p.IdempotentMethods {
+		if strings.EqualFold(idempotent, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableComment reports whether a neo_error comment indicates a transient failure
+func (p RetryPolicy) retryableComment(comment string) bool {
+	comment = strings.ToLower(comment)
+	for _, phrase := range p.RetryableComments {
+		if strings.Contains(comment, strings.ToLower(phrase)) {
+			return true
+		}
+	}
+	return false
+}
+
+// httpRetryDelay decides whether a REST request is retried after the given attempt, starting at 1,
+// and returns the delay before the retry.
+func (p RetryPolicy) httpRetryDelay(method string, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if err != nil {
+		return p.backoff(attempt), p.idempotent(method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if !p.idempotent(method) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		if p.MaxRetryAfter > 0 && retryAfter > p.MaxRetryAfter {
+			return 0, false
+		}
+		return retryAfter, true
+	}
+	return p.backoff(attempt), true
+}
+
+// parseRetryAfter parses the value of a Retry-After header, given either in seconds or as HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// exponentialBackoff returns the delay before the given attempt, starting at 1
+func exponentialBackoff(initial, max time.Duration, multiplier, jitter float64, attempt int) time.Duration {
+	delay := float64(initial)
+	for i := 1; i < attempt; i++ {
+		delay *= multiplier
+		if max > 0 && delay >= float64(max) {
+			delay = float64(max)
+			break
+		}
+	}
+	if jitter > 0 {
+		delay += delay * jitter * (2*rand.Float64() - 1)
+	}
+	if max > 0 && delay > float64(max) {
+		delay = float64(max)
+	}
+	return time.Duration(delay)
+}
+
+// SetRetryPolicy sets the policy used to retry failed REST requests and WebSocket commands.
+// The zero RetryPolicy disables retries.
+func (r *Requester) SetRetryPolicy(policy RetryPolicy) {
+	r.retryMutex.Lock()
+	defer r.retryMutex.Unlock()
+
+	r.retryPolicy = policy
+}
+
+// currentRetryPolicy returns the retry policy in effect
+func (r *Requester) currentRetryPolicy() RetryPolicy {
+	r.retryMutex.Lock()
+	defer r.retryMutex.Unlock()
+
+	return r.retryPolicy
+}
+
+// neoErrorRetryDelay decides whether a WebSocket command is retried after receiving frame as reply to the given
+// attempt, starting at 1, and returns the delay before the retry
+func (r *Requester) neoErrorRetryDelay(frame []byte, attempt int) (time.Duration, bool) {
+	policy := r.currentRetryPolicy()
+	if attempt >= policy.MaxAttempts {
+		return 0, false
+	}
+
+	var response WebSocketResponse
+	if json.Unmarshal(frame, &response) != nil || response.Command != "neo_error" {
+		return 0, false
+	}
+	if !policy.retryableComment(response.Comment) {
+		return 0, false
+	}
+	return policy.backoff(attempt), true
+}
+
+// sleepContext waits for delay, returning early with the error of ctx if it is done first
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discardResponse drains and closes the body of a response which is not handed to the caller
+func discardResponse(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
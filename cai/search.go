@@ -0,0 +1,389 @@
+package cai
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Snippet sizes in bytes
+const (
+	snippetLeadingContext = 60  // Context kept before the first match
+	snippetLength         = 200 // Length of a snippet, unless the first match is longer
+)
+
+// snippetEllipsis marks text cut from a snippet
+const snippetEllipsis = "…"
+
+// SearchAuthor selects the turns a search considers by their author
+type SearchAuthor int
+
+// Authors of turns a search can be restricted to
+const (
+	SearchAnyAuthor       SearchAuthor = iota // Turns of the user and of characters
+	SearchHumanAuthor                         // Turns of the user only
+	SearchCharacterAuthor                     // Turns of characters only
+)
+
+// SearchQuery describes the turns to find. All of its conditions must hold for a turn to match.
+type SearchQuery struct {
+	// Text holds words and "quoted phrases" which must all occur in a candidate, ignoring case. Words match whole
+	// words; a phrase matches its words in a row. Empty text matches every candidate.
+	Text string
+	// CharacterIDs restricts the search to chats with any of these characters. In group chats, turns of other
+	// characters are excluded.
+	CharacterIDs []string
+	Author       SearchAuthor
+	PinnedOnly   bool
+	PrimaryOnly  bool      // Search the primary candidate of each turn only, instead of all candidates
+	After        time.Time // If set, only turns created at or after this time match
+	Before       time.Time // If set, only turns created before this time match
+	Limit        int       // Maximum number of hits; 0 means no limit
+}
+
+// SearchHit is a candidate matching a SearchQuery
+type SearchHit struct {
+	TurnKey     TurnKey
+	CandidateID string
+	CharacterID string // Character of the chat; empty for group chats
+	AuthorID    string
+	AuthorName  string
+	IsHuman     bool
+	IsPinned    bool
+	IsPrimary   bool // Whether the candidate is the primary candidate of its turn
+	CreateTime  time.Time
+	// Snippet is an excerpt of the candidate text around the first match, on a single line. Text cut at either
+	// end is marked with an ellipsis.
+	Snippet string
+	// Highlights are the matches within Snippet, in order and not overlapping
+	Highlights []SearchRange
+}
+
+// SearchRange is a range of bytes of a snippet
+type SearchRange struct {
+	Start int
+	End   int // Exclusive
+}
+
+// HighlightedSnippet returns the snippet with each match enclosed in pre and post, e.g. "<mark>" and "</mark>".
+func (h *SearchHit) HighlightedSnippet(pre, post string) string {
+	var builder strings.Builder
+	last := 0
+	for _, highlight := range h.Highlights {
+		builder.WriteString(h.Snippet[last:highlight.Start])
+		builder.WriteString(pre)
+		builder.WriteString(h.Snippet[highlight.Start:highlight.End])
+		builder.WriteString(post)
+		last = highlight.End
+	}
+	builder.WriteString(h.Snippet[last:])
+	return builder.String()
+}
+
+// SearchCachedMessages searches the turns of all chats in the cache without contacting the service, see
+// SyncAllChatsContext. Hits are ordered newest first.
+func (c *Client) SearchCachedMessages(query SearchQuery) ([]SearchHit, error) {
+	if c.store == nil {
+		return nil, errNoChatStore
+	}
+	c.recorder.flush()
+
+	chats, err := c.store.Chats()
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := newSearchMatcher(query)
+	var hits []SearchHit
+	for _, chat := range chats {
+		if !matcher.matchesChat(chat) {
+			continue
+		}
+		turns, err := c.store.Turns(chat.ChatID)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, matcher.search(chat, turns)...)
+	}
+	return matcher.finish(hits), nil
+}
+
+// SearchTurns searches turns of a chat, e.g. of an export or a backup. Hits are ordered newest first.
+func SearchTurns(chat *Chat, turns []*Turn, query SearchQuery) []SearchHit {
+	matcher := newSearchMatcher(query)
+	if !matcher.matchesChat(chat) {
+		return nil
+	}
+	return matcher.finish(matcher.search(chat, turns))
+}
+
+// searchMatcher applies a parsed SearchQuery
+type searchMatcher struct {
+	query    SearchQuery
+	patterns [][]string // Words, and phrases as words in a row, in lower case
+}
+
+func newSearchMatcher(query SearchQuery) *searchMatcher {
+	return &searchMatcher{query: query, patterns: parseSearchText(query.Text)}
+}
+
+// parseSearchText splits query text into words and quoted phrases. An unterminated quote extends to the end.
+func parseSearchText(text string) [][]string {
+	var patterns [][]string
+	for i, part := range strings.Split(text, `"`) {
+		words := searchWords(part)
+		if i%2 == 1 {
+			// Inside quotes
+			if len(words) > 0 {
+				patterns = append(patterns, words)
+			}
+			continue
+		}
+		for _, word := range words {
+			patterns = append(patterns, []string{word})
+		}
+	}
+	return patterns
+}
+
+// searchWords returns the words of text in lower case
+func searchWords(text string) []string {
+	var words []string
+	for _, token := range tokenize(text) {
+		words = append(words, token.word)
+	}
+	return words
+}
+
+// searchToken is a word of a text and its position
+type searchToken struct {
+	word       string // Lower case
+	start, end int
+}
+
+// tokenize splits text into words, which are runs of letters and digits
+func tokenize(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	for i, r := range text {
+		inWord := isWordRune(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, searchToken{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, searchToken{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// isWordRune reports whether r is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// matchesChat reports whether turns of chat may match the character filter
+func (m *searchMatcher) matchesChat(chat *Chat) bool {
+	if len(m.query.CharacterIDs) == 0 {
+		return true
+	}
+	if containsID(m.query.CharacterIDs, chat.CharacterID) {
+		return true
+	}
+	for _, characterID := range chat.CharacterIDs {
+		if containsID(m.query.CharacterIDs, characterID) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTurn reports whether a turn passes the filters of the query
+func (m *searchMatcher) matchesTurn(turn *Turn) bool {
+	switch {
+	case m.query.Author == SearchHumanAuthor && !turn.Author.IsHuman,
+		m.query.Author == SearchCharacterAuthor && turn.Author.IsHuman,
+		m.query.PinnedOnly && !turn.IsPinned,
+		!m.query.After.IsZero() && turn.CreateTime.Before(m.query.After),
+		!m.query.Before.IsZero() && !turn.CreateTime.Before(m.query.Before):
+		return false
+	}
+	if len(m.query.CharacterIDs) > 0 && !turn.Author.IsHuman && turn.Author.AuthorID != "" {
+		return containsID(m.query.CharacterIDs, turn.Author.AuthorID)
+	}
+	return true
+}
+
+// search returns the hits among the turns of a chat
+func (m *searchMatcher) search(chat *Chat, turns []*Turn) []SearchHit {
+	var hits []SearchHit
+	for _, turn := range turns {
+		if !m.matchesTurn(turn) {
+			continue
+		}
+		for _, candidate := range turn.OrderedCandidates() {
+			primary := candidate.CandidateID == turn.PrimaryCandidateID
+			if m.query.PrimaryOnly && !primary {
+				continue
+			}
+			matches, ok := m.match(candidate.Text)
+			if !ok {
+				continue
+			}
+
+			hit := SearchHit{
+				TurnKey:     turn.TurnKey,
+				CandidateID: candidate.CandidateID,
+				AuthorID:    turn.Author.AuthorID,
+				AuthorName:  turn.Author.Name,
+				IsHuman:     turn.Author.IsHuman,
+				IsPinned:    turn.IsPinned,
+				IsPrimary:   primary,
+				CreateTime:  turn.CreateTime,
+			}
+			if chat.ChatType != ChatTypeGroup {
+				hit.CharacterID = chat.CharacterID
+			}
+			if hit.TurnKey.ChatID == "" {
+				hit.TurnKey.ChatID = chat.ChatID
+			}
+			hit.Snippet, hit.Highlights = snippet(candidate.Text, matches)
+			hits = append(hits, hit)
+		}
+	}
+	return hits
+}
+
+// match returns the ranges of text matching the patterns, in order and merged, and whether all patterns occur
+func (m *searchMatcher) match(text string) ([]SearchRange, bool) {
+	if len(m.patterns) == 0 {
+		return nil, true
+	}
+
+	tokens := tokenize(text)
+	var matches []SearchRange
+	for _, pattern := range m.patterns {
+		found := false
+		for i := 0; i+len(pattern) <= len(tokens); i++ {
+			if tokensMatch(tokens[i:i+len(pattern)], pattern) {
+				matches = append(matches, SearchRange{Start: tokens[i].start, End: tokens[i+len(pattern)-1].end})
+				found = true
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	merged := matches[:1]
+	for _, match := range matches[1:] {
+		last := &merged[len(merged)-1]
+		if match.Start <= last.End {
+			if match.End > last.End {
+				last.End = match.End
+			}
+			continue
+		}
+		merged = append(merged, match)
+	}
+	return merged, true
+}
+
+// tokensMatch reports whether tokens spell the words of a pattern
+func tokensMatch(tokens []searchToken, pattern []string) bool {
+	for i, word := range pattern {
+		if tokens[i].word != word {
+			return false
+		}
+	}
+	return true
+}
+
+// finish orders hits newest first and applies the limit
+func (m *searchMatcher) finish(hits []SearchHit) []SearchHit {
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].CreateTime.After(hits[j].CreateTime)
+	})
+	if m.query.Limit > 0 && len(hits) > m.query.Limit {
+		hits = hits[:m.query.Limit]
+	}
+	return hits
+}
+
+// snippet cuts an excerpt around the first match from text, returning it with the matches it contains
+func snippet(text string, matches []SearchRange) (string, []SearchRange) {
+	var first SearchRange
+	if len(matches) > 0 {
+		first = matches[0]
+	}
+
+	// Cut at word boundaries, so no word is split
+	start := first.Start - snippetLeadingContext
+	if start <= 0 {
+		start = 0
+	} else {
+		for !utf8.RuneStart(text[start]) {
+			start--
+		}
+		cut := first.Start
+		partial, _ := utf8.DecodeLastRuneInString(text[:start])
+		for _, token := range tokenize(text[start:first.Start]) {
+			if token.start > 0 || !isWordRune(partial) {
+				cut = start + token.start
+				break
+			}
+		}
+		start = cut
+	}
+	end := start + snippetLength
+	if end < first.End {
+		end = first.End
+	}
+	if end >= len(text) {
+		end = len(text)
+	} else {
+		cut := first.End
+		for _, token := range tokenize(text[first.End:end]) {
+			// The last token may be cut off
+			if first.End+token.end < end {
+				cut = first.End + token.end
+			}
+		}
+		end = cut
+	}
+
+	var builder strings.Builder
+	offset := -start
+	if start > 0 {
+		builder.WriteString(snippetEllipsis)
+		offset += len(snippetEllipsis)
+	}
+	builder.WriteString(strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, text[start:end]))
+	if end < len(text) {
+		builder.WriteString(snippetEllipsis)
+	}
+
+	var highlights []SearchRange
+	for _, match := range matches {
+		if match.Start >= end {
+			break
+		}
+		if match.End > end {
+			match.End = end
+		}
+		highlights = append(highlights, SearchRange{Start: match.Start + offset, End: match.End + offset})
+	}
+	return builder.String(), highlights
+}
@@ -0,0 +1,306 @@
+package cai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ChatSession is a one-on-one chat together with its turns, kept in sync as the session's methods change the chat.
+// It spares callers from tracking character, chat, turn and candidate IDs themselves.
+// The methods of a session are safe for concurrent use, but operations changing the chat should not overlap.
+type ChatSession struct {
+	Chat   *Chat
+	client *Client
+	mutex  sync.Mutex
+	turns  []*Turn // Chronological order
+}
+
+// CreateChatSession calls CreateChatSessionContext with context.Background().
+func (c *Client) CreateChatSession(characterID string, greeting bool) (*ChatSession, error) {
+	return c.CreateChatSessionContext(context.Background(), characterID, greeting)
+}
+
+// CreateChatSessionContext creates a new chat with a character and returns a session for it
+func (c *Client) CreateChatSessionContext(ctx context.Context, characterID string, greeting bool) (*ChatSession, error) {
+	chat, greetingTurn, err := c.CreateChatContext(ctx, characterID, greeting)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &ChatSession{Chat: chat, client: c}
+	if greetingTurn != nil {
+		session.turns = append(session.turns, greetingTurn)
+	}
+	return session, nil
+}
+
+// FetchChatSession calls FetchChatSessionContext with context.Background().
+func (c *Client) FetchChatSession(chatID string) (*ChatSession, error) {
+	return c.FetchChatSessionContext(context.Background(), chatID)
+}
+
+// FetchChatSessionContext returns a session for an existing chat, loading all of its turns
+func (c *Client) FetchChatSessionContext(ctx context.Context, chatID string) (*ChatSession, error) {
+	chat, err := c.FetchChatContext(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &ChatSession{Chat: chat, client: c}
+	err = session.RefreshContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Turns returns the turns of the chat in chronological order
+func (s *ChatSession) Turns() []*Turn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]*Turn(nil), s.turns...)
+}
+
+// LastTurn returns the latest turn of the chat, or nil if the chat is empty
+func (s *ChatSession) LastTurn() *Turn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.turns) == 0 {
+		return nil
+	}
+	return s.turns[len(s.turns)-1]
+}
+
+// Refresh calls RefreshContext with context.Background().
+func (s *ChatSession) Refresh() error {
+	return s.RefreshContext(context.Background())
+}
+
+// RefreshContext reloads the turns of the chat from the server, discarding the local state
+func (s *ChatSession) RefreshContext(ctx context.Context) error {
+	turns, err := s.client.FetchAllMessagesContext(ctx, s.Chat.ChatID, false)
+	if err != nil {
+		return err
+	}
+
+	// Messages are fetched newest first
+	for i, j := 0, len(turns)-1; i < j; i, j = i+1, j-1 {
+		turns[i], turns[j] = turns[j], turns[i]
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.turns = turns
+	return nil
+}
+
+// Send calls SendContext with context.Background().
+func (s *ChatSession) Send(text string) (*Turn, error) {
+	return s.SendContext(context.Background(), text)
+}
+
+// SendContext sends a message to the character and returns its final reply.
+// Both the message and the reply are appended to the session's turns. If ctx is done before the reply is final,
+// its generation is aborted and the partial reply is kept and returned along with the context's error.
+func (s *ChatSession) SendContext(ctx context.Context, text string) (*Turn, error) {
+	events, err := s.client.sendMessageStream(ctx, s.Chat.CharacterID, s.Chat.ChatID, text, GenerationOptions{}, s.appendTurn)
+	if err != nil {
+		return nil, err
+	}
+
+	turn, err := awaitFinalTurn(ctx, events)
+	if turn != nil {
+		s.appendTurn(turn)
+	}
+	return turn, err
+}
+
+// Regenerate calls RegenerateContext with context.Background().
+func (s *ChatSession) Regenerate() (*Turn, error) {
+	return s.RegenerateContext(context.Background())
+}
+
+// RegenerateContext generates another candidate for the character's latest reply, which becomes its primary candidate
+func (s *ChatSession) RegenerateContext(ctx context.Context) (*Turn, error) {
+	last, err := s.lastReply()
+	if err != nil {
+		return nil, err
+	}
+
+	turn, err := s.client.AnotherResponseContext(ctx, s.Chat.CharacterID, s.Chat.ChatID, last.TurnKey.TurnID)
+	if err != nil {
+		return nil, err
+	}
+	s.replaceTurn(turn)
+	return turn, nil
+}
+
+// Swipe calls SwipeContext with context.Background().
+func (s *ChatSession) Swipe(n int) (*Turn, error) {
+	return s.SwipeContext(context.Background(), n)
+}
+
+// SwipeContext moves the primary candidate of the character's latest reply by n positions, in the order the
+// candidates were generated. Negative n moves to older candidates. Swiping past the newest candidate generates
+// a new one, like Regenerate.
+func (s *ChatSession) SwipeContext(ctx context.Context, n int) (*Turn, error) {
+	last, err := s.lastReply()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := last.OrderedCandidates()
+	current := 0
+	for i, candidate := range candidates {
+		if candidate.CandidateID == last.PrimaryCandidateID {
+			current = i
+		}
+	}
+	target := current + n
+	if target < 0 {
+		return nil, fmt.Errorf("cannot swipe to candidate %d of %d", target, len(candidates))
+	}
+	if target >= len(candidates) {
+		return s.RegenerateContext(ctx)
+	}
+
+	candidateID := candidates[target].CandidateID
+	err = s.client.UpdatePrimaryCandidateContext(ctx, s.Chat.ChatID, last.TurnKey.TurnID, candidateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Candidates are not modified, so the updated turn shares them with the previous one
+	turn := *last
+	turn.PrimaryCandidateID = candidateID
+	s.replaceTurn(&turn)
+	return &turn, nil
+}
+
+// EditLast calls EditLastContext with context.Background().
+func (s *ChatSession) EditLast(text string) (*Turn, error) {
+	return s.EditLastContext(context.Background(), text)
+}
+
+// EditLastContext replaces the text of the latest turn of the chat, whether it was written by the user or the character
+func (s *ChatSession) EditLastContext(ctx context.Context, text string) (*Turn, error) {
+	last := s.LastTurn()
+	if last == nil {
+		return nil, errors.New("chat has no turns")
+	}
+
+	turn, err := s.client.EditMessageContext(ctx, s.Chat.ChatID, last.TurnKey.TurnID, last.PrimaryCandidateID, text)
+	if err != nil {
+		return nil, err
+	}
+	s.replaceTurn(turn)
+	return turn, nil
+}
+
+// Rewind calls RewindContext with context.Background().
+func (s *ChatSession) Rewind(turnID string) error {
+	return s.RewindContext(context.Background(), turnID)
+}
+
+// RewindContext deletes all turns after the given one, making it the latest turn of the chat
+func (s *ChatSession) RewindContext(ctx context.Context, turnID string) error {
+	s.mutex.Lock()
+	index := s.indexOf(turnID)
+	var turnIDs []string
+	if index >= 0 {
+		for _, turn := range s.turns[index+1:] {
+			turnIDs = append(turnIDs, turn.TurnKey.TurnID)
+		}
+	}
+	s.mutex.Unlock()
+
+	if index < 0 {
+		return fmt.Errorf("%w: turn %s is not part of the chat", ErrNotFound, turnID)
+	}
+	if len(turnIDs) == 0 {
+		return nil
+	}
+
+	err := s.client.DeleteMessagesContext(ctx, s.Chat.ChatID, turnIDs)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if index = s.indexOf(turnID); index >= 0 {
+		s.turns = s.turns[:index+1]
+	}
+	return nil
+}
+
+// Branch calls BranchContext with context.Background().
+func (s *ChatSession) Branch(turnID string) (*ChatSession, error) {
+	return s.BranchContext(context.Background(), turnID)
+}
+
+// BranchContext copies the chat up to the given turn, or entirely if turnID is empty, and returns a session
+// for the copy. The original chat is left unchanged.
+func (s *ChatSession) BranchContext(ctx context.Context, turnID string) (*ChatSession, error) {
+	if turnID == "" {
+		last := s.LastTurn()
+		if last == nil {
+			return nil, errors.New("chat has no turns")
+		}
+		turnID = last.TurnKey.TurnID
+	}
+
+	chatID, err := s.client.CopyChatContext(ctx, s.Chat.ChatID, turnID)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.FetchChatSessionContext(ctx, chatID)
+}
+
+// lastReply returns the latest turn if it was written by the character
+func (s *ChatSession) lastReply() (*Turn, error) {
+	last := s.LastTurn()
+	if last == nil || last.Author.IsHuman {
+		return nil, errors.New("latest turn is not a reply of the character")
+	}
+	return last, nil
+}
+
+// appendTurn adds a new turn to the end of the chat. A turn already known is replaced instead, as the server
+// may send updates of the user's turn after adding it.
+func (s *ChatSession) appendTurn(turn *Turn) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if index := s.indexOf(turn.TurnKey.TurnID); index >= 0 {
+		s.turns[index] = turn
+		return
+	}
+	s.turns = append(s.turns, turn)
+}
+
+// replaceTurn replaces the local state of a turn with an update received from the server
+func (s *ChatSession) replaceTurn(turn *Turn) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if index := s.indexOf(turn.TurnKey.TurnID); index >= 0 {
+		s.turns[index] = turn
+	}
+}
+
+// indexOf returns the index of a turn, or -1; the caller must hold the mutex
+func (s *ChatSession) indexOf(turnID string) int {
+	for i, turn := range s.turns {
+		if turn.TurnKey.TurnID == turnID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,273 @@
+package cai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sillyTavernCreateDateLayout is the layout of the chat creation date in SillyTavern headers
+const sillyTavernCreateDateLayout = "2006-01-02@15h04m05s"
+
+// sillyTavernLayouts are the timestamp layouts accepted when reading SillyTavern and TavernAI chats
+var sillyTavernLayouts = []string{
+	time.RFC3339Nano,
+	sillyTavernCreateDateLayout,
+	"2006-1-2 @15h 04m 05s 000ms",
+	"2006-1-2 @15h 04m 05s",
+	"January 2, 2006 3:04pm",
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006 15:04",
+}
+
+// SillyTavernTime is a timestamp of a SillyTavern chat. It is written in RFC 3339 format, which SillyTavern reads.
+// Reading accepts the formats written by SillyTavern and TavernAI versions as well: humanized dates and Unix
+// milliseconds. Timestamps which cannot be parsed are read as the zero time.
+type SillyTavernTime time.Time
+
+// MarshalJSON writes the time in RFC 3339 format
+func (t SillyTavernTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).UTC().Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON parses any of the known timestamp formats
+func (t *SillyTavernTime) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case float64:
+		*t = SillyTavernTime(time.UnixMilli(int64(value)).UTC())
+	case string:
+		*t = SillyTavernTime(parseSillyTavernTime(value))
+	default:
+		*t = SillyTavernTime{}
+	}
+	return nil
+}
+
+// parseSillyTavernTime parses a timestamp string, returning the zero time if no layout matches
+func parseSillyTavernTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if milliseconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(milliseconds).UTC()
+	}
+	for _, layout := range sillyTavernLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// SillyTavernHeader is the first line of a SillyTavern chat file
+type SillyTavernHeader struct {
+	UserName      string                 `json:"user_name"`
+	CharacterName string                 `json:"character_name"`
+	CreateDate    string                 `json:"create_date"`
+	ChatMetadata  map[string]interface{} `json:"chat_metadata"`
+}
+
+// SillyTavernMessage is a message line of a SillyTavern chat file.
+// Swipes are the alternative texts of a character message, SwipeID selects the one shown as Mes.
+type SillyTavernMessage struct {
+	Name      string                 `json:"name"`
+	IsUser    bool                   `json:"is_user"`
+	IsSystem  bool                   `json:"is_system"`
+	SendDate  SillyTavernTime        `json:"send_date"`
+	Mes       string                 `json:"mes"`
+	Swipes    []string               `json:"swipes,omitempty"`
+	SwipeID   int                    `json:"swipe_id,omitempty"`
+	SwipeInfo []SillyTavernSwipeInfo `json:"swipe_info,omitempty"`
+	Extra     map[string]interface{} `json:"extra"`
+}
+
+// SillyTavernSwipeInfo holds the metadata of a swipe
+type SillyTavernSwipeInfo struct {
+	SendDate SillyTavernTime        `json:"send_date"`
+	Extra    map[string]interface{} `json:"extra"`
+}
+
+// WriteSillyTavernChat writes turns in chronological order as a SillyTavern .jsonl chat.
+// Candidates become swipes, with the primary candidate selected. Authors without a name on the turn are
+// named userName or characterName.
+func WriteSillyTavernChat(w io.Writer, turns []*Turn, userName, characterName string) error {
+	createDate := time.Now()
+	if len(turns) > 0 && !turns[0].CreateTime.IsZero() {
+		createDate = turns[0].CreateTime
+	}
+
+	encoder := json.NewEncoder(w)
+	err := encoder.Encode(SillyTavernHeader{
+		UserName:      userName,
+		CharacterName: characterName,
+		CreateDate:    createDate.UTC().Format(sillyTavernCreateDateLayout),
+		ChatMetadata:  map[string]interface{}{},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, turn := range turns {
+		err = encoder.Encode(newSillyTavernMessage(turn, userName, characterName))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newSillyTavernMessage converts a turn to a SillyTavern message
+func newSillyTavernMessage(turn *Turn, userName, characterName string) SillyTavernMessage {
+	message := SillyTavernMessage{
+		Name:     turn.Author.Name,
+		IsUser:   turn.Author.IsHuman,
+		SendDate: SillyTavernTime(turn.CreateTime),
+		Extra:    map[string]interface{}{},
+	}
+	if message.Name == "" {
+		message.Name = characterName
+		if turn.Author.IsHuman {
+			message.Name = userName
+		}
+	}
+	if candidate := turn.PrimaryCandidate(); candidate != nil {
+		message.Mes = candidate.Text
+	}
+
+	// SillyTavern keeps swipes for character messages only
+	if turn.Author.IsHuman {
+		return message
+	}
+	for i, candidate := range turn.OrderedCandidates() {
+		if candidate.CandidateID == turn.PrimaryCandidateID {
+			message.SwipeID = i
+		}
+		message.Swipes = append(message.Swipes, candidate.Text)
+		message.SwipeInfo = append(message.SwipeInfo, SillyTavernSwipeInfo{
+			SendDate: SillyTavernTime(candidate.CreateTime),
+			Extra:    map[string]interface{}{},
+		})
+	}
+	return message
+}
+
+// ReadSillyTavernChat reads a SillyTavern or TavernAI .jsonl chat. The header is nil if the chat has none.
+// Messages become turns in chronological order, with their swipes as candidates; system messages are skipped.
+// The turns get generated IDs and are not part of any chat.
+func ReadSillyTavernChat(r io.Reader) (*SillyTavernHeader, []*Turn, error) {
+	var header *SillyTavernHeader
+	var turns []*Turn
+
+	decoder := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return header, turns, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		var fields map[string]json.RawMessage
+		if err = json.Unmarshal(raw, &fields); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if _, ok := fields["mes"]; !ok {
+			if line > 1 {
+				return nil, nil, fmt.Errorf("line %d: message without text", line)
+			}
+			header = &SillyTavernHeader{}
+			if err = json.Unmarshal(raw, header); err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			continue
+		}
+
+		var message SillyTavernMessage
+		if err = json.Unmarshal(raw, &message); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !message.IsSystem {
+			turns = append(turns, message.turn())
+		}
+	}
+}
+
+// turn converts the message to a turn with generated IDs
+func (m *SillyTavernMessage) turn() *Turn {
+	turn := &Turn{
+		TurnKey:    TurnKey{TurnID: generateUUID()},
+		CreateTime: time.Time(m.SendDate),
+		State:      "STATE_OK",
+		Author:     AuthorInfo{Name: m.Name, IsHuman: m.IsUser},
+		Candidates: make(map[string]*TurnCandidate),
+	}
+	turn.TurnID = turn.TurnKey.TurnID
+	if !turn.CreateTime.IsZero() {
+		turn.CreateTimeStr = turn.CreateTime.Format(time.RFC3339Nano)
+	}
+
+	swipes := m.Swipes
+	selected := m.SwipeID
+	if len(swipes) == 0 || selected < 0 || selected >= len(swipes) {
+		swipes = []string{m.Mes}
+		selected = 0
+	}
+	for i, text := range swipes {
+		candidate := TurnCandidate{CandidateID: generateUUID(), Text: text, IsFinal: true, CreateTime: turn.CreateTime}
+		if i < len(m.SwipeInfo) && !time.Time(m.SwipeInfo[i].SendDate).IsZero() {
+			candidate.CreateTime = time.Time(m.SwipeInfo[i].SendDate)
+		}
+		if !candidate.CreateTime.IsZero() {
+			candidate.CreateTimeStr = candidate.CreateTime.Format(time.RFC3339Nano)
+		}
+		turn.CandidatesList = append(turn.CandidatesList, candidate)
+	}
+	for i := range turn.CandidatesList {
+		turn.Candidates[turn.CandidatesList[i].CandidateID] = &turn.CandidatesList[i]
+	}
+	turn.PrimaryCandidateID = turn.CandidatesList[selected].CandidateID
+	return turn
+}
+
+// ImportSillyTavernChat calls ImportSillyTavernChatContext with context.Background().
+func (c *Client) ImportSillyTavernChat(characterID string, r io.Reader) (*Chat, error) {
+	return c.ImportSillyTavernChatContext(context.Background(), characterID, r)
+}
+
+// ImportSillyTavernChatContext replays a SillyTavern or TavernAI .jsonl chat into a new chat with a character.
+// Each message is added as a turn of its author without generating anything; character messages keep the text of
+// their selected swipe, other swipes are not replayed. All turns are timestamped at the time of the import.
+// If adding a message fails, the chat is returned along with the error, holding the messages imported so far.
+func (c *Client) ImportSillyTavernChatContext(ctx context.Context, characterID string, r io.Reader) (*Chat, error) {
+	_, turns, err := ReadSillyTavernChat(r)
+	if err != nil {
+		return nil, err
+	}
+
+	chat, _, err := c.CreateChatContext(ctx, characterID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, turn := range turns {
+		author := AuthorPayload{AuthorID: characterID, Name: turn.Author.Name}
+		if turn.Author.IsHuman {
+			author = AuthorPayload{AuthorID: c.UserAccountID, IsHuman: true}
+		}
+		_, err = c.createTurn(ctx, chat.ChatID, author, turn.PrimaryCandidate().Text)
+		if err != nil {
+			return chat, err
+		}
+	}
+	return chat, nil
+}
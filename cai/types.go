@@ -3,6 +3,8 @@ package cai
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -174,13 +176,18 @@ type Avatar struct {
 	FileName string `json:"file_name"`
 }
 
-// GetURL returns the avatar URL.
+// GetURL returns the avatar URL on the public media server.
 func (a *Avatar) GetURL(size int, animated bool) string {
+	return a.GetURLWithBase(DefaultEndpoints().Media, size, animated)
+}
+
+// GetURLWithBase returns the avatar URL on the media server at baseURL.
+func (a *Avatar) GetURLWithBase(baseURL string, size int, animated bool) string {
 	anim := 0
 	if animated {
 		anim = 1
 	}
-	return fmt.Sprintf("https://characterai.io/i/%d/static/avatars/%s?webp=true&anim=%d", size, a.FileName, anim)
+	return fmt.Sprintf("%s/i/%d/static/avatars/%s?webp=true&anim=%d", baseURL, size, a.FileName, anim)
 }
 
 // Voice represents a voice setting.
@@ -378,6 +385,12 @@ func (csr *CharacterSearchResult) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Chat types
+const (
+	ChatTypeOneOnOne = "TYPE_ONE_ON_ONE" // Chat between the user and a single character
+	ChatTypeGroup    = "TYPE_GROUP"      // Group chat (room) between the user and several characters
+)
+
 type TurnKey struct {
 	ChatID string `json:"chat_id"`
 	TurnID string `json:"turn_id"`
@@ -442,6 +455,75 @@ func (t *Turn) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// PrimaryCandidate returns the primary candidate of the turn.
+// If the primary candidate is unknown, the first candidate is returned instead, or nil if there is none.
+func (t *Turn) PrimaryCandidate() *TurnCandidate {
+	if candidate, ok := t.Candidates[t.PrimaryCandidateID]; ok {
+		return candidate
+	}
+	if len(t.CandidatesList) > 0 {
+		return &t.CandidatesList[0]
+	}
+	return nil
+}
+
+// OrderedCandidates returns the candidates of the turn in the order they were generated, oldest first
+func (t *Turn) OrderedCandidates() []*TurnCandidate {
+	candidates := make([]*TurnCandidate, len(t.CandidatesList))
+	for i := range t.CandidatesList {
+		candidates[i] = &t.CandidatesList[i]
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].CreateTime.Before(candidates[j].CreateTime)
+	})
+	return candidates
+}
+
+// Alternatives returns the candidates of the turn other than the primary one, oldest first
+func (t *Turn) Alternatives() []*TurnCandidate {
+	var alternatives []*TurnCandidate
+	for _, candidate := range t.OrderedCandidates() {
+		if candidate.CandidateID != t.PrimaryCandidateID {
+			alternatives = append(alternatives, candidate)
+		}
+	}
+	return alternatives
+}
+
+// IsComplete reports whether all candidates of the turn are final
+func (t *Turn) IsComplete() bool {
+	for _, candidate := range t.CandidatesList {
+		if !candidate.IsFinal {
+			return false
+		}
+	}
+	return len(t.CandidatesList) > 0
+}
+
+// TurnEvent represents a streamed update of a turn being generated.
+type TurnEvent struct {
+	Turn            *Turn  // Turn state as of this update
+	Delta           string // Text appended to the primary candidate since the previous event
+	Final           bool   // Whether generation of the turn is complete, i.e. all its candidates are final
+	SafetyTruncated bool   // Whether the primary candidate was truncated by the safety filter
+	Err             error  // Set if the stream failed; no further events follow
+}
+
+// newTurnEvent creates the event for a turn update, given the primary candidate text of the previous update.
+func newTurnEvent(turn *Turn, previousText string) TurnEvent {
+	event := TurnEvent{Turn: turn, Final: turn.IsComplete()}
+	if candidate := turn.PrimaryCandidate(); candidate != nil {
+		event.SafetyTruncated = candidate.IsFiltered
+		if strings.HasPrefix(candidate.Text, previousText) {
+			event.Delta = candidate.Text[len(previousText):]
+		} else {
+			// The text was rewritten rather than extended
+			event.Delta = candidate.Text
+		}
+	}
+	return event
+}
+
 // AuthorInfo represents the author of a turn
 type AuthorInfo struct {
 	AuthorID string `json:"author_id"`
@@ -488,6 +570,7 @@ func (tc *TurnCandidate) UnmarshalJSON(data []byte) error {
 type Chat struct {
 	ChatID             string    `json:"chat_id"`
 	CharacterID        string    `json:"character_id"`
+	CharacterIDs       []string  `json:"character_ids,omitempty"` // Participating characters of group chats
 	CreatorID          string    `json:"creator_id"`
 	CreateTimeStr      string    `json:"create_time"`
 	CreateTime         time.Time `json:"-"`
@@ -523,9 +606,6 @@ func (c *Chat) UnmarshalJSON(data []byte) error {
 		}
 	}
 
-	// Lowercase the visibility
-	c.Visibility = c.Visibility
-
 	// Create CharacterAvatar instance if CharacterAvatarURI is provided
 	if c.CharacterAvatarURI != "" {
 		c.CharacterAvatar = &Avatar{FileName: c.CharacterAvatarURI}
@@ -1,6 +1,7 @@
 package cai
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,9 +9,14 @@ import (
 	"net/url"
 )
 
-// FetchUser retrieves a public user's information
+// FetchUser calls FetchUserContext with context.Background().
 func (c *Client) FetchUser(username string) (*PublicUser, error) {
-	urlStr := "https://plus.character.ai/chat/user/public/"
+	return c.FetchUserContext(context.Background(), username)
+}
+
+// FetchUserContext retrieves a public user's information
+func (c *Client) FetchUserContext(ctx context.Context, username string) (*PublicUser, error) {
+	urlStr := c.Endpoints.Plus + "/chat/user/public/"
 	headers := c.GetHeaders(false)
 
 	payload := FetchUserRequest{
@@ -21,7 +27,7 @@ func (c *Client) FetchUser(username string) (*PublicUser, error) {
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +38,7 @@ func (c *Client) FetchUser(username string) (*PublicUser, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch user, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch user", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -47,15 +53,20 @@ func (c *Client) FetchUser(username string) (*PublicUser, error) {
 	}
 
 	if result.PublicUser == nil {
-		return nil, fmt.Errorf("failed to fetch user, public user data is missing")
+		return nil, fmt.Errorf("%w: public user data is missing", ErrInvalidResponse)
 	}
 
 	return result.PublicUser, nil
 }
 
-// FollowUser follows a user
+// FollowUser calls FollowUserContext with context.Background().
 func (c *Client) FollowUser(username string) error {
-	urlStr := "https://plus.character.ai/chat/user/follow/"
+	return c.FollowUserContext(context.Background(), username)
+}
+
+// FollowUserContext follows a user
+func (c *Client) FollowUserContext(ctx context.Context, username string) error {
+	urlStr := c.Endpoints.Plus + "/chat/user/follow/"
 	headers := c.GetHeaders(false)
 
 	payload := FollowUserRequest{
@@ -66,14 +77,14 @@ func (c *Client) FollowUser(username string) error {
 		return err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to follow user, status code: %d", resp.StatusCode)
+		return newAPIError("follow user", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -88,15 +99,20 @@ func (c *Client) FollowUser(username string) error {
 	}
 
 	if result.Status != "OK" {
-		return fmt.Errorf("failed to follow user, error: %s", result.Error)
+		return newRejectedError("follow user", resp, body, result.Error)
 	}
 
 	return nil
 }
 
-// UnfollowUser unfollows a user
+// UnfollowUser calls UnfollowUserContext with context.Background().
 func (c *Client) UnfollowUser(username string) error {
-	urlStr := "https://plus.character.ai/chat/user/unfollow/"
+	return c.UnfollowUserContext(context.Background(), username)
+}
+
+// UnfollowUserContext unfollows a user
+func (c *Client) UnfollowUserContext(ctx context.Context, username string) error {
+	urlStr := c.Endpoints.Plus + "/chat/user/unfollow/"
 	headers := c.GetHeaders(false)
 
 	payload := FollowUserRequest{
@@ -107,14 +123,14 @@ func (c *Client) UnfollowUser(username string) error {
 		return err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to unfollow user, status code: %d", resp.StatusCode)
+		return newAPIError("unfollow user", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -129,37 +145,20 @@ func (c *Client) UnfollowUser(username string) error {
 	}
 
 	if result.Status != "OK" {
-		return fmt.Errorf("failed to unfollow user, error: %s", result.Error)
+		return newRejectedError("unfollow user", resp, body, result.Error)
 	}
 
 	return nil
 }
 
-// FetchUserVoices retrieves the voices created by a public user.
+// FetchUserVoices calls FetchUserVoicesContext with context.Background().
 func (c *Client) FetchUserVoices(username string) ([]*Voice, error) {
-	urlStr := fmt.Sprintf("https://neo.character.ai/multimodal/api/v1/voices/search?creatorInfo.username=%s", url.QueryEscape(username))
-	headers := c.GetHeaders(false)
-
-	resp, err := c.Requester.Get(urlStr, headers)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch user voices, status code: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var result SearchVoicesResponse
-	err = json.Unmarshal(body, &result)
-	if err != nil {
-		return nil, err
-	}
+	return c.FetchUserVoicesContext(context.Background(), username)
+}
 
-	return result.Voices, nil
+// FetchUserVoicesContext retrieves all voices created by a public user.
+func (c *Client) FetchUserVoicesContext(ctx context.Context, username string) ([]*Voice, error) {
+	return NewPager(func(ctx context.Context, token string) ([]*Voice, string, error) {
+		return c.searchVoicesPage(ctx, url.Values{"creatorInfo.username": {username}}, token, "fetch user voices")
+	}).Collect(ctx)
 }
@@ -1,17 +1,23 @@
 package cai
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"math/rand"
 	"net/http"
 )
 
-// Ping checks if the service is reachable
+// Ping calls PingContext with context.Background().
 func (c *Client) Ping() (bool, error) {
-	urlStr := "https://neo.character.ai/ping/"
+	return c.PingContext(context.Background())
+}
+
+// PingContext checks if the service is reachable
+func (c *Client) PingContext(ctx context.Context) (bool, error) {
+	urlStr := c.Endpoints.Neo + "/ping/"
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return false, err
 	}
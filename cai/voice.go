@@ -2,6 +2,7 @@ package cai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,19 +13,24 @@ import (
 	"strings"
 )
 
-// FetchVoice retrieves a voice by its ID.
+// FetchVoice calls FetchVoiceContext with context.Background().
 func (c *Client) FetchVoice(voiceID string) (*Voice, error) {
-	urlStr := fmt.Sprintf("https://neo.character.ai/multimodal/api/v1/voices/%s", voiceID)
+	return c.FetchVoiceContext(context.Background(), voiceID)
+}
+
+// FetchVoiceContext retrieves a voice by its ID.
+func (c *Client) FetchVoiceContext(ctx context.Context, voiceID string) (*Voice, error) {
+	urlStr := fmt.Sprintf("%s/multimodal/api/v1/voices/%s", c.Endpoints.Neo, voiceID)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch voice, status code: %d", resp.StatusCode)
+		return nil, newAPIError("fetch voice", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -39,45 +45,72 @@ func (c *Client) FetchVoice(voiceID string) (*Voice, error) {
 	}
 
 	if result.Voice == nil {
-		return nil, errors.New("voice not found")
+		return nil, newRejectedError("fetch voice", resp, body, "voice not found")
 	}
 
 	return result.Voice, nil
 }
 
-// SearchVoices searches for voices by name.
+// SearchVoices calls SearchVoicesContext with context.Background().
 func (c *Client) SearchVoices(query string) ([]*Voice, error) {
-	urlStr := fmt.Sprintf("https://neo.character.ai/multimodal/api/v1/voices/search?query=%s", url.QueryEscape(query))
+	return c.SearchVoicesContext(context.Background(), query)
+}
+
+// SearchVoicesContext searches for voices by name, returning the first page of results.
+// See SearchVoicesPager for the others.
+func (c *Client) SearchVoicesContext(ctx context.Context, query string) ([]*Voice, error) {
+	voices, _, err := c.searchVoicesPage(ctx, url.Values{"query": {query}}, "", "search voices")
+	return voices, err
+}
+
+// SearchVoicesPager returns a pager over the results of a search for voices by name.
+func (c *Client) SearchVoicesPager(query string) *Pager[*Voice] {
+	return NewPager(func(ctx context.Context, token string) ([]*Voice, string, error) {
+		return c.searchVoicesPage(ctx, url.Values{"query": {query}}, token, "search voices")
+	})
+}
+
+// searchVoicesPage retrieves a page of the voices matching a search. operation names the search in errors.
+func (c *Client) searchVoicesPage(ctx context.Context, query url.Values, token string, operation string) ([]*Voice, string, error) {
+	if token != "" {
+		query.Set("pageToken", token)
+	}
+	urlStr := fmt.Sprintf("%s/multimodal/api/v1/voices/search?%s", c.Endpoints.Neo, query.Encode())
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.Get(urlStr, headers)
+	resp, err := c.Requester.GetContext(ctx, urlStr, headers)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to search voices, status code: %d", resp.StatusCode)
+		return nil, "", newAPIError(operation, resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var result SearchVoicesResponse
 	err = json.Unmarshal(body, &result)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return result.Voices, nil
+	return result.Voices, result.NextPageToken, nil
+}
+
+// UploadVoice calls UploadVoiceContext with context.Background().
+func (c *Client) UploadVoice(voiceData []byte, name string, description string, visibility string) (*Voice, error) {
+	return c.UploadVoiceContext(context.Background(), voiceData, name, description, visibility)
 }
 
-// UploadVoice uploads a new voice.
+// UploadVoiceContext uploads a new voice.
 // The voiceData parameter should contain the voice file's data in bytes.
 // The visibility parameter should be "public" or "private".
-func (c *Client) UploadVoice(voiceData []byte, name string, description string, visibility string) (*Voice, error) {
+func (c *Client) UploadVoiceContext(ctx context.Context, voiceData []byte, name string, description string, visibility string) (*Voice, error) {
 	if len(name) < 3 || len(name) > 20 {
 		return nil, errors.New("name must be at least 3 characters and no more than 20")
 	}
@@ -133,15 +166,15 @@ func (c *Client) UploadVoice(voiceData []byte, name string, description string,
 
 	writer.Close()
 
-	urlStr := "https://neo.character.ai/multimodal/api/v1/voices/"
-	resp, err := c.Requester.Post(urlStr, headers, body.Bytes())
+	urlStr := c.Endpoints.Neo + "/multimodal/api/v1/voices/"
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, body.Bytes())
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("failed to upload voice, status code: %d", resp.StatusCode)
+		return nil, newAPIError("upload voice", resp)
 	}
 
 	bodyResp, err := io.ReadAll(resp.Body)
@@ -156,15 +189,20 @@ func (c *Client) UploadVoice(voiceData []byte, name string, description string,
 	}
 
 	if result.Voice == nil {
-		return nil, errors.New("voice not returned in response")
+		return nil, fmt.Errorf("%w: voice not returned", ErrInvalidResponse)
 	}
 
 	// Optionally, call EditVoice to ensure metadata is updated
-	return c.EditVoice(result.Voice.VoiceID, name, description, visibility)
+	return c.EditVoiceContext(ctx, result.Voice.VoiceID, name, description, visibility)
 }
 
-// EditVoice edits an existing voice.
+// EditVoice calls EditVoiceContext with context.Background().
 func (c *Client) EditVoice(voiceID string, name string, description string, visibility string) (*Voice, error) {
+	return c.EditVoiceContext(context.Background(), voiceID, name, description, visibility)
+}
+
+// EditVoiceContext edits an existing voice.
+func (c *Client) EditVoiceContext(ctx context.Context, voiceID string, name string, description string, visibility string) (*Voice, error) {
 	if len(name) < 3 || len(name) > 20 {
 		return nil, errors.New("name must be at least 3 characters and no more than 20")
 	}
@@ -177,7 +215,7 @@ func (c *Client) EditVoice(voiceID string, name string, description string, visi
 	}
 
 	// Fetch the existing voice
-	voice, err := c.FetchVoice(voiceID)
+	voice, err := c.FetchVoiceContext(ctx, voiceID)
 	if err != nil {
 		return nil, err
 	}
@@ -201,21 +239,21 @@ func (c *Client) EditVoice(voiceID string, name string, description string, visi
 		},
 	}
 
-	urlStr := fmt.Sprintf("https://neo.character.ai/multimodal/api/v1/voices/%s", voiceID)
+	urlStr := fmt.Sprintf("%s/multimodal/api/v1/voices/%s", c.Endpoints.Neo, voiceID)
 	headers := c.GetHeaders(false)
 	bodyBytes, err := json.Marshal(updatedVoice)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.Requester.DoRequest("PUT", urlStr, headers, bodyBytes)
+	resp, err := c.Requester.DoRequestContext(ctx, "PUT", urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to edit voice, status code: %d", resp.StatusCode)
+		return nil, newAPIError("edit voice", resp)
 	}
 
 	bodyResp, err := io.ReadAll(resp.Body)
@@ -232,28 +270,38 @@ func (c *Client) EditVoice(voiceID string, name string, description string, visi
 	return result.Voice, nil
 }
 
-// DeleteVoice deletes a voice by its ID.
+// DeleteVoice calls DeleteVoiceContext with context.Background().
 func (c *Client) DeleteVoice(voiceID string) error {
-	urlStr := fmt.Sprintf("https://neo.character.ai/multimodal/api/v1/voices/%s", voiceID)
+	return c.DeleteVoiceContext(context.Background(), voiceID)
+}
+
+// DeleteVoiceContext deletes a voice by its ID.
+func (c *Client) DeleteVoiceContext(ctx context.Context, voiceID string) error {
+	urlStr := fmt.Sprintf("%s/multimodal/api/v1/voices/%s", c.Endpoints.Neo, voiceID)
 	headers := c.GetHeaders(false)
 
-	resp, err := c.Requester.DoRequest("DELETE", urlStr, headers, nil)
+	resp, err := c.Requester.DoRequestContext(ctx, "DELETE", urlStr, headers, nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to delete voice, status code: %d", resp.StatusCode)
+		return newAPIError("delete voice", resp)
 	}
 
 	return nil
 }
 
-// GenerateSpeech generates speech audio for a turn using a specific voice.
-// Returns the audio data as bytes.
+// GenerateSpeech calls GenerateSpeechContext with context.Background().
 func (c *Client) GenerateSpeech(chatID string, turnID string, candidateID string, voiceID string) ([]byte, error) {
-	urlStr := "https://neo.character.ai/multimodal/api/v1/memo/replay"
+	return c.GenerateSpeechContext(context.Background(), chatID, turnID, candidateID, voiceID)
+}
+
+// GenerateSpeechContext generates speech audio for a turn using a specific voice.
+// Returns the audio data as bytes.
+func (c *Client) GenerateSpeechContext(ctx context.Context, chatID string, turnID string, candidateID string, voiceID string) ([]byte, error) {
+	urlStr := c.Endpoints.Neo + "/multimodal/api/v1/memo/replay"
 	headers := c.GetHeaders(false)
 
 	payload := GenerateSpeechPayload{
@@ -267,26 +315,21 @@ func (c *Client) GenerateSpeech(chatID string, turnID string, candidateID string
 		return nil, err
 	}
 
-	resp, err := c.Requester.Post(urlStr, headers, bodyBytes)
+	resp, err := c.Requester.PostContext(ctx, urlStr, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("generate speech", resp)
+	}
+
 	bodyResp, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		var errorResp ErrorResponse
-		err = json.Unmarshal(bodyResp, &errorResp)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate speech, status code: %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("failed to generate speech, error: %s", errorResp.Error.Message)
-	}
-
 	var result GenerateSpeechResponse
 	err = json.Unmarshal(bodyResp, &result)
 	if err != nil {
@@ -295,18 +338,18 @@ func (c *Client) GenerateSpeech(chatID string, turnID string, candidateID string
 
 	audioURL := result.ReplayURL
 	if audioURL == "" {
-		return nil, errors.New("no audio URL returned")
+		return nil, fmt.Errorf("%w: no audio URL returned", ErrInvalidResponse)
 	}
 
 	// Fetch the audio data
-	audioResp, err := c.Requester.Get(audioURL, nil)
+	audioResp, err := c.Requester.GetContext(ctx, audioURL, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer audioResp.Body.Close()
 
 	if audioResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch audio data, status code: %d", audioResp.StatusCode)
+		return nil, newAPIError("fetch audio data", audioResp)
 	}
 
 	audioData, err := io.ReadAll(audioResp.Body)
@@ -0,0 +1,119 @@
+package cai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// longReply is streamed word by word, taking long enough to be aborted
+const longReply = "This reply goes on and on and on for a very long time without ever coming to an end soon"
+
+// AbortSuite tests aborting generations against the fake service
+type AbortSuite struct {
+	FakeSuite
+	chat *cai.Chat
+}
+
+func (s *AbortSuite) SetupTest() {
+	s.FakeSuite.SetupTest()
+	s.server.SetReply(caitest.CharacterID, caitest.StaticReply(longReply))
+	s.server.SetChunkDelay(20 * time.Millisecond)
+
+	var err error
+	s.chat, _, err = s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err, "Failed to create chat")
+}
+
+// assertAborted checks that the fake stopped the generation of the chat's latest turn
+func (s *AbortSuite) assertAborted(chatID string) {
+	// The fake handles commands asynchronously
+	s.Assert().Eventually(func() bool {
+		return s.server.Aborts(chatID) == 1
+	}, time.Second, 10*time.Millisecond, "An abort should be sent")
+	s.Assert().Eventually(func() bool {
+		turns := s.server.Turns(chatID)
+		candidate := turns[len(turns)-1].PrimaryCandidate()
+		return candidate.IsFinal && candidate.Text != longReply
+	}, time.Second, 10*time.Millisecond, "The generation should stop with a partial text")
+}
+
+func (s *AbortSuite) TestSendMessageCancelled() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	turn, err := s.client.SendMessageContext(ctx, caitest.CharacterID, s.chat.ChatID, "Tell me a story")
+	s.Assert().True(errors.Is(err, context.DeadlineExceeded), "Expected the context's error, got %v", err)
+	s.Require().NotNil(turn, "The partial turn should be returned")
+	s.Assert().True(strings.HasPrefix(longReply, turn.PrimaryCandidate().Text))
+	s.Assert().NotEqual(longReply, turn.PrimaryCandidate().Text)
+	s.assertAborted(s.chat.ChatID)
+
+	// The chat can be continued afterwards
+	s.server.SetChunkDelay(0)
+	turn, err = s.client.SendMessage(caitest.CharacterID, s.chat.ChatID, "Go on")
+	s.Require().NoError(err)
+	s.Assert().Equal(longReply, turn.PrimaryCandidate().Text)
+}
+
+func (s *AbortSuite) TestStreamCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := s.client.SendMessageStreamContext(ctx, caitest.CharacterID, s.chat.ChatID, "Tell me a story")
+	s.Require().NoError(err)
+	event := <-events
+	s.Require().NoError(event.Err)
+	s.Assert().False(event.Final)
+	cancel()
+
+	for range events {
+		// Drain until the stream is closed
+	}
+	s.assertAborted(s.chat.ChatID)
+}
+
+func (s *AbortSuite) TestAnotherResponseCancelled() {
+	s.server.SetChunkDelay(0)
+	turn, err := s.client.SendMessage(caitest.CharacterID, s.chat.ChatID, "Tell me a story")
+	s.Require().NoError(err)
+	s.server.SetChunkDelay(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	partial, err := s.client.AnotherResponseContext(ctx, caitest.CharacterID, s.chat.ChatID, turn.TurnKey.TurnID)
+	s.Assert().True(errors.Is(err, context.DeadlineExceeded), "Expected the context's error, got %v", err)
+	s.Require().NotNil(partial, "The partial turn should be returned")
+	s.Assert().Len(partial.CandidatesList, 2)
+	s.Assert().NotEqual(turn.PrimaryCandidateID, partial.PrimaryCandidateID)
+	s.assertAborted(s.chat.ChatID)
+}
+
+func (s *AbortSuite) TestAbortGeneration() {
+	events, err := s.client.SendMessageStream(caitest.CharacterID, s.chat.ChatID, "Tell me a story")
+	s.Require().NoError(err)
+	event := <-events
+	s.Require().NoError(event.Err)
+
+	err = s.client.AbortGeneration(s.chat.ChatID)
+	s.Require().NoError(err, "AbortGeneration returned an error")
+
+	// The stream ends with the partial candidate marked final
+	var last cai.TurnEvent
+	for event := range events {
+		last = event
+	}
+	s.Require().NoError(last.Err)
+	s.Assert().True(last.Final)
+	s.Assert().NotEqual(longReply, last.Turn.PrimaryCandidate().Text)
+}
+
+func TestAbortSuite(t *testing.T) {
+	suite.Run(t, new(AbortSuite))
+}
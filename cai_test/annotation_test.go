@@ -0,0 +1,90 @@
+package cai
+
+import (
+	"testing"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// AnnotationSuite tests candidate feedback against the fake service
+type AnnotationSuite struct {
+	FakeSuite
+}
+
+func (s *AnnotationSuite) TestRateCandidate() {
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+	turn, err := s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().NoError(err)
+
+	err = s.client.RateCandidate(chat.ChatID, turn.TurnKey.TurnID, turn.PrimaryCandidateID, 3)
+	s.Require().NoError(err, "RateCandidate returned an error")
+	stars, _ := s.server.Annotation(turn.PrimaryCandidateID)
+	s.Assert().Equal(3, stars)
+
+	s.Assert().Error(s.client.RateCandidate(chat.ChatID, turn.TurnKey.TurnID, turn.PrimaryCandidateID, 0))
+	s.Assert().Error(s.client.RateCandidate(chat.ChatID, turn.TurnKey.TurnID, turn.PrimaryCandidateID, cai.MaxStarRating+1))
+	s.Assert().Error(s.client.RateCandidate(chat.ChatID, turn.TurnKey.TurnID, "unknown-candidate", 2))
+}
+
+func (s *AnnotationSuite) TestAnnotationsInfluenceGeneration() {
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+	turn, err := s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().NoError(err)
+	s.Assert().Equal(0, s.server.PreviousAnnotations(chat.ChatID)["boring"])
+
+	err = s.client.AnnotateCandidate(chat.ChatID, turn.TurnKey.TurnID, turn.PrimaryCandidateID,
+		cai.AnnotationBoring, cai.AnnotationRepetitive.Negated())
+	s.Require().NoError(err, "AnnotateCandidate returned an error")
+	_, tags := s.server.Annotation(turn.PrimaryCandidateID)
+	s.Assert().Equal([]string{"boring", "not_repetitive"}, tags)
+
+	s.Assert().Error(s.client.AnnotateCandidate(chat.ChatID, turn.TurnKey.TurnID, turn.PrimaryCandidateID), "Tags are required")
+
+	_, err = s.client.AnotherResponse(caitest.CharacterID, chat.ChatID, turn.TurnKey.TurnID)
+	s.Require().NoError(err)
+	previous := s.server.PreviousAnnotations(chat.ChatID)
+	s.Assert().Equal(1, previous["boring"])
+	s.Assert().Equal(1, previous["not_repetitive"])
+	s.Assert().Equal(0, previous["funny"])
+
+	err = s.client.AnnotateCandidate(chat.ChatID, turn.TurnKey.TurnID, turn.PrimaryCandidateID, cai.AnnotationBoring)
+	s.Require().NoError(err)
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Still there?")
+	s.Require().NoError(err)
+	previous = s.server.PreviousAnnotations(chat.ChatID)
+	s.Assert().Equal(1, previous["boring"], "Annotating a candidate again should replace its tags")
+	s.Assert().Equal(0, previous["not_repetitive"], "Annotating a candidate again should replace its tags")
+
+	// A changed opinion replaces the tag with its negation
+	err = s.client.AnnotateCandidate(chat.ChatID, turn.TurnKey.TurnID, turn.PrimaryCandidateID, cai.AnnotationBoring.Negated())
+	s.Require().NoError(err)
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Are you?")
+	s.Require().NoError(err)
+	previous = s.server.PreviousAnnotations(chat.ChatID)
+	s.Assert().Equal(0, previous["boring"])
+	s.Assert().Equal(1, previous["not_boring"])
+
+	// Tags of different candidates add up
+	reply, err := s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Tell me more")
+	s.Require().NoError(err)
+	err = s.client.AnnotateCandidate(chat.ChatID, reply.TurnKey.TurnID, reply.PrimaryCandidateID, cai.AnnotationBoring.Negated())
+	s.Require().NoError(err)
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Go on")
+	s.Require().NoError(err)
+	s.Assert().Equal(2, s.server.PreviousAnnotations(chat.ChatID)["not_boring"])
+
+	// Feedback is kept per chat
+	other, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+	_, err = s.client.SendMessage(caitest.CharacterID, other.ChatID, "Hi")
+	s.Require().NoError(err)
+	s.Assert().Equal(0, s.server.PreviousAnnotations(other.ChatID)["boring"])
+}
+
+func TestAnnotationSuite(t *testing.T) {
+	suite.Run(t, new(AnnotationSuite))
+}
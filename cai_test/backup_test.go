@@ -0,0 +1,173 @@
+package cai
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// BackupSuite tests account backups against the fake service
+type BackupSuite struct {
+	FakeSuite
+	character *cai.Character
+	persona   *cai.Persona
+	voice     *cai.Voice
+	chats     []*cai.Chat
+}
+
+func (s *BackupSuite) SetupTest() {
+	s.FakeSuite.SetupTest()
+
+	var err error
+	s.character, err = s.client.CreateCharacter("Backup Bot", "Hi, I keep copies.", "An archivist",
+		"Keeps copies of everything.", "{{char}} archives chats.", false, "private", "", "")
+	s.Require().NoError(err, "CreateCharacter returned an error")
+	s.persona, err = s.client.CreatePersona("Archivist", "Collects backups.", "")
+	s.Require().NoError(err, "CreatePersona returned an error")
+	s.Require().NoError(s.client.SetDefaultPersona(s.persona.PersonaID))
+	s.Require().NoError(s.client.SetPersona(s.character.ExternalID, s.persona.PersonaID))
+
+	s.voice = s.server.AddVoice(cai.Voice{
+		Name:            "Backup Voice",
+		PreviewAudioURL: s.server.Endpoints().Media + "/audio/preview.mp3",
+	})
+
+	s.chats = nil
+	for _, characterID := range []string{caitest.CharacterID, s.character.ExternalID} {
+		chat, _, err := s.client.CreateChat(characterID, true)
+		s.Require().NoError(err, "CreateChat returned an error")
+		_, err = s.client.SendMessage(characterID, chat.ChatID, "Remember this")
+		s.Require().NoError(err, "SendMessage returned an error")
+		s.chats = append(s.chats, chat)
+	}
+}
+
+func (s *BackupSuite) TestBackupDirectory() {
+	dir := filepath.Join(s.T().TempDir(), "backup")
+	manifest, err := s.client.Backup(dir)
+	s.Require().NoError(err, "Backup returned an error")
+
+	s.Assert().Equal(cai.BackupVersion, manifest.Version)
+	s.Assert().Equal(caitest.Username, manifest.Username)
+	s.Assert().Equal([]string{s.persona.PersonaID}, manifest.Personas)
+	s.Assert().Equal([]string{s.character.ExternalID}, manifest.Characters)
+	s.Assert().Equal([]string{s.voice.VoiceID}, manifest.Voices)
+	s.Assert().ElementsMatch([]string{s.chats[0].ChatID, s.chats[1].ChatID}, manifest.Chats)
+
+	for _, name := range []string{"manifest.json", "profile.json", "settings.json"} {
+		s.Assert().FileExists(filepath.Join(dir, name))
+	}
+
+	audio, err := os.ReadFile(filepath.Join(dir, "voices", s.voice.VoiceID+".mp3"))
+	s.Require().NoError(err, "Preview audio not written")
+	s.Assert().Equal("caitest audio preview", string(audio))
+
+	_, err = s.client.Backup(dir)
+	s.Assert().Error(err, "Backup should refuse a directory which is not empty")
+}
+
+func (s *BackupSuite) TestBackupChats() {
+	dir := filepath.Join(s.T().TempDir(), "backup")
+	_, err := s.client.Backup(dir)
+	s.Require().NoError(err, "Backup returned an error")
+
+	data, err := os.ReadFile(filepath.Join(dir, "chats", s.chats[1].ChatID+".json"))
+	s.Require().NoError(err, "Chat not written")
+	var chat cai.BackupChat
+	s.Require().NoError(json.Unmarshal(data, &chat))
+	s.Assert().Equal(s.character.ExternalID, chat.Chat.CharacterID)
+	s.Require().Len(chat.Turns, 3, "Greeting, message and reply expected")
+	s.Assert().False(chat.Turns[0].Author.IsHuman, "Turns should be in chronological order")
+	s.Assert().Equal("Remember this", chat.Turns[1].PrimaryCandidate().Text)
+}
+
+func (s *BackupSuite) TestRestoreZip() {
+	archive := filepath.Join(s.T().TempDir(), "backup.zip")
+	_, err := s.client.Backup(archive)
+	s.Require().NoError(err, "Backup returned an error")
+
+	target := caitest.NewServer()
+	defer target.Close()
+	client := target.NewClient()
+	defer client.Close()
+
+	result, err := client.Restore(archive, cai.DefaultRestoreOptions())
+	s.Require().NoError(err, "Restore returned an error")
+	s.Require().Contains(result.Characters, s.character.ExternalID)
+	s.Require().Contains(result.Personas, s.persona.PersonaID)
+	characterID := result.Characters[s.character.ExternalID]
+	personaID := result.Personas[s.persona.PersonaID]
+
+	character, err := client.FetchCharacterInfo(characterID)
+	s.Require().NoError(err, "Restored character not found")
+	s.Assert().Equal("Backup Bot", character.Name)
+	s.Assert().Equal("{{char}} archives chats.", character.Definition)
+	s.Assert().Equal("PRIVATE", character.Visibility)
+
+	persona, err := client.FetchMyPersona(personaID)
+	s.Require().NoError(err, "Restored persona not found")
+	s.Assert().Equal("Collects backups.", persona.Definition)
+
+	settings, err := client.FetchMySettings()
+	s.Require().NoError(err)
+	s.Assert().Equal(personaID, settings.DefaultPersonaID)
+	s.Assert().Equal(personaID, settings.PersonaOverrides[characterID])
+}
+
+func (s *BackupSuite) TestRestoreOptions() {
+	dir := filepath.Join(s.T().TempDir(), "backup")
+	_, err := s.client.Backup(dir)
+	s.Require().NoError(err, "Backup returned an error")
+
+	target := caitest.NewServer()
+	defer target.Close()
+	client := target.NewClient()
+	defer client.Close()
+
+	result, err := client.Restore(dir, cai.RestoreOptions{Personas: true})
+	s.Require().NoError(err, "Restore returned an error")
+	s.Assert().Empty(result.Characters)
+	s.Assert().Len(result.Personas, 1)
+	s.Assert().Nil(result.Settings)
+
+	characters, err := client.FetchMyCharacters()
+	s.Require().NoError(err)
+	s.Assert().Empty(characters)
+
+	_, err = client.Restore(filepath.Join(s.T().TempDir(), "missing"), cai.DefaultRestoreOptions())
+	s.Assert().Error(err, "Restore of a missing archive should fail")
+}
+
+func (s *BackupSuite) TestRestorePartial() {
+	dir := filepath.Join(s.T().TempDir(), "backup")
+	_, err := s.client.Backup(dir)
+	s.Require().NoError(err, "Backup returned an error")
+
+	target := caitest.NewServer()
+	defer target.Close()
+	client := target.NewClient()
+	defer client.Close()
+
+	// Personas are restored first, so the second create is the one of the character
+	target.FailRequestAfter("/plus/chat/character/create/", 1, http.StatusForbidden)
+	result, err := client.Restore(dir, cai.DefaultRestoreOptions())
+	s.Require().Error(err, "Restore should fail with the character create")
+	s.Require().NotNil(result, "Restore should return the partial result")
+	s.Assert().Empty(result.Characters)
+	s.Assert().Nil(result.Settings)
+	s.Require().Contains(result.Personas, s.persona.PersonaID)
+
+	persona, err := client.FetchMyPersona(result.Personas[s.persona.PersonaID])
+	s.Require().NoError(err, "Restored persona not found")
+	s.Assert().Equal("Archivist", persona.Name)
+}
+
+func TestBackupSuite(t *testing.T) {
+	suite.Run(t, new(BackupSuite))
+}
@@ -2,6 +2,7 @@ package cai
 
 import (
 	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 	"os"
@@ -12,6 +13,7 @@ type BaseSuite struct {
 	suite.Suite
 	client *cai.Client
 	config *TestConfig
+	server *caitest.Server // Fake service used when no token is configured
 }
 
 type TestConfig struct {
@@ -33,7 +35,18 @@ func LoadTestConfig() *TestConfig {
 
 func (s *BaseSuite) SetupSuite() {
 	s.config = LoadTestConfig()
-	s.client = cai.NewClient(s.config.Token, s.config.WebNextAuth, s.config.Proxy)
+	if s.config.Token == "" {
+		// Run against the fake service
+		s.server = caitest.NewServer()
+		s.server.AddVoice(cai.Voice{VoiceID: "test-voice-id", Name: "Test Voice"})
+		s.config.Token = caitest.Token
+		s.config.CharacterID = caitest.CharacterID
+		s.client = s.server.NewClient()
+	} else {
+		client, err := cai.NewClient(s.config.Token, s.config.WebNextAuth, s.config.Proxy)
+		s.Require().NoError(err)
+		s.client = client
+	}
 	err := s.client.Authenticate()
 	s.Require().NoError(err)
 }
@@ -43,6 +56,9 @@ func (s *BaseSuite) TearDownSuite() {
 		err := s.client.Close()
 		s.Require().NoError(err, "Failed to close client")
 	}
+	if s.server != nil {
+		s.server.Close()
+	}
 }
 
 func (s *BaseSuite) SetupTest() {
@@ -52,5 +68,25 @@ func (s *BaseSuite) SetupTest() {
 
 func (s *BaseSuite) TearDownTest() {
 	// Pause to avoid rate limiting
-	time.Sleep(1 * time.Second)
+	if s.server == nil {
+		time.Sleep(1 * time.Second)
+	}
+}
+
+// FakeSuite runs each test against a fresh fake service and a client connected to it
+type FakeSuite struct {
+	suite.Suite
+	server        *caitest.Server
+	client        *cai.Client
+	clientOptions []cai.ClientOption // Options of the client, set before SetupTest runs
+}
+
+func (s *FakeSuite) SetupTest() {
+	s.server = caitest.NewServer()
+	s.client = s.server.NewClient(s.clientOptions...)
+}
+
+func (s *FakeSuite) TearDownTest() {
+	s.Require().NoError(s.client.Close(), "Failed to close client")
+	s.server.Close()
 }
@@ -0,0 +1,227 @@
+package cai
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caibolt"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// CacheSuite tests the chat cache against the fake service
+type CacheSuite struct {
+	FakeSuite
+	store *cai.MemoryChatStore
+	chat  *cai.Chat
+}
+
+func (s *CacheSuite) SetupTest() {
+	s.store = cai.NewMemoryChatStore()
+	s.clientOptions = []cai.ClientOption{cai.WithChatStore(s.store)}
+	s.FakeSuite.SetupTest()
+	s.server.SetPageSize(2)
+
+	var err error
+	s.chat, _, err = s.client.CreateChat(caitest.CharacterID, true)
+	s.Require().NoError(err, "CreateChat returned an error")
+	for _, text := range []string{"One", "Two", "Three"} {
+		_, err = s.client.SendMessage(caitest.CharacterID, s.chat.ChatID, text)
+		s.Require().NoError(err, "SendMessage returned an error")
+	}
+}
+
+// turnsPath returns the path of the turns endpoint of the chat
+func (s *CacheSuite) turnsPath() string {
+	return "/neo/turns/" + s.chat.ChatID + "/"
+}
+
+// assertTurnsMatch checks that turns, newest first, equal the turns of the chat held by the fake
+func (s *CacheSuite) assertTurnsMatch(turns []*cai.Turn) {
+	expected := s.server.Turns(s.chat.ChatID)
+	s.Require().Len(turns, len(expected), "Cached turns should match the chat")
+	for i, turn := range turns {
+		want := expected[len(expected)-1-i]
+		s.Assert().Equal(want.TurnKey.TurnID, turn.TurnKey.TurnID, "Turn %d", i)
+		s.Assert().Equal(want.PrimaryCandidate().Text, turn.PrimaryCandidate().Text, "Turn %d", i)
+	}
+}
+
+func (s *CacheSuite) TestFirstSyncFetchesEverything() {
+	_, err := s.client.CachedMessages(s.chat.ChatID)
+	s.Assert().ErrorIs(err, cai.ErrNotCached, "Chat should not be cached before the first sync")
+
+	turns, err := s.client.FetchAllMessages(s.chat.ChatID, false)
+	s.Require().NoError(err, "FetchAllMessages returned an error")
+	s.assertTurnsMatch(turns)
+	s.Assert().Equal(4, s.server.Requests(s.turnsPath()), "Seven turns take four pages")
+
+	cached, err := s.client.CachedMessages(s.chat.ChatID)
+	s.Require().NoError(err, "CachedMessages returned an error")
+	s.assertTurnsMatch(cached)
+
+	chat, err := s.client.CachedChat(s.chat.ChatID)
+	s.Require().NoError(err, "CachedChat returned an error")
+	s.Assert().Equal(caitest.CharacterID, chat.CharacterID)
+}
+
+func (s *CacheSuite) TestIncrementalSync() {
+	_, err := s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+	requests := s.server.Requests(s.turnsPath())
+
+	// Turns added by another client are not seen until the next sync
+	other := s.server.NewClient()
+	defer other.Close()
+	_, err = other.SendMessage(caitest.CharacterID, s.chat.ChatID, "Four")
+	s.Require().NoError(err, "SendMessage returned an error")
+
+	turns, err := s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+	s.assertTurnsMatch(turns)
+	s.Assert().Equal(requests+2, s.server.Requests(s.turnsPath()), "Sync should stop at the newest cached turn")
+}
+
+func (s *CacheSuite) TestRecordsEvents() {
+	_, err := s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+
+	reply, err := s.client.SendMessage(caitest.CharacterID, s.chat.ChatID, "Four")
+	s.Require().NoError(err, "SendMessage returned an error")
+	turns := s.server.Turns(s.chat.ChatID)
+	oldest := turns[1]
+	_, err = s.client.EditMessage(s.chat.ChatID, oldest.TurnKey.TurnID, oldest.PrimaryCandidateID, "Edited")
+	s.Require().NoError(err, "EditMessage returned an error")
+	s.Require().NoError(s.client.DeleteMessage(s.chat.ChatID, turns[2].TurnKey.TurnID))
+
+	cached, err := s.client.CachedMessages(s.chat.ChatID)
+	s.Require().NoError(err, "CachedMessages returned an error")
+	s.assertTurnsMatch(cached)
+	s.Assert().Equal(reply.TurnKey.TurnID, cached[0].TurnKey.TurnID, "Reply should be recorded")
+
+	// Recorded turns are fetched again, back to the newest turn of the previous sync
+	requests := s.server.Requests(s.turnsPath())
+	_, err = s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+	s.Assert().Equal(requests+2, s.server.Requests(s.turnsPath()))
+}
+
+func (s *CacheSuite) TestSyncFindsTurnsBeforeRecordedOnes() {
+	_, err := s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+
+	// Turns of another client are followed by turns the client records itself
+	other := s.server.NewClient()
+	defer other.Close()
+	_, err = other.SendMessage(caitest.CharacterID, s.chat.ChatID, "Four")
+	s.Require().NoError(err, "SendMessage returned an error")
+	_, err = s.client.SendMessage(caitest.CharacterID, s.chat.ChatID, "Five")
+	s.Require().NoError(err, "SendMessage returned an error")
+
+	turns, err := s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+	s.assertTurnsMatch(turns)
+
+	// The next sync stops at the newest turn again
+	requests := s.server.Requests(s.turnsPath())
+	turns, err = s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+	s.assertTurnsMatch(turns)
+	s.Assert().Equal(requests+1, s.server.Requests(s.turnsPath()))
+}
+
+func (s *CacheSuite) TestSyncDropsRemotelyDeletedTurns() {
+	_, err := s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+
+	turns := s.server.Turns(s.chat.ChatID)
+	other := s.server.NewClient()
+	defer other.Close()
+	s.Require().NoError(other.DeleteMessage(s.chat.ChatID, turns[len(turns)-1].TurnKey.TurnID))
+
+	synced, err := s.client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+	s.assertTurnsMatch(synced)
+}
+
+func (s *CacheSuite) TestPinnedOnlyIsNotCached() {
+	turns := s.server.Turns(s.chat.ChatID)
+	s.Require().NoError(s.client.PinMessage(s.chat.ChatID, turns[1].TurnKey.TurnID))
+
+	pinned, err := s.client.FetchAllMessages(s.chat.ChatID, true)
+	s.Require().NoError(err, "FetchAllMessages returned an error")
+	s.Require().Len(pinned, 1)
+	_, err = s.client.CachedMessages(s.chat.ChatID)
+	s.Assert().ErrorIs(err, cai.ErrNotCached)
+}
+
+func (s *CacheSuite) TestBoltStoreOffline() {
+	path := filepath.Join(s.T().TempDir(), "chats.db")
+	store, err := caibolt.Open(path)
+	s.Require().NoError(err, "Open returned an error")
+	client := s.server.NewClient(cai.WithChatStore(store))
+	_, err = client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+	s.Require().NoError(client.Close())
+	s.Require().NoError(store.Close())
+
+	s.server.Close()
+	store, err = caibolt.Open(path)
+	s.Require().NoError(err, "Open returned an error")
+	defer store.Close()
+	offline := s.server.NewClient(cai.WithChatStore(store))
+	defer offline.Close()
+
+	_, err = offline.FetchAllMessages(s.chat.ChatID, false)
+	s.Assert().Error(err, "Service should be unreachable")
+
+	cached, err := offline.CachedMessages(s.chat.ChatID)
+	s.Require().NoError(err, "CachedMessages returned an error")
+	s.assertTurnsMatch(cached)
+
+	chats, err := offline.CachedChats()
+	s.Require().NoError(err, "CachedChats returned an error")
+	s.Require().Len(chats, 1)
+	s.Assert().Equal(s.chat.ChatID, chats[0].ChatID)
+}
+
+// blockingStore is a ChatStore whose writes of turns wait until release is closed
+type blockingStore struct {
+	*cai.MemoryChatStore
+	release chan struct{}
+}
+
+func (b *blockingStore) PutTurns(chatID string, turns []*cai.Turn) error {
+	<-b.release
+	return b.MemoryChatStore.PutTurns(chatID, turns)
+}
+
+func (s *CacheSuite) TestSlowStoreDoesNotBlockReplies() {
+	store := &blockingStore{MemoryChatStore: cai.NewMemoryChatStore(), release: make(chan struct{})}
+	client := s.server.NewClient(cai.WithChatStore(store))
+	defer client.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.SendMessage(caitest.CharacterID, s.chat.ChatID, "Four")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		s.Require().NoError(err, "SendMessage returned an error")
+	case <-time.After(5 * time.Second):
+		close(store.release)
+		s.FailNow("SendMessage waited for the chat store")
+	}
+
+	close(store.release)
+	turns, err := client.SyncChat(s.chat.ChatID)
+	s.Require().NoError(err, "SyncChat returned an error")
+	s.assertTurnsMatch(turns)
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
@@ -0,0 +1,346 @@
+package cai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// FakeServerSuite tests the client against the fake service only, using its scripting features
+type FakeServerSuite struct {
+	FakeSuite
+}
+
+func (s *FakeServerSuite) TestScriptedReply() {
+	s.server.SetReply(caitest.CharacterID, caitest.StaticReply("Scripted answer"))
+
+	chat, greeting, err := s.client.CreateChat(caitest.CharacterID, true)
+	s.Require().NoError(err, "CreateChat returned an error")
+	s.Assert().Equal("Hello! I am a test character.", greeting.PrimaryCandidate().Text)
+
+	turn, err := s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().NoError(err, "SendMessage returned an error")
+	s.Assert().Equal("Scripted answer", turn.PrimaryCandidate().Text)
+	s.Assert().Equal("Test Character", turn.Author.Name)
+
+	turns := s.server.Turns(chat.ChatID)
+	s.Require().Len(turns, 3, "Chat should hold greeting, message and reply")
+	s.Assert().True(turns[1].Author.IsHuman)
+	s.Assert().Equal("Hi", turns[1].PrimaryCandidate().Text)
+}
+
+func (s *FakeServerSuite) TestSendMessageStream() {
+	s.server.SetReply(caitest.CharacterID, caitest.ChunkedReply("One, ", "two, ", "three."))
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+
+	events, err := s.client.SendMessageStream(caitest.CharacterID, chat.ChatID, "Count to three")
+	s.Require().NoError(err, "SendMessageStream returned an error")
+
+	var deltas []string
+	var last cai.TurnEvent
+	for event := range events {
+		s.Require().NoError(event.Err)
+		deltas = append(deltas, event.Delta)
+		last = event
+	}
+	s.Assert().Equal([]string{"One, ", "two, ", "three."}, deltas)
+	s.Assert().True(last.Final, "Last event should be final")
+	s.Assert().Equal("One, two, three.", last.Turn.PrimaryCandidate().Text)
+}
+
+func (s *FakeServerSuite) TestMultipleCandidates() {
+	count := 0
+	s.server.SetReply(caitest.CharacterID, func(string, string) caitest.Reply {
+		count++
+		return caitest.Reply{Chunks: caitest.Words(fmt.Sprintf("Alternative number %d", count))}
+	})
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+
+	turn, err := s.client.SendMessageCandidates(caitest.CharacterID, chat.ChatID, "Hi", 3)
+	s.Require().NoError(err, "SendMessageCandidates returned an error")
+	s.Require().True(turn.IsComplete(), "All candidates should be final")
+	candidates := turn.OrderedCandidates()
+	s.Require().Len(candidates, 3)
+	for i, candidate := range candidates {
+		s.Assert().Equal(fmt.Sprintf("Alternative number %d", i+1), candidate.Text)
+	}
+	s.Assert().Equal(candidates[0].CandidateID, turn.PrimaryCandidateID)
+	s.Assert().Len(turn.Alternatives(), 2)
+
+	err = s.client.UpdatePrimaryCandidate(chat.ChatID, turn.TurnKey.TurnID, candidates[2].CandidateID)
+	s.Require().NoError(err)
+	s.Assert().Equal(candidates[2].CandidateID, s.server.Turns(chat.ChatID)[1].PrimaryCandidateID)
+
+	another, err := s.client.AnotherResponse(caitest.CharacterID, chat.ChatID, turn.TurnKey.TurnID)
+	s.Require().NoError(err)
+	s.Assert().Equal("Alternative number 4", another.PrimaryCandidate().Text, "AnotherResponse should return the finished candidate")
+	s.Assert().Len(another.OrderedCandidates(), 4)
+
+	_, err = s.client.SendMessageCandidates(caitest.CharacterID, chat.ChatID, "Hi", 0)
+	s.Assert().Error(err)
+}
+
+func (s *FakeServerSuite) TestSafetyTruncatedReply() {
+	s.server.SetReply(caitest.CharacterID, func(string, string) caitest.Reply {
+		return caitest.Reply{Chunks: []string{"Let me"}, SafetyTruncated: true}
+	})
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+
+	turn, err := s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Say something bad")
+	s.Require().NoError(err)
+	s.Assert().True(turn.PrimaryCandidate().IsFiltered, "Candidate should be safety truncated")
+}
+
+func (s *FakeServerSuite) TestNeoError() {
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+
+	s.server.FailNext("create_and_generate_turn", "invalid turn")
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().Error(err, "SendMessage should fail")
+	s.Assert().Contains(err.Error(), "invalid turn")
+
+	// Only the next command fails
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi again")
+	s.Require().NoError(err)
+
+	s.server.SetReply(caitest.CharacterID, caitest.ErrorReply("generation failed"))
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi once more")
+	s.Require().Error(err, "SendMessage should fail")
+	s.Assert().Contains(err.Error(), "generation failed")
+}
+
+func (s *FakeServerSuite) TestAPIErrors() {
+	_, err := s.client.FetchChat("unknown-chat")
+	s.Require().Error(err, "FetchChat should fail")
+	s.Assert().True(errors.Is(err, cai.ErrNotFound), "Error should match ErrNotFound")
+	var apiErr *cai.APIError
+	s.Require().True(errors.As(err, &apiErr), "Error should be an APIError")
+	s.Assert().Equal(404, apiErr.StatusCode)
+	s.Assert().Equal("chat not found", apiErr.Message)
+	s.Assert().Equal(s.server.Endpoints().Neo+"/chat/unknown-chat/", apiErr.Endpoint)
+
+	unauthenticated, err := cai.NewClient("wrong-token", "", "", cai.WithEndpoints(s.server.Endpoints()))
+	s.Require().NoError(err)
+	err = unauthenticated.Authenticate()
+	s.Assert().True(errors.Is(err, cai.ErrAuthenticationFailed), "Error should match ErrAuthenticationFailed")
+
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+	s.client.Requester.SetRetryPolicy(cai.RetryPolicy{})
+	s.server.FailNext("create_and_generate_turn", "Rate limit exceeded")
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Assert().True(errors.Is(err, cai.ErrRateLimited), "Error should match ErrRateLimited")
+	s.Require().True(errors.As(err, &apiErr), "Error should be an APIError")
+	s.Assert().Equal("create_and_generate_turn", apiErr.Endpoint)
+	s.Assert().NotEmpty(apiErr.RequestID)
+
+	// Only the known comments of the safety filter match ErrContentFiltered
+	s.server.FailNext("create_and_generate_turn", "Content filtered")
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Assert().True(errors.Is(err, cai.ErrContentFiltered), "Error should match ErrContentFiltered")
+	s.server.FailNext("create_and_generate_turn", "invalid filter in request")
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().Error(err, "SendMessage should fail")
+	s.Assert().False(errors.Is(err, cai.ErrContentFiltered), "Other comments mentioning a filter should not match")
+}
+
+func (s *FakeServerSuite) TestRetryPolicy() {
+	policy := cai.DefaultRetryPolicy()
+	policy.InitialBackoff = 10 * time.Millisecond
+	s.client.Requester.SetRetryPolicy(policy)
+
+	// Idempotent requests are retried after server errors
+	s.server.FailNextRequest("/neo/ping/", http.StatusServiceUnavailable, 0)
+	s.server.FailNextRequest("/neo/ping/", http.StatusBadGateway, 0)
+	ok, err := s.client.Ping()
+	s.Require().NoError(err)
+	s.Assert().True(ok, "Ping should succeed on the third attempt")
+
+	// Giving up after MaxAttempts
+	for i := 0; i < policy.MaxAttempts; i++ {
+		s.server.FailNextRequest("/neo/ping/", http.StatusServiceUnavailable, 0)
+	}
+	ok, err = s.client.Ping()
+	s.Require().NoError(err)
+	s.Assert().False(ok, "Ping should fail once attempts are exhausted")
+
+	// POST requests are only retried when rate limited, honoring Retry-After
+	s.server.FailNextRequest("/plus/chat/character/info/", http.StatusTooManyRequests, time.Second)
+	start := time.Now()
+	character, err := s.client.FetchCharacterInfo(caitest.CharacterID)
+	s.Require().NoError(err)
+	s.Assert().Equal("Test Character", character.Name)
+	s.Assert().GreaterOrEqual(time.Since(start), time.Second, "Retry-After should be honored")
+
+	s.server.FailNextRequest("/plus/chat/character/info/", http.StatusInternalServerError, 0)
+	_, err = s.client.FetchCharacterInfo(caitest.CharacterID)
+	s.Assert().True(errors.Is(err, cai.ErrServerError), "POST should not be retried after a server error")
+
+	// WebSocket commands are retried after retryable neo_errors
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+	s.server.FailNext("create_and_generate_turn", "Rate limit exceeded")
+	turn, err := s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().NoError(err)
+	s.Assert().Equal("You said: Hi", turn.PrimaryCandidate().Text)
+}
+
+func (s *FakeServerSuite) TestRateLimit() {
+	s.client.Requester.SetRateLimit(cai.FamilyREST, cai.RateLimit{Rate: 20, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := s.client.Ping()
+		s.Require().NoError(err)
+	}
+	s.Assert().GreaterOrEqual(time.Since(start), 80*time.Millisecond, "Requests beyond the burst should wait")
+	stats := s.client.Requester.RateLimitStats(cai.FamilyREST)
+	s.Assert().Equal(int64(4), stats.Allowed)
+	s.Assert().Equal(int64(2), stats.Delayed)
+	s.Assert().Greater(stats.TotalWait, time.Duration(0))
+
+	// Other families are not affected
+	_, err := s.client.FetchVoice("unknown-voice")
+	s.Assert().False(errors.Is(err, cai.ErrRateLimitExceeded))
+	s.Assert().Equal(int64(1), s.client.Requester.RateLimitStats(cai.FamilyVoice).Allowed)
+
+	// Waiting ends with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	s.client.Requester.SetRateLimit(cai.FamilyREST, cai.RateLimit{Rate: 1, Burst: 1})
+	_, err = s.client.PingContext(ctx)
+	s.Require().NoError(err)
+	_, err = s.client.PingContext(ctx)
+	s.Assert().True(errors.Is(err, cai.ErrRateLimitExceeded), "Request should not wait beyond its deadline")
+
+	// A burst below 1 counts as 1, so the bucket starts out with one token
+	s.client.Requester.SetRateLimit(cai.FamilyREST, cai.RateLimit{Rate: 1, FailFast: true})
+	_, err = s.client.Ping()
+	s.Require().NoError(err, "First request should be allowed")
+	_, err = s.client.Ping()
+	s.Assert().True(errors.Is(err, cai.ErrRateLimited), "Second request should be rejected")
+
+	// Fail-fast mode
+	s.client.Requester.SetRateLimit(cai.FamilyNeoTurns, cai.RateLimit{Rate: 1, Burst: 1, FailFast: true})
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().Error(err, "SendMessage should be rejected")
+	s.Assert().True(errors.Is(err, cai.ErrRateLimited), "Error should match ErrRateLimited")
+	s.Assert().Equal(int64(1), s.client.Requester.RateLimitStats(cai.FamilyNeoTurns).Rejected)
+}
+
+func (s *FakeServerSuite) TestConcurrentSendMessages() {
+	const count = 8
+
+	chats := make([]*cai.Chat, count)
+	for i := range chats {
+		chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+		s.Require().NoError(err)
+		chats[i] = chat
+	}
+
+	var wg sync.WaitGroup
+	replies := make([]string, count)
+	errs := make([]error, count)
+	for i := range chats {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			turn, err := s.client.SendMessage(caitest.CharacterID, chats[i].ChatID, fmt.Sprintf("Message %d", i))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			replies[i] = turn.PrimaryCandidate().Text
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range chats {
+		s.Require().NoError(errs[i])
+		s.Assert().Equal(fmt.Sprintf("You said: Message %d", i), replies[i], "Reply should belong to its own chat")
+	}
+}
+
+func (s *FakeServerSuite) TestConcurrentCloseAndSend() {
+	for i := 0; i < 20; i++ {
+		// Slow writes make senders queue up for the connection while it is closed
+		client := s.server.NewClient(cai.WithMiddleware(cai.Middleware{
+			SendFrame: func(frame []byte) ([]byte, error) {
+				time.Sleep(time.Millisecond)
+				return frame, nil
+			},
+		}))
+		client.Requester.SetRateLimit(cai.FamilyNeoTurns, cai.RateLimit{})
+		s.Require().NoError(client.Requester.InitializeWebSocket())
+
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 10; k++ {
+					message := cai.WebSocketMessage{
+						Command:   "abort_generation",
+						RequestID: fmt.Sprintf("request-%d", k),
+						Payload:   cai.AbortGenerationPayload{ChatID: "chat"},
+					}
+					// Sends fail once the connection is closed
+					_ = client.Requester.SendWebSocketMessage(message)
+				}
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(2 * time.Millisecond)
+			_ = client.Close()
+		}()
+
+		finished := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(finished)
+		}()
+		select {
+		case <-finished:
+		case <-time.After(5 * time.Second):
+			s.FailNow("Close and concurrent sends deadlocked")
+		}
+	}
+}
+
+func (s *FakeServerSuite) TestFetchMessagesPaging() {
+	s.server.SetPageSize(2)
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, true)
+	s.Require().NoError(err)
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().NoError(err)
+
+	turns, nextToken, err := s.client.FetchMessages(chat.ChatID, false, "")
+	s.Require().NoError(err)
+	s.Assert().Len(turns, 2)
+	s.Assert().NotEmpty(nextToken, "There should be another page")
+
+	all, err := s.client.FetchAllMessages(chat.ChatID, false)
+	s.Require().NoError(err)
+	s.Require().Len(all, 3)
+	s.Assert().Equal("Hello! I am a test character.", all[2].PrimaryCandidate().Text, "Turns should be newest first")
+}
+
+func TestFakeServerSuite(t *testing.T) {
+	suite.Run(t, new(FakeServerSuite))
+}
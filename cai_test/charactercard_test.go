@@ -0,0 +1,134 @@
+package cai
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// CharacterCardSuite tests Character Card import and export against the fake service
+type CharacterCardSuite struct {
+	FakeSuite
+}
+
+// encodeImage returns a small image in PNG or JPEG format
+func (s *CharacterCardSuite) encodeImage(asPNG bool) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var buffer bytes.Buffer
+	if asPNG {
+		s.Require().NoError(png.Encode(&buffer, img))
+	} else {
+		s.Require().NoError(jpeg.Encode(&buffer, img, nil))
+	}
+	return buffer.Bytes()
+}
+
+func (s *CharacterCardSuite) TestJSONRoundTrip() {
+	card, err := s.client.FetchCharacterCard(caitest.CharacterID)
+	s.Require().NoError(err, "FetchCharacterCard returned an error")
+	s.Assert().Equal(cai.CharacterCardSpec, card.Spec)
+	s.Assert().Equal("Test Character", card.Data.Name)
+	s.Assert().Equal("Hello! I am a test character.", card.Data.FirstMes)
+	s.Assert().Equal("{{char}} is a fake character served by caitest.", card.Data.MesExample)
+
+	data, err := json.Marshal(card)
+	s.Require().NoError(err)
+	parsed, err := cai.ParseCharacterCard(data)
+	s.Require().NoError(err, "ParseCharacterCard returned an error")
+
+	payload := parsed.CreateCharacterPayload()
+	s.Assert().Equal("Test Character", payload.Name)
+	s.Assert().Equal("A character for testing", payload.Title)
+	s.Assert().Equal("Replies to everything in a predictable way.", payload.Description)
+	s.Assert().Equal("{{char}} is a fake character served by caitest.", payload.Definition)
+	s.Assert().Equal("PUBLIC", payload.Visibility)
+	s.Assert().True(payload.Copyable)
+}
+
+func (s *CharacterCardSuite) TestForeignCards() {
+	v1 := `{"name":"Old Card","description":"Short","personality":"Grumpy","scenario":"A tavern","first_mes":"What?","mes_example":"<START>"}`
+	card, err := cai.ParseCharacterCard([]byte(v1))
+	s.Require().NoError(err)
+	payload := card.CreateCharacterPayload()
+	s.Assert().Equal("Old Card", payload.Name)
+	s.Assert().Equal("Short", payload.Description)
+	s.Assert().Equal("Personality: Grumpy\n\nScenario: A tavern\n\n<START>", payload.Definition)
+	s.Assert().Equal("PRIVATE", payload.Visibility)
+
+	long := strings.Repeat("x", 600)
+	v2 := `{"spec":"chara_card_v2","spec_version":"2.0","data":{"name":"New Card","description":"` + long + `","first_mes":"Hi","creator_notes":"Notes"}}`
+	card, err = cai.ParseCharacterCard([]byte(v2))
+	s.Require().NoError(err)
+	payload = card.CreateCharacterPayload()
+	s.Assert().Empty(payload.Description, "A long description should move to the definition")
+	s.Assert().Equal(long, payload.Definition)
+	s.Assert().Equal("Notes", payload.Title)
+
+	_, err = cai.ParseCharacterCard([]byte(`{"spec":"chara_card_v9","data":{}}`))
+	s.Assert().Error(err)
+	_, err = cai.ParseCharacterCard(s.encodeImage(true))
+	s.Assert().Error(err, "A PNG without card should be rejected")
+}
+
+func (s *CharacterCardSuite) TestPNG() {
+	path := filepath.Join(s.T().TempDir(), "avatar.jpg")
+	s.Require().NoError(os.WriteFile(path, s.encodeImage(false), 0o600))
+	avatar, err := s.client.UploadAvatar(path, false)
+	s.Require().NoError(err)
+	character := s.server.AddCharacter(cai.Character{
+		Name:           "Card Character",
+		Greeting:       "Hello from the card.",
+		Title:          "Lives in a PNG",
+		Visibility:     "PRIVATE",
+		AvatarFileName: avatar.FileName,
+	})
+
+	var buffer bytes.Buffer
+	err = s.client.ExportCharacterCardPNG(character.ExternalID, &buffer)
+	s.Require().NoError(err, "ExportCharacterCardPNG returned an error")
+	_, err = png.Decode(bytes.NewReader(buffer.Bytes()))
+	s.Require().NoError(err, "The card should be a valid PNG")
+
+	imported, err := s.client.ImportCharacterCard(buffer.Bytes())
+	s.Require().NoError(err, "ImportCharacterCard returned an error")
+	s.Assert().NotEqual(character.ExternalID, imported.ExternalID)
+	s.Assert().Equal("Card Character", imported.Name)
+	s.Assert().Equal("Lives in a PNG", imported.Title)
+	s.Assert().Equal("Hello from the card.", imported.Greeting)
+	s.Require().NotEmpty(imported.AvatarFileName, "The card image should become the avatar")
+	uploaded, ok := s.server.Avatar(imported.AvatarFileName)
+	s.Require().True(ok, "The card image should be uploaded")
+	s.Assert().NotContains(string(uploaded), "chara\x00", "The card should not be part of the avatar")
+	_, err = png.Decode(bytes.NewReader(uploaded))
+	s.Assert().NoError(err, "The avatar should be a valid PNG")
+
+	// Embedding again replaces the card
+	card := cai.NewCharacterCard(imported)
+	card.Data.Name = "Renamed"
+	var again bytes.Buffer
+	s.Require().NoError(cai.WriteCharacterCardPNG(&again, card, buffer.Bytes()))
+	parsed, err := cai.ParseCharacterCard(again.Bytes())
+	s.Require().NoError(err)
+	s.Assert().Equal("Renamed", parsed.Data.Name)
+
+	// Without avatar, a placeholder image is used
+	buffer.Reset()
+	s.Require().NoError(s.client.ExportCharacterCardPNG(caitest.CharacterID, &buffer))
+	parsed, err = cai.ParseCharacterCard(buffer.Bytes())
+	s.Require().NoError(err)
+	s.Assert().Equal("Test Character", parsed.Data.Name)
+}
+
+func TestCharacterCardSuite(t *testing.T) {
+	suite.Run(t, new(CharacterCardSuite))
+}
@@ -0,0 +1,130 @@
+package cai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// ExportSuite tests exporting chats from the fake service
+type ExportSuite struct {
+	FakeSuite
+	character *cai.Character
+	avatar    []byte
+	chat      *cai.Chat
+}
+
+func (s *ExportSuite) SetupTest() {
+	s.FakeSuite.SetupTest()
+
+	var buffer bytes.Buffer
+	s.Require().NoError(png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 2, 2))))
+	s.avatar = buffer.Bytes()
+	path := filepath.Join(s.T().TempDir(), "avatar.png")
+	s.Require().NoError(os.WriteFile(path, s.avatar, 0o600))
+	avatar, err := s.client.UploadAvatar(path, false)
+	s.Require().NoError(err, "Failed to upload avatar")
+
+	s.character = s.server.AddCharacter(cai.Character{
+		Name:           "Export <Character>",
+		Title:          "Exported in all formats",
+		Greeting:       "Welcome to the export.",
+		AvatarFileName: avatar.FileName,
+	})
+	s.server.SetReply(s.character.ExternalID, caitest.StaticReply("First answer"))
+
+	s.chat, _, err = s.client.CreateChat(s.character.ExternalID, true)
+	s.Require().NoError(err)
+	reply, err := s.client.SendMessage(s.character.ExternalID, s.chat.ChatID, "Hello there")
+	s.Require().NoError(err)
+	s.server.SetReply(s.character.ExternalID, caitest.StaticReply("Second answer"))
+	reply, err = s.client.AnotherResponse(s.character.ExternalID, s.chat.ChatID, reply.TurnKey.TurnID)
+	s.Require().NoError(err)
+	s.Require().NoError(s.client.PinMessage(s.chat.ChatID, reply.TurnKey.TurnID))
+}
+
+// export writes the chat in a format
+func (s *ExportSuite) export(format cai.ExportFormat, options cai.ExportOptions) string {
+	var buffer bytes.Buffer
+	err := s.client.ExportChat(s.chat.ChatID, &buffer, format, options)
+	s.Require().NoError(err, "ExportChat returned an error")
+	return buffer.String()
+}
+
+func (s *ExportSuite) TestJSON() {
+	var export cai.ChatExport
+	s.Require().NoError(json.Unmarshal([]byte(s.export(cai.ExportJSON, cai.ExportOptions{})), &export))
+	s.Assert().Equal(cai.ChatExportVersion, export.Version)
+	s.Assert().Equal(s.chat.ChatID, export.Chat.ChatID)
+	s.Require().Len(export.Participants, 2)
+	s.Assert().True(export.Participants[0].IsHuman)
+	s.Assert().Equal("Export <Character>", export.Participants[1].Name)
+	s.Assert().NotEmpty(export.Participants[1].AvatarURL)
+
+	s.Require().Len(export.Turns, 3)
+	s.Assert().Equal("Welcome to the export.", export.Turns[0].Text)
+	s.Assert().Equal("Hello there", export.Turns[1].Text)
+	s.Assert().True(export.Turns[1].IsHuman)
+	s.Assert().Equal("Second answer", export.Turns[2].Text)
+	s.Assert().False(export.Turns[2].IsPinned, "Pins are only included on request")
+	s.Assert().Empty(export.Turns[2].Candidates, "Candidates are only included on request")
+
+	s.Require().NoError(json.Unmarshal([]byte(s.export(cai.ExportJSON, cai.ExportOptions{Candidates: true, Pins: true})), &export))
+	s.Assert().True(export.Turns[2].IsPinned)
+	s.Require().Len(export.Turns[2].Candidates, 2)
+	s.Assert().Equal("First answer", export.Turns[2].Candidates[0].Text)
+	s.Assert().False(export.Turns[2].Candidates[0].IsPrimary)
+	s.Assert().True(export.Turns[2].Candidates[1].IsPrimary)
+	s.Assert().Equal([]cai.ExportedCandidate{export.Turns[2].Candidates[0]}, export.Turns[2].Alternatives())
+}
+
+func (s *ExportSuite) TestMarkdownAndText() {
+	markdown := s.export(cai.ExportMarkdown, cai.ExportOptions{})
+	s.Assert().True(strings.HasPrefix(markdown, "# Chat with Export <Character>\n"))
+	s.Assert().Contains(markdown, "**Export <Character>** · ")
+	s.Assert().Contains(markdown, "**Caitest User** · ")
+	s.Assert().Contains(markdown, "\n\nSecond answer\n")
+	s.Assert().NotContains(markdown, "First answer")
+	s.Assert().NotContains(markdown, "📌")
+
+	markdown = s.export(cai.ExportMarkdown, cai.ExportOptions{Candidates: true, Pins: true})
+	s.Assert().Contains(markdown, "**Export <Character>** 📌 · ")
+	s.Assert().Contains(markdown, "> *Alternative 1:*\n> First answer\n")
+
+	text := s.export(cai.ExportText, cai.ExportOptions{Candidates: true, Pins: true})
+	s.Assert().Contains(text, "] Caitest User:\nHello there\n")
+	s.Assert().Contains(text, "] Export <Character> [pinned]:\nSecond answer\n  (alternative 1) First answer\n")
+}
+
+func (s *ExportSuite) TestHTML() {
+	page := s.export(cai.ExportHTML, cai.ExportOptions{Candidates: true, Pins: true})
+	s.Assert().True(strings.HasPrefix(page, "<!DOCTYPE html>"))
+	s.Assert().Contains(page, "<title>Chat with Export &lt;Character&gt;</title>", "Names should be escaped")
+	s.Assert().Contains(page, `src="data:image/png;base64,`+base64.StdEncoding.EncodeToString(s.avatar)+`"`, "The avatar should be embedded")
+	s.Assert().Contains(page, `<div class="avatar">C</div>`, "The user without avatar gets a placeholder")
+	s.Assert().Contains(page, "📌 pinned")
+	s.Assert().Contains(page, "Alternative 1</span>\nFirst answer")
+
+	page = s.export(cai.ExportHTML, cai.ExportOptions{})
+	s.Assert().NotContains(page, "First answer")
+	s.Assert().NotContains(page, "📌 pinned")
+}
+
+func (s *ExportSuite) TestUnsupportedFormat() {
+	err := s.client.ExportChat(s.chat.ChatID, &bytes.Buffer{}, "pdf", cai.ExportOptions{})
+	s.Assert().Error(err)
+}
+
+func TestExportSuite(t *testing.T) {
+	suite.Run(t, new(ExportSuite))
+}
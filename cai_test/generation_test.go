@@ -0,0 +1,151 @@
+package cai
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// GenerationOptionsSuite tests generating replies with options against the fake service
+type GenerationOptionsSuite struct {
+	FakeSuite
+	chat *cai.Chat
+}
+
+func (s *GenerationOptionsSuite) SetupTest() {
+	s.FakeSuite.SetupTest()
+
+	var err error
+	s.chat, _, err = s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err, "Failed to create chat")
+}
+
+func (s *GenerationOptionsSuite) TestSendMessageWithOptions() {
+	voice := s.server.AddVoice(cai.Voice{Name: "Option Voice"})
+	persona, err := s.client.CreatePersona("Option Persona", "A persona chosen per message", "")
+	s.Require().NoError(err)
+
+	reply, err := s.client.SendMessageWithOptions(caitest.CharacterID, s.chat.ChatID, "Hallo", cai.GenerationOptions{
+		Language:      "de",
+		UserName:      "Alex",
+		PersonaID:     persona.PersonaID,
+		TTS:           true,
+		VoiceID:       voice.VoiceID,
+		NumCandidates: 2,
+	})
+	s.Require().NoError(err, "SendMessageWithOptions returned an error")
+	s.Assert().Len(reply.Turn.CandidatesList, 2)
+	s.Assert().Equal("caitest audio "+reply.Turn.PrimaryCandidateID, string(reply.Audio))
+
+	request, ok := s.server.LastGenerateRequest(s.chat.ChatID)
+	s.Require().True(ok)
+	s.Assert().Equal("de", request.SelectedLanguage)
+	s.Assert().Equal("Alex", request.UserName)
+	s.Assert().True(request.TTSEnabled)
+	s.Assert().Equal(2, request.NumCandidates)
+	s.Assert().Equal(persona.PersonaID, request.PersonaID, "The persona should be in effect while generating")
+	s.Assert().Equal("Alex", s.server.Turns(s.chat.ChatID)[0].Author.Name, "The message should be authored by the user name")
+
+	settings, err := s.client.FetchMySettings()
+	s.Require().NoError(err)
+	s.Assert().NotContains(settings.PersonaOverrides, caitest.CharacterID, "The persona should only apply to the message")
+
+	// Without options nothing is set
+	reply, err = s.client.SendMessageWithOptions(caitest.CharacterID, s.chat.ChatID, "Hello", cai.GenerationOptions{})
+	s.Require().NoError(err)
+	s.Assert().Nil(reply.Audio)
+	request, _ = s.server.LastGenerateRequest(s.chat.ChatID)
+	s.Assert().Equal(caitest.GenerateRequest{CharacterID: caitest.CharacterID, NumCandidates: 1, PreviousAnnotations: request.PreviousAnnotations}, request)
+
+	_, err = s.client.SendMessageWithOptions(caitest.CharacterID, s.chat.ChatID, "Hello", cai.GenerationOptions{NumCandidates: -1})
+	s.Assert().Error(err, "A negative number of candidates should be rejected")
+}
+
+func (s *GenerationOptionsSuite) TestAnotherResponseWithOptions() {
+	voice := s.server.AddVoice(cai.Voice{Name: "Character Voice"})
+	character := s.server.AddCharacter(cai.Character{Name: "Speaking Character", DefaultVoiceID: voice.VoiceID})
+	chat, _, err := s.client.CreateChat(character.ExternalID, false)
+	s.Require().NoError(err)
+	turn, err := s.client.SendMessage(character.ExternalID, chat.ChatID, "Hi")
+	s.Require().NoError(err)
+
+	reply, err := s.client.AnotherResponseWithOptions(character.ExternalID, chat.ChatID, turn.TurnKey.TurnID, cai.GenerationOptions{
+		Language: "fr",
+		UserName: "Alex",
+		TTS:      true,
+	})
+	s.Require().NoError(err, "AnotherResponseWithOptions returned an error")
+	s.Assert().Len(reply.Turn.CandidatesList, 2)
+	s.Assert().Equal("caitest audio "+reply.Turn.PrimaryCandidateID, string(reply.Audio), "The character's voice should be used")
+
+	request, _ := s.server.LastGenerateRequest(chat.ChatID)
+	s.Assert().Equal("fr", request.SelectedLanguage)
+	s.Assert().Equal("Alex", request.UserName)
+	s.Assert().True(request.TTSEnabled)
+
+	// An existing override is restored after generating with another persona
+	chosen, err := s.client.CreatePersona("Chosen Persona", "The persona chosen for the character", "")
+	s.Require().NoError(err)
+	other, err := s.client.CreatePersona("Other Persona", "A persona chosen for one reply", "")
+	s.Require().NoError(err)
+	s.Require().NoError(s.client.SetPersona(character.ExternalID, chosen.PersonaID))
+	_, err = s.client.AnotherResponseWithOptions(character.ExternalID, chat.ChatID, turn.TurnKey.TurnID, cai.GenerationOptions{PersonaID: other.PersonaID})
+	s.Require().NoError(err)
+	request, _ = s.server.LastGenerateRequest(chat.ChatID)
+	s.Assert().Equal(other.PersonaID, request.PersonaID)
+	settings, err := s.client.FetchMySettings()
+	s.Require().NoError(err)
+	s.Assert().Equal(chosen.PersonaID, settings.PersonaOverrides[character.ExternalID])
+
+	_, err = s.client.AnotherResponseWithOptions(character.ExternalID, chat.ChatID, turn.TurnKey.TurnID, cai.GenerationOptions{NumCandidates: 2})
+	s.Assert().Error(err, "Only a single candidate can be generated")
+
+	// Speech needs a voice
+	turn, err = s.client.SendMessage(caitest.CharacterID, s.chat.ChatID, "Hi")
+	s.Require().NoError(err)
+	_, err = s.client.AnotherResponseWithOptions(caitest.CharacterID, s.chat.ChatID, turn.TurnKey.TurnID, cai.GenerationOptions{TTS: true})
+	s.Assert().Error(err)
+}
+
+func (s *GenerationOptionsSuite) TestSetPersonaConcurrently() {
+	persona, err := s.client.CreatePersona("Option Persona", "A persona chosen per character", "")
+	s.Require().NoError(err)
+	var characterIDs []string
+	for i := 0; i < 10; i++ {
+		characterIDs = append(characterIDs, s.server.AddCharacter(cai.Character{Name: fmt.Sprintf("Character %d", i)}).ExternalID)
+	}
+
+	// Each override is a read-modify-write of the account settings, which must not lose concurrent updates
+	var wg sync.WaitGroup
+	for _, characterID := range characterIDs {
+		wg.Add(1)
+		go func(characterID string) {
+			defer wg.Done()
+			s.Assert().NoError(s.client.SetPersona(characterID, persona.PersonaID))
+		}(characterID)
+	}
+	wg.Wait()
+
+	settings, err := s.client.FetchMySettings()
+	s.Require().NoError(err)
+	for _, characterID := range characterIDs {
+		s.Assert().Equal(persona.PersonaID, settings.PersonaOverrides[characterID])
+	}
+
+	// The override is not touched by sending messages
+	chat, _, err := s.client.CreateChat(characterIDs[0], false)
+	s.Require().NoError(err)
+	_, err = s.client.SendMessageWithOptions(characterIDs[0], chat.ChatID, "Hello", cai.GenerationOptions{})
+	s.Require().NoError(err)
+	settings, err = s.client.FetchMySettings()
+	s.Require().NoError(err)
+	s.Assert().Equal(persona.PersonaID, settings.PersonaOverrides[characterIDs[0]])
+}
+
+func TestGenerationOptionsSuite(t *testing.T) {
+	suite.Run(t, new(GenerationOptionsSuite))
+}
@@ -0,0 +1,81 @@
+package cai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// GroupChatSuite tests group chats against the fake service, which scripts the replies of each character
+type GroupChatSuite struct {
+	FakeSuite
+	second *cai.Character
+}
+
+func (s *GroupChatSuite) SetupTest() {
+	s.FakeSuite.SetupTest()
+	s.second = s.server.AddCharacter(cai.Character{Name: "Second Character", Greeting: "Hi, I am second."})
+	s.server.SetReply(caitest.CharacterID, caitest.StaticReply("First here"))
+	s.server.SetReply(s.second.ExternalID, caitest.StaticReply("Second here"))
+}
+
+func (s *GroupChatSuite) TestCreateAndList() {
+	chat, err := s.client.CreateGroupChat("Test room", []string{caitest.CharacterID, s.second.ExternalID})
+	s.Require().NoError(err, "CreateGroupChat returned an error")
+	s.Assert().Equal(cai.ChatTypeGroup, chat.ChatType)
+	s.Assert().Equal("Test room", chat.ChatName)
+	s.Assert().Equal([]string{caitest.CharacterID, s.second.ExternalID}, chat.CharacterIDs)
+
+	// One-on-one chats are not listed as rooms
+	_, _, err = s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+
+	rooms, err := s.client.FetchGroupChats()
+	s.Require().NoError(err, "FetchGroupChats returned an error")
+	s.Require().Len(rooms, 1)
+	s.Assert().Equal(chat.ChatID, rooms[0].ChatID)
+	createTime, err := time.Parse(time.RFC3339Nano, chat.CreateTimeStr)
+	s.Require().NoError(err)
+	s.Assert().True(createTime.Equal(rooms[0].CreateTime), "CreateTime should be parsed from CreateTimeStr")
+
+	_, err = s.client.CreateGroupChat("Broken room", []string{caitest.CharacterID, "unknown-character"})
+	s.Assert().Error(err, "Unknown characters should be rejected")
+}
+
+func (s *GroupChatSuite) TestSendGroupMessage() {
+	chat, err := s.client.CreateGroupChat("Test room", []string{caitest.CharacterID, s.second.ExternalID})
+	s.Require().NoError(err)
+
+	replies, err := s.client.SendGroupMessage(chat.ChatID, "Hello everyone")
+	s.Require().NoError(err, "SendGroupMessage returned an error")
+	s.Require().Len(replies, 2, "Every participant should reply")
+	s.Assert().Equal("First here", replies[0].PrimaryCandidate().Text)
+	s.Assert().Equal("Test Character", replies[0].Author.Name)
+	s.Assert().Equal("Second here", replies[1].PrimaryCandidate().Text)
+	s.Assert().Equal("Second Character", replies[1].Author.Name)
+
+	// Choosing the speakers and their order
+	replies, err = s.client.SendGroupMessage(chat.ChatID, "Only you, second", s.second.ExternalID)
+	s.Require().NoError(err)
+	s.Require().Len(replies, 1)
+	s.Assert().Equal(s.second.ExternalID, replies[0].Author.AuthorID)
+
+	turn, err := s.client.GenerateGroupTurn(chat.ChatID, caitest.CharacterID)
+	s.Require().NoError(err, "GenerateGroupTurn returned an error")
+	s.Assert().Equal("First here", turn.PrimaryCandidate().Text)
+
+	turns := s.server.Turns(chat.ChatID)
+	s.Require().Len(turns, 6)
+	s.Assert().True(turns[0].Author.IsHuman)
+	s.Assert().Equal("Hello everyone", turns[0].PrimaryCandidate().Text)
+
+	_, err = s.client.GenerateGroupTurn(chat.ChatID, "unknown-character")
+	s.Assert().Error(err, "Only participants can speak")
+}
+
+func TestGroupChatSuite(t *testing.T) {
+	suite.Run(t, new(GroupChatSuite))
+}
@@ -0,0 +1,164 @@
+package cai
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// MiddlewareSuite tests custom HTTP transports and middleware hooks against the fake service
+type MiddlewareSuite struct {
+	FakeSuite
+}
+
+// countingTransport counts the requests passed on to the default transport
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// fetchMe fetches the account through client, failing the test on errors, and closes the client
+func (s *MiddlewareSuite) fetchMe(client *cai.Client) {
+	defer func() {
+		s.Require().NoError(client.Close(), "Failed to close client")
+	}()
+	_, err := client.FetchMe()
+	s.Require().NoError(err, "FetchMe returned an error")
+}
+
+func (s *MiddlewareSuite) TestTransport() {
+	transport := &countingTransport{}
+	s.fetchMe(s.server.NewClient(cai.WithTransport(transport)))
+	s.Assert().EqualValues(1, atomic.LoadInt32(&transport.requests), "Requests should use the transport")
+
+	transport = &countingTransport{}
+	s.fetchMe(s.server.NewClient(cai.WithHTTPClient(&http.Client{Transport: transport})))
+	s.Assert().EqualValues(1, atomic.LoadInt32(&transport.requests), "Requests should use the HTTP client")
+}
+
+func (s *MiddlewareSuite) TestRequestHooks() {
+	// Hooks run on the goroutine making the request
+	var userAgents, traces, order []string
+	tracing := cai.Middleware{
+		BeforeRequest: func(req *http.Request) error {
+			req.Header.Set("X-Trace", "trace-id")
+			order = append(order, "before outer")
+			return nil
+		},
+		AfterResponse: func(req *http.Request, resp *http.Response, err error) (*http.Response, error) {
+			order = append(order, "after outer")
+			return resp, err
+		},
+	}
+	recording := cai.Middleware{
+		BeforeRequest: func(req *http.Request) error {
+			order = append(order, "before inner")
+			return nil
+		},
+		AfterResponse: func(req *http.Request, resp *http.Response, err error) (*http.Response, error) {
+			userAgents = append(userAgents, req.Header.Get("User-Agent"))
+			traces = append(traces, req.Header.Get("X-Trace"))
+			order = append(order, "after inner")
+			return resp, err
+		},
+	}
+
+	s.fetchMe(s.server.NewClient(cai.WithUserAgent("test-agent"), cai.WithMiddleware(tracing), cai.WithMiddleware(recording)))
+	s.Assert().Equal([]string{"test-agent"}, userAgents)
+	s.Assert().Equal([]string{"trace-id"}, traces, "Headers set before the request should be sent")
+	s.Assert().Equal([]string{"before outer", "before inner", "after inner", "after outer"}, order,
+		"The first middleware should be the outermost")
+}
+
+func (s *MiddlewareSuite) TestBeforeRequestAborts() {
+	transport := &countingTransport{}
+	failure := errors.New("signing failed")
+	client := s.server.NewClient(cai.WithTransport(transport), cai.WithMiddleware(cai.Middleware{
+		BeforeRequest: func(req *http.Request) error { return failure },
+	}))
+	defer client.Close()
+
+	_, err := client.FetchMe()
+	s.Assert().ErrorIs(err, failure)
+	s.Assert().EqualValues(0, atomic.LoadInt32(&transport.requests), "The request should neither be sent nor retried")
+}
+
+func (s *MiddlewareSuite) TestAfterResponseRefreshesAuth() {
+	s.server.FailNextRequest("/beta/chat/user/", http.StatusUnauthorized, 0)
+	var refreshed int32
+	client := s.server.NewClient(cai.WithMiddleware(cai.Middleware{
+		AfterResponse: func(req *http.Request, resp *http.Response, err error) (*http.Response, error) {
+			if err != nil || resp.StatusCode != http.StatusUnauthorized {
+				return resp, err
+			}
+			resp.Body.Close()
+			atomic.AddInt32(&refreshed, 1)
+			retry := req.Clone(req.Context())
+			retry.Header.Set("authorization", "Token "+caitest.Token)
+			return http.DefaultTransport.RoundTrip(retry)
+		},
+	}))
+	s.fetchMe(client)
+	s.Assert().EqualValues(1, atomic.LoadInt32(&refreshed))
+}
+
+func (s *MiddlewareSuite) TestFrameHooks() {
+	var mutex sync.Mutex
+	var sent, received [][]byte
+	client := s.server.NewClient(cai.WithMiddleware(cai.Middleware{
+		SendFrame: func(frame []byte) ([]byte, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			sent = append(sent, frame)
+			return frame, nil
+		},
+		ReceiveFrame: func(frame []byte) []byte {
+			mutex.Lock()
+			defer mutex.Unlock()
+			received = append(received, frame)
+			return frame
+		},
+	}))
+	defer func() {
+		s.Require().NoError(client.Close(), "Failed to close client")
+	}()
+
+	chat, _, err := client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err, "CreateChat returned an error")
+	_, err = client.SendMessage(caitest.CharacterID, chat.ChatID, "Hello")
+	s.Require().NoError(err, "SendMessage returned an error")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	s.Require().NotEmpty(sent, "Outgoing frames should pass the middleware")
+	s.Assert().Contains(string(bytes.Join(sent, nil)), "Hello")
+	s.Require().NotEmpty(received, "Incoming frames should pass the middleware")
+	s.Assert().Contains(string(bytes.Join(received, nil)), "You said: Hello")
+}
+
+func (s *MiddlewareSuite) TestSendFrameAborts() {
+	failure := errors.New("frame rejected")
+	client := s.server.NewClient(cai.WithMiddleware(cai.Middleware{
+		SendFrame: func(frame []byte) ([]byte, error) { return nil, failure },
+	}))
+	defer client.Close()
+
+	chat, _, err := client.CreateChat(caitest.CharacterID, false)
+	s.Assert().ErrorIs(err, failure)
+	s.Assert().Nil(chat)
+}
+
+func TestMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareSuite))
+}
@@ -0,0 +1,128 @@
+package cai
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/stretchr/testify/suite"
+)
+
+// NotificationSuite tests the notifications API against the fake service, which can create notifications
+type NotificationSuite struct {
+	FakeSuite
+}
+
+func (s *NotificationSuite) TestFetchAndMarkRead() {
+	s.server.SetPageSize(2)
+	first := s.server.AddNotification(cai.Notification{Type: "follow", Message: "Someone followed you"})
+	s.server.AddNotification(cai.Notification{Type: "comment", Message: "Someone commented"})
+	s.server.AddNotification(cai.Notification{Type: "follow", Message: "Someone else followed you"})
+
+	notifications, nextToken, err := s.client.FetchNotifications(false, "")
+	s.Require().NoError(err, "FetchNotifications returned an error")
+	s.Require().Len(notifications, 2)
+	s.Assert().NotEmpty(nextToken, "There should be another page")
+	s.Assert().Equal("Someone else followed you", notifications[0].Message, "Notifications should be newest first")
+	s.Assert().False(notifications[0].CreateTime.IsZero(), "Creation time should be parsed")
+
+	err = s.client.MarkNotificationsRead(first.NotificationID)
+	s.Require().NoError(err, "MarkNotificationsRead returned an error")
+	unread, err := s.client.FetchAllNotifications(true)
+	s.Require().NoError(err)
+	s.Assert().Len(unread, 2)
+	for _, notification := range unread {
+		s.Assert().NotEqual(first.NotificationID, notification.NotificationID)
+	}
+
+	err = s.client.MarkAllNotificationsRead()
+	s.Require().NoError(err, "MarkAllNotificationsRead returned an error")
+	unread, err = s.client.FetchAllNotifications(true)
+	s.Require().NoError(err)
+	s.Assert().Empty(unread)
+	all, err := s.client.FetchAllNotifications(false)
+	s.Require().NoError(err)
+	s.Assert().Len(all, 3)
+}
+
+func (s *NotificationSuite) TestWatchNotifications() {
+	s.server.SetPageSize(2)
+	s.server.AddNotification(cai.Notification{Type: "follow", Message: "Before the watch"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := s.client.WatchNotifications(ctx, 20*time.Millisecond)
+
+	// Let the first poll record the existing notification
+	time.Sleep(50 * time.Millisecond)
+	for i := 1; i <= 3; i++ {
+		s.server.AddNotification(cai.Notification{Type: "comment", Message: fmt.Sprintf("Comment %d", i)})
+	}
+
+	var messages []string
+	timeout := time.After(2 * time.Second)
+	for len(messages) < 3 {
+		select {
+		case event := <-events:
+			s.Require().NoError(event.Err)
+			messages = append(messages, event.Notification.Message)
+		case <-timeout:
+			s.FailNow("Timed out waiting for notifications", "Received %v", messages)
+		}
+	}
+	s.Assert().Equal([]string{"Comment 1", "Comment 2", "Comment 3"}, messages, "New notifications should arrive oldest first")
+
+	cancel()
+	for range events {
+	}
+}
+
+func (s *NotificationSuite) TestWatchAfterSeenNotificationsAreDeleted() {
+	s.server.SetPageSize(2)
+	for i := 1; i <= 5; i++ {
+		s.server.AddNotification(cai.Notification{Type: "follow", Message: fmt.Sprintf("Old %d", i)})
+	}
+	seen := s.server.Notifications()[3:]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := s.client.WatchNotifications(ctx, 20*time.Millisecond)
+
+	// Let the first poll record the newest page, then delete it
+	time.Sleep(50 * time.Millisecond)
+	for _, notification := range seen {
+		s.server.RemoveNotification(notification.NotificationID)
+	}
+	s.server.AddNotification(cai.Notification{Type: "comment", Message: "New"})
+
+	select {
+	case event := <-events:
+		s.Require().NoError(event.Err)
+		s.Assert().Equal("New", event.Notification.Message)
+	case <-time.After(2 * time.Second):
+		s.FailNow("Timed out waiting for the notification")
+	}
+	select {
+	case event := <-events:
+		s.Failf("Older notifications should not be emitted", "Received %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func (s *NotificationSuite) TestWatchDefaultInterval() {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := s.client.WatchNotifications(ctx, 0)
+	cancel()
+	for range events {
+	}
+}
+
+func TestNotificationSuite(t *testing.T) {
+	suite.Run(t, new(NotificationSuite))
+}
@@ -0,0 +1,35 @@
+//go:build go1.23
+
+package cai
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+func (s *PagerSuite) TestRangeOverFunc() {
+	pager, fetches := countingPager(10, -1)
+	var items []int
+	for item, err := range pager.Prefetch(1).All(context.Background()) {
+		s.Require().NoError(err)
+		items = append(items, item)
+		if item == 2 {
+			break
+		}
+	}
+	s.Assert().Equal([]int{0, 1, 2}, items)
+	s.Assert().LessOrEqual(atomic.LoadInt32(fetches), int32(3), "Breaking should stop fetching")
+
+	pager, _ = countingPager(10, 2)
+	items = nil
+	var failure error
+	for item, err := range pager.All(context.Background()) {
+		if err != nil {
+			failure = err
+			continue
+		}
+		items = append(items, item)
+	}
+	s.Assert().Equal([]int{0, 1}, items)
+	s.Assert().EqualError(failure, "page failed")
+}
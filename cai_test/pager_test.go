@@ -0,0 +1,179 @@
+package cai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// PagerSuite tests paginated listings, both on their own and against the fake service
+type PagerSuite struct {
+	FakeSuite
+}
+
+func (s *PagerSuite) SetupTest() {
+	s.FakeSuite.SetupTest()
+	s.server.SetPageSize(2)
+}
+
+// countingPager returns a pager over pages of two numbers, counting up from 0 to below count, and the number of
+// pages fetched so far. Fetching the page starting at failAt fails.
+func countingPager(count int, failAt int) (*cai.Pager[int], *int32) {
+	var fetches int32
+	pager := cai.NewPager(func(ctx context.Context, token string) ([]int, string, error) {
+		atomic.AddInt32(&fetches, 1)
+		start, _ := strconv.Atoi(token)
+		if start == failAt {
+			return nil, "", errors.New("page failed")
+		}
+		var items []int
+		for i := start; i < count && i < start+2; i++ {
+			items = append(items, i)
+		}
+		if start+2 >= count {
+			return items, "", nil
+		}
+		return items, strconv.Itoa(start + 2), nil
+	})
+	return pager, &fetches
+}
+
+func (s *PagerSuite) TestCollect() {
+	pager, fetches := countingPager(5, -1)
+	items, err := pager.Collect(context.Background())
+	s.Require().NoError(err, "Collect returned an error")
+	s.Assert().Equal([]int{0, 1, 2, 3, 4}, items)
+	s.Assert().EqualValues(3, atomic.LoadInt32(fetches))
+
+	items, err = pager.Prefetch(2).Collect(context.Background())
+	s.Require().NoError(err, "Collect returned an error")
+	s.Assert().Equal([]int{0, 1, 2, 3, 4}, items, "Prefetching should not change the items")
+}
+
+func (s *PagerSuite) TestLazy() {
+	pager, fetches := countingPager(10, -1)
+	it := pager.Iterate(context.Background())
+	defer it.Close()
+	s.Assert().EqualValues(0, atomic.LoadInt32(fetches), "No page should be fetched before iterating")
+	s.Require().True(it.Next())
+	s.Require().True(it.Next())
+	s.Assert().Equal(1, it.Item())
+	s.Assert().EqualValues(1, atomic.LoadInt32(fetches), "Only the first page should be fetched")
+}
+
+func (s *PagerSuite) TestPrefetchIsBounded() {
+	pager, fetches := countingPager(20, -1)
+	it := pager.Prefetch(2).Iterate(context.Background())
+	s.Require().True(it.Next())
+	s.Assert().Eventually(func() bool { return atomic.LoadInt32(fetches) == 3 }, time.Second, time.Millisecond,
+		"Two pages should be fetched ahead")
+	s.Assert().Never(func() bool { return atomic.LoadInt32(fetches) > 3 }, 50*time.Millisecond, time.Millisecond,
+		"No more pages should be fetched ahead")
+
+	it.Close()
+	s.Assert().False(it.Next(), "A closed iterator should end")
+	s.Assert().NoError(it.Err())
+}
+
+func (s *PagerSuite) TestError() {
+	for _, prefetch := range []int{0, 1} {
+		pager, _ := countingPager(10, 4)
+		it := pager.Prefetch(prefetch).Iterate(context.Background())
+		var items []int
+		for it.Next() {
+			items = append(items, it.Item())
+		}
+		s.Assert().Equal([]int{0, 1, 2, 3}, items, "Items before the error should be returned")
+		s.Assert().EqualError(it.Err(), "page failed")
+
+		_, err := pager.Collect(context.Background())
+		s.Assert().Error(err)
+	}
+}
+
+func (s *PagerSuite) TestCancel() {
+	pager, _ := countingPager(10, -1)
+	ctx, cancel := context.WithCancel(context.Background())
+	it := pager.Iterate(ctx)
+	s.Require().True(it.Next())
+	s.Require().True(it.Next())
+	cancel()
+	s.Assert().False(it.Next())
+	s.Assert().ErrorIs(it.Err(), context.Canceled)
+}
+
+func (s *PagerSuite) TestMessagesAreFetchedLazily() {
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, true)
+	s.Require().NoError(err, "CreateChat returned an error")
+	for _, text := range []string{"One", "Two"} {
+		_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, text)
+		s.Require().NoError(err, "SendMessage returned an error")
+	}
+	turns := s.server.Turns(chat.ChatID)
+
+	it := s.client.MessagesPager(chat.ChatID, false).Iterate(context.Background())
+	defer it.Close()
+	for i := 0; i < 3; i++ {
+		s.Require().True(it.Next())
+		s.Assert().Equal(turns[len(turns)-1-i].TurnKey.TurnID, it.Item().TurnKey.TurnID, "Turns should be newest first")
+	}
+	s.Assert().Equal(2, s.server.Requests("/neo/turns/"+chat.ChatID+"/"), "Older pages should not be fetched")
+}
+
+func (s *PagerSuite) TestSearches() {
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("Paged %d", i)
+		s.server.AddCharacter(cai.Character{Name: name, Visibility: "PUBLIC", AuthorUsername: fmt.Sprintf("paged_author_%d", i)})
+		s.server.AddVoice(cai.Voice{Name: name})
+	}
+
+	characters, err := s.client.SearchCharacters("paged")
+	s.Require().NoError(err, "SearchCharacters returned an error")
+	s.Assert().Len(characters, 2, "SearchCharacters should return the first page")
+	characters, err = s.client.SearchCharactersPager("paged").Collect(context.Background())
+	s.Require().NoError(err, "Collect returned an error")
+	s.Assert().Len(characters, 5)
+
+	creators, err := s.client.SearchCreatorsPager("paged_author").Collect(context.Background())
+	s.Require().NoError(err, "Collect returned an error")
+	s.Assert().Len(creators, 5)
+
+	voices, err := s.client.SearchVoicesPager("paged").Prefetch(1).Collect(context.Background())
+	s.Require().NoError(err, "Collect returned an error")
+	s.Assert().Len(voices, 5)
+	voices, err = s.client.FetchUserVoices(caitest.Username)
+	s.Require().NoError(err, "FetchUserVoices returned an error")
+	s.Assert().Len(voices, 5, "All pages of user voices should be fetched")
+}
+
+func (s *PagerSuite) TestHistories() {
+	var chatIDs []string
+	for i := 0; i < 3; i++ {
+		chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+		s.Require().NoError(err, "CreateChat returned an error")
+		chatIDs = append(chatIDs, chat.ChatID)
+	}
+
+	histories, err := s.client.HistoriesPager(caitest.CharacterID, 2).Collect(context.Background())
+	s.Require().NoError(err, "Collect returned an error")
+	s.Require().Len(histories, 3)
+	seen := map[string]bool{}
+	for _, history := range histories {
+		seen[history.ChatID] = true
+	}
+	for _, chatID := range chatIDs {
+		s.Assert().True(seen[chatID], "History of chat %s should be listed", chatID)
+	}
+}
+
+func TestPagerSuite(t *testing.T) {
+	suite.Run(t, new(PagerSuite))
+}
@@ -0,0 +1,237 @@
+package cai
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	proxyUser     = "proxy-user"
+	proxyPassword = "proxy-password"
+)
+
+// ProxySuite tests that HTTP and WebSocket traffic is routed through the configured proxy
+type ProxySuite struct {
+	suite.Suite
+	server *caitest.Server
+}
+
+func (s *ProxySuite) SetupTest() {
+	s.server = caitest.NewServer()
+}
+
+func (s *ProxySuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ProxySuite) TestInvalidProxy() {
+	for _, proxy := range []string{"://missing-scheme", "ftp://localhost:21", "socks5://"} {
+		_, err := cai.NewClient(caitest.Token, "", proxy)
+		s.Assert().Error(err, "NewClient should reject proxy %q", proxy)
+	}
+}
+
+func (s *ProxySuite) TestHTTPProxy() {
+	listener, connections := s.listen(serveHTTPProxy)
+	defer listener.Close()
+
+	s.chatThroughProxy("http://" + proxyUser + ":" + proxyPassword + "@" + listener.Addr().String())
+	s.Assert().GreaterOrEqual(atomic.LoadInt32(connections), int32(2), "HTTP and WebSocket traffic should use the proxy")
+}
+
+func (s *ProxySuite) TestSOCKS5Proxy() {
+	listener, connections := s.listen(serveSOCKS5Proxy)
+	defer listener.Close()
+
+	s.chatThroughProxy("socks5://" + proxyUser + ":" + proxyPassword + "@" + listener.Addr().String())
+	s.Assert().GreaterOrEqual(atomic.LoadInt32(connections), int32(2), "HTTP and WebSocket traffic should use the proxy")
+}
+
+// chatThroughProxy performs REST and WebSocket calls against the fake using the given proxy
+func (s *ProxySuite) chatThroughProxy(proxy string) {
+	client, err := cai.NewClient(caitest.Token, "", proxy, cai.WithEndpoints(s.server.Endpoints()))
+	s.Require().NoError(err)
+	defer client.Close()
+
+	s.Require().NoError(client.Authenticate())
+	chat, _, err := client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err)
+	turn, err := client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+	s.Require().NoError(err)
+	s.Assert().Equal("You said: Hi", turn.PrimaryCandidate().Text)
+}
+
+// listen starts a proxy serving each connection with serve, counting the connections it tunnels
+func (s *ProxySuite) listen(serve func(conn net.Conn, established func())) (net.Listener, *int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	var connections int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn, func() {
+					atomic.AddInt32(&connections, 1)
+				})
+			}()
+		}
+	}()
+	return listener, &connections
+}
+
+// serveHTTPProxy handles a connection of an HTTP proxy requiring basic authentication.
+// CONNECT requests are tunneled, other requests are forwarded.
+func serveHTTPProxy(conn net.Conn, established func()) {
+	reader := bufio.NewReader(conn)
+	request, err := http.ReadRequest(reader)
+	if err != nil {
+		return
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(proxyUser + ":" + proxyPassword))
+	if request.Header.Get("Proxy-Authorization") != "Basic "+credentials {
+		_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return
+	}
+
+	target, err := net.Dial("tcp", request.Host)
+	if err != nil {
+		_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer target.Close()
+
+	if request.Method == http.MethodConnect {
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	} else {
+		request.Header.Del("Proxy-Authorization")
+		if request.Write(target) != nil {
+			return
+		}
+	}
+	established()
+	tunnel(conn, reader, target)
+}
+
+// serveSOCKS5Proxy handles a connection of a SOCKS5 proxy requiring username and password authentication
+func serveSOCKS5Proxy(conn net.Conn, established func()) {
+	reader := bufio.NewReader(conn)
+
+	// Greeting: version, method count, methods
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil || header[0] != 5 {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(reader, methods); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{5, 2}); err != nil {
+		return
+	}
+
+	// Username and password authentication
+	if _, err := io.ReadFull(reader, header[:1]); err != nil || header[0] != 1 {
+		return
+	}
+	user, err := readSOCKSString(reader)
+	if err != nil {
+		return
+	}
+	password, err := readSOCKSString(reader)
+	if err != nil {
+		return
+	}
+	if user != proxyUser || password != proxyPassword {
+		_, _ = conn.Write([]byte{1, 1})
+		return
+	}
+	if _, err := conn.Write([]byte{1, 0}); err != nil {
+		return
+	}
+
+	// Connect request: version, command, reserved, address type, address, port
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(reader, request); err != nil || request[1] != 1 {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, net.IPv4len)
+		if _, err := io.ReadFull(reader, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	case 3:
+		host, err = readSOCKSString(reader)
+		if err != nil {
+			return
+		}
+	default:
+		return
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(reader, port); err != nil {
+		return
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+	established()
+	tunnel(conn, reader, target)
+}
+
+// readSOCKSString reads a length-prefixed string of the SOCKS5 protocol
+func readSOCKSString(reader *bufio.Reader) (string, error) {
+	length, err := reader.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// tunnel copies data between the client connection and the target until either side closes
+func tunnel(conn net.Conn, reader *bufio.Reader, target net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(target, reader)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, target)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func TestProxySuite(t *testing.T) {
+	suite.Run(t, new(ProxySuite))
+}
@@ -0,0 +1,168 @@
+package cai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// ReconnectSuite tests redialing dropped WebSocket connections against the fake service
+type ReconnectSuite struct {
+	FakeSuite
+	events chan cai.ReconnectEvent
+}
+
+func (s *ReconnectSuite) SetupTest() {
+	s.FakeSuite.SetupTest()
+	s.events = make(chan cai.ReconnectEvent, 100)
+	s.client.Requester.OnReconnect(func(event cai.ReconnectEvent) {
+		s.events <- event
+	})
+	s.client.Requester.SetReconnectPolicy(cai.ReconnectPolicy{
+		Enabled:        true,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		MaxAttempts:    5,
+	})
+}
+
+// awaitEvent returns the next reconnect event, failing the test if none arrives in time
+func (s *ReconnectSuite) awaitEvent() cai.ReconnectEvent {
+	select {
+	case event := <-s.events:
+		return event
+	case <-time.After(5 * time.Second):
+		s.FailNow("No reconnect event received")
+		return cai.ReconnectEvent{}
+	}
+}
+
+// awaitReconnected collects the events up to Reconnected, failing the test if reconnecting is abandoned
+func (s *ReconnectSuite) awaitReconnected() []cai.ReconnectEvent {
+	var events []cai.ReconnectEvent
+	for {
+		event := s.awaitEvent()
+		events = append(events, event)
+		switch event.Type {
+		case cai.Reconnected:
+			return events
+		case cai.ReconnectAbandoned:
+			s.FailNow("Reconnecting was abandoned")
+		}
+	}
+}
+
+// sendMessage checks that a message round trip works over the current connection
+func (s *ReconnectSuite) sendMessage() {
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err, "CreateChat returned an error")
+	_, err = s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hello")
+	s.Require().NoError(err, "SendMessage returned an error")
+}
+
+func (s *ReconnectSuite) TestReconnectAfterDrop() {
+	s.Require().NoError(s.client.Requester.InitializeWebSocket(), "InitializeWebSocket returned an error")
+	s.server.DropWebSockets()
+
+	events := s.awaitReconnected()
+	s.Require().Equal(cai.Disconnected, events[0].Type)
+	s.Assert().Error(events[0].Err, "Disconnected should carry the cause")
+	s.Assert().Equal([]cai.ReconnectEvent{
+		{Type: cai.Reconnecting, Attempt: 1, Delay: 10 * time.Millisecond},
+		{Type: cai.Reconnected, Attempt: 1},
+	}, events[1:])
+
+	s.sendMessage()
+}
+
+func (s *ReconnectSuite) TestBackoffGrows() {
+	s.Require().NoError(s.client.Requester.InitializeWebSocket(), "InitializeWebSocket returned an error")
+	s.server.FailNextWebSocketDials(2)
+	s.server.DropWebSockets()
+
+	var delays []time.Duration
+	var failed []int
+	for _, event := range s.awaitReconnected() {
+		switch event.Type {
+		case cai.Reconnecting:
+			delays = append(delays, event.Delay)
+		case cai.ReconnectFailed:
+			s.Assert().Error(event.Err, "ReconnectFailed should carry the cause")
+			failed = append(failed, event.Attempt)
+		case cai.Reconnected:
+			s.Assert().Equal(3, event.Attempt)
+		}
+	}
+	s.Assert().Equal([]time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond}, delays)
+	s.Assert().Equal([]int{1, 2}, failed)
+
+	s.sendMessage()
+}
+
+func (s *ReconnectSuite) TestReconnectAbandoned() {
+	s.Require().NoError(s.client.Requester.InitializeWebSocket(), "InitializeWebSocket returned an error")
+	s.server.FailNextWebSocketDials(5)
+	s.server.DropWebSockets()
+
+	for {
+		event := s.awaitEvent()
+		s.Require().NotEqual(cai.Reconnected, event.Type, "All redials should fail")
+		if event.Type == cai.ReconnectAbandoned {
+			break
+		}
+	}
+
+	// The next call dials on demand
+	s.sendMessage()
+}
+
+func (s *ReconnectSuite) TestKeepAliveDetectsDeadConnection() {
+	s.client.Requester.SetReconnectPolicy(cai.ReconnectPolicy{
+		Enabled:        true,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		MaxAttempts:    5,
+		PingInterval:   20 * time.Millisecond,
+	})
+	s.Require().NoError(s.client.Requester.InitializeWebSocket(), "InitializeWebSocket returned an error")
+
+	// Answered pings keep the connection alive past its read deadline
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case event := <-s.events:
+		s.FailNow("Unexpected reconnect event", "%v", event.Type)
+	default:
+	}
+
+	s.server.SetIgnorePings(true)
+	s.Require().Equal(cai.Disconnected, s.awaitEvent().Type, "Unanswered pings should drop the connection")
+
+	s.server.SetIgnorePings(false)
+	s.awaitReconnected()
+	s.sendMessage()
+}
+
+func (s *ReconnectSuite) TestReconnectAfterReinitialize() {
+	s.Require().NoError(s.client.Requester.InitializeWebSocket(), "InitializeWebSocket returned an error")
+	s.Require().NoError(s.client.Requester.CloseWebSocket(), "CloseWebSocket returned an error")
+
+	// Closing is not a drop
+	time.Sleep(50 * time.Millisecond)
+	s.Require().Empty(s.events, "Closing should not report reconnect events")
+
+	s.Require().NoError(s.client.Requester.InitializeWebSocket(), "InitializeWebSocket returned an error")
+	s.server.DropWebSockets()
+
+	events := s.awaitReconnected()
+	s.Assert().Equal(cai.Disconnected, events[0].Type)
+	s.sendMessage()
+}
+
+func TestReconnectSuite(t *testing.T) {
+	suite.Run(t, new(ReconnectSuite))
+}
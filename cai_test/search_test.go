@@ -0,0 +1,125 @@
+package cai
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// SearchSuite tests the search of cached chats against the fake service
+type SearchSuite struct {
+	FakeSuite
+	other    *cai.Character
+	chat     *cai.Chat
+	question *cai.Turn
+	answer   *cai.Turn
+}
+
+func (s *SearchSuite) SetupTest() {
+	s.clientOptions = []cai.ClientOption{cai.WithChatStore(cai.NewMemoryChatStore())}
+	s.FakeSuite.SetupTest()
+	s.other = s.server.AddCharacter(cai.Character{Name: "Other Character", Greeting: "Hi."})
+	s.server.SetReply(caitest.CharacterID, caitest.StaticReply("The treasure is buried under the old oak tree."))
+	s.server.SetReply(s.other.ExternalID, caitest.StaticReply("Nothing about gold here."))
+
+	var err error
+	s.chat, _, err = s.client.CreateChat(caitest.CharacterID, false)
+	s.Require().NoError(err, "CreateChat returned an error")
+	s.answer, err = s.client.SendMessage(caitest.CharacterID, s.chat.ChatID, "Where is the treasure?")
+	s.Require().NoError(err, "SendMessage returned an error")
+	turns := s.server.Turns(s.chat.ChatID)
+	s.question = &turns[0]
+
+	chat, _, err := s.client.CreateChat(s.other.ExternalID, false)
+	s.Require().NoError(err, "CreateChat returned an error")
+	_, err = s.client.SendMessage(s.other.ExternalID, chat.ChatID, "Tell me about the treasure")
+	s.Require().NoError(err, "SendMessage returned an error")
+
+	chats, err := s.client.SyncAllChats()
+	s.Require().NoError(err, "SyncAllChats returned an error")
+	s.Require().Len(chats, 2)
+}
+
+// search runs a query, failing the test on errors
+func (s *SearchSuite) search(query cai.SearchQuery) []cai.SearchHit {
+	hits, err := s.client.SearchCachedMessages(query)
+	s.Require().NoError(err, "SearchCachedMessages returned an error")
+	return hits
+}
+
+func (s *SearchSuite) TestWords() {
+	hits := s.search(cai.SearchQuery{Text: "TREASURE"})
+	s.Require().Len(hits, 3, "Both questions and one answer mention the treasure")
+	for i := 1; i < len(hits); i++ {
+		s.Assert().False(hits[i].CreateTime.After(hits[i-1].CreateTime), "Hits should be ordered newest first")
+	}
+
+	s.Assert().Len(s.search(cai.SearchQuery{Text: "treasure oak"}), 1, "All words must occur")
+	s.Assert().Empty(s.search(cai.SearchQuery{Text: "treas"}), "Words should match whole words only")
+	s.Assert().Len(s.search(cai.SearchQuery{Text: "treasure", Limit: 2}), 2)
+}
+
+func (s *SearchSuite) TestPhrases() {
+	hits := s.search(cai.SearchQuery{Text: `"old oak" buried`})
+	s.Require().Len(hits, 1)
+	hit := hits[0]
+	s.Assert().Equal(s.answer.TurnKey, hit.TurnKey)
+	s.Assert().Equal(s.answer.PrimaryCandidateID, hit.CandidateID)
+	s.Assert().Equal(caitest.CharacterID, hit.CharacterID)
+	s.Assert().True(hit.IsPrimary)
+	s.Assert().False(hit.IsHuman)
+	s.Assert().Equal("The treasure is buried under the old oak tree.", hit.Snippet)
+	s.Assert().Equal("The treasure is <b>buried</b> under the <b>old oak</b> tree.", hit.HighlightedSnippet("<b>", "</b>"))
+
+	s.Assert().Empty(s.search(cai.SearchQuery{Text: `"oak old"`}), "Phrase words must occur in order")
+}
+
+func (s *SearchSuite) TestFilters() {
+	hits := s.search(cai.SearchQuery{Text: "treasure", Author: cai.SearchCharacterAuthor})
+	s.Require().Len(hits, 1)
+	s.Assert().Equal(s.answer.TurnKey, hits[0].TurnKey)
+
+	hits = s.search(cai.SearchQuery{Text: "treasure", Author: cai.SearchHumanAuthor, CharacterIDs: []string{s.other.ExternalID}})
+	s.Require().Len(hits, 1)
+	s.Assert().Equal("Tell me about the treasure", hits[0].Snippet)
+
+	s.Assert().Empty(s.search(cai.SearchQuery{Text: "treasure", PinnedOnly: true}))
+	s.Require().NoError(s.client.PinMessage(s.chat.ChatID, s.question.TurnKey.TurnID))
+	hits = s.search(cai.SearchQuery{Text: "treasure", PinnedOnly: true})
+	s.Require().Len(hits, 1, "Pinning should be recorded in the cache")
+	s.Assert().Equal(s.question.TurnKey.TurnID, hits[0].TurnKey.TurnID)
+
+	s.Assert().Len(s.search(cai.SearchQuery{Before: time.Now().Add(time.Minute)}), 4)
+	s.Assert().Empty(s.search(cai.SearchQuery{After: time.Now().Add(time.Minute)}))
+}
+
+func (s *SearchSuite) TestSnippets() {
+	text := strings.Repeat("filler words go here ", 10) + "the needle\nis somewhere " + strings.Repeat("more padding text ", 20)
+	chat := &cai.Chat{ChatID: "chat", CharacterID: caitest.CharacterID}
+	turn := &cai.Turn{
+		TurnKey:            cai.TurnKey{ChatID: "chat", TurnID: "turn"},
+		Author:             cai.AuthorInfo{AuthorID: caitest.CharacterID},
+		CandidatesList:     []cai.TurnCandidate{{CandidateID: "candidate", Text: text}},
+		PrimaryCandidateID: "candidate",
+	}
+	turn.Candidates = map[string]*cai.TurnCandidate{"candidate": &turn.CandidatesList[0]}
+
+	hits := cai.SearchTurns(chat, []*cai.Turn{turn}, cai.SearchQuery{Text: `"needle is"`})
+	s.Require().Len(hits, 1)
+	snippet := hits[0].Snippet
+	s.Assert().True(strings.HasPrefix(snippet, "…filler") || strings.HasPrefix(snippet, "…words") ||
+		strings.HasPrefix(snippet, "…go") || strings.HasPrefix(snippet, "…here"), "Snippet should start at a word: %q", snippet)
+	s.Assert().True(strings.HasSuffix(snippet, "…"), "Snippet should be cut at the end: %q", snippet)
+	s.Assert().NotContains(snippet, "\n")
+	s.Require().Len(hits[0].Highlights, 1)
+	highlight := hits[0].Highlights[0]
+	s.Assert().Equal("needle is", snippet[highlight.Start:highlight.End])
+}
+
+func TestSearchSuite(t *testing.T) {
+	suite.Run(t, new(SearchSuite))
+}
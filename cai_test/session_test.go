@@ -0,0 +1,128 @@
+package cai
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// ChatSessionSuite tests ChatSession against the fake service
+type ChatSessionSuite struct {
+	FakeSuite
+}
+
+// texts returns the primary texts of turns
+func texts(turns []*cai.Turn) []string {
+	result := make([]string, len(turns))
+	for i, turn := range turns {
+		result[i] = turn.PrimaryCandidate().Text
+	}
+	return result
+}
+
+func (s *ChatSessionSuite) TestSendAndRefresh() {
+	session, err := s.client.CreateChatSession(caitest.CharacterID, true)
+	s.Require().NoError(err, "CreateChatSession returned an error")
+
+	reply, err := session.Send("Hi")
+	s.Require().NoError(err, "Send returned an error")
+	s.Assert().Equal("You said: Hi", reply.PrimaryCandidate().Text)
+	expected := []string{"Hello! I am a test character.", "Hi", "You said: Hi"}
+	s.Assert().Equal(expected, texts(session.Turns()))
+	s.Assert().True(session.Turns()[1].Author.IsHuman)
+
+	fetched, err := s.client.FetchChatSession(session.Chat.ChatID)
+	s.Require().NoError(err, "FetchChatSession returned an error")
+	s.Assert().Equal(expected, texts(fetched.Turns()), "Fetched turns should be chronological")
+}
+
+func (s *ChatSessionSuite) TestRegenerateAndSwipe() {
+	session, err := s.client.CreateChatSession(caitest.CharacterID, false)
+	s.Require().NoError(err)
+	answers := []string{"First answer", "Second answer", "Third answer"}
+	count := 0
+	s.server.SetReply(caitest.CharacterID, func(string, string) caitest.Reply {
+		count++
+		return caitest.Reply{Chunks: caitest.Words(answers[(count-1)%len(answers)])}
+	})
+
+	_, err = session.Send("Question")
+	s.Require().NoError(err)
+	turn, err := session.Regenerate()
+	s.Require().NoError(err, "Regenerate returned an error")
+	s.Assert().Equal("Second answer", turn.PrimaryCandidate().Text, "Regenerate should wait for the complete candidate")
+	s.Assert().Len(turn.CandidatesList, 2)
+
+	turn, err = session.Swipe(-1)
+	s.Require().NoError(err, "Swipe returned an error")
+	s.Assert().Equal("First answer", turn.PrimaryCandidate().Text)
+	s.Assert().Equal(turn.PrimaryCandidateID, s.server.Turns(session.Chat.ChatID)[1].PrimaryCandidateID)
+
+	_, err = session.Swipe(-1)
+	s.Assert().Error(err, "There is no candidate before the first")
+
+	turn, err = session.Swipe(1)
+	s.Require().NoError(err)
+	s.Assert().Equal("Second answer", turn.PrimaryCandidate().Text)
+
+	// Swiping past the newest candidate generates another
+	turn, err = session.Swipe(1)
+	s.Require().NoError(err)
+	s.Assert().Equal("Third answer", turn.PrimaryCandidate().Text)
+	s.Assert().Len(turn.CandidatesList, 3)
+	s.Assert().Equal("Third answer", session.LastTurn().PrimaryCandidate().Text)
+}
+
+func (s *ChatSessionSuite) TestSendWithUserTurnUpdate() {
+	s.server.SetUserTurnUpdates(true)
+	session, err := s.client.CreateChatSession(caitest.CharacterID, false)
+	s.Require().NoError(err)
+
+	_, err = session.Send("Hello")
+	s.Require().NoError(err, "Send returned an error")
+	turns := session.Turns()
+	s.Require().Len(turns, 2, "The updated user turn should replace the added one")
+	s.Assert().Equal("Hello", turns[0].PrimaryCandidate().Text)
+	s.Assert().Equal("You said: Hello", turns[1].PrimaryCandidate().Text)
+}
+
+func (s *ChatSessionSuite) TestEditLastRewindAndBranch() {
+	session, err := s.client.CreateChatSession(caitest.CharacterID, true)
+	s.Require().NoError(err)
+	_, err = session.Send("One")
+	s.Require().NoError(err)
+	_, err = session.Send("Two")
+	s.Require().NoError(err)
+
+	turn, err := session.EditLast("Edited reply")
+	s.Require().NoError(err, "EditLast returned an error")
+	s.Assert().Equal("Edited reply", turn.PrimaryCandidate().Text)
+	s.Assert().Equal("Edited reply", session.LastTurn().PrimaryCandidate().Text)
+
+	branch, err := session.Branch("")
+	s.Require().NoError(err, "Branch returned an error")
+	s.Assert().NotEqual(session.Chat.ChatID, branch.Chat.ChatID)
+	s.Assert().Equal(texts(session.Turns()), texts(branch.Turns()))
+
+	greeting := session.Turns()[0]
+	err = session.Rewind(greeting.TurnKey.TurnID)
+	s.Require().NoError(err, "Rewind returned an error")
+	s.Assert().Len(session.Turns(), 1)
+	s.Assert().Len(s.server.Turns(session.Chat.ChatID), 1, "Later turns should be deleted on the server")
+	s.Assert().Len(branch.Turns(), 5, "The branch should not be affected")
+
+	err = session.Rewind("unknown-turn")
+	s.Assert().True(errors.Is(err, cai.ErrNotFound))
+
+	// Branching at an earlier turn
+	earlier, err := branch.Branch(branch.Turns()[2].TurnKey.TurnID)
+	s.Require().NoError(err)
+	s.Assert().Equal([]string{"Hello! I am a test character.", "One", "You said: One"}, texts(earlier.Turns()))
+}
+
+func TestChatSessionSuite(t *testing.T) {
+	suite.Run(t, new(ChatSessionSuite))
+}
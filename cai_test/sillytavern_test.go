@@ -0,0 +1,143 @@
+package cai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/caitest"
+	"github.com/stretchr/testify/suite"
+)
+
+// sillyTavernChat is a chat as written by SillyTavern, with a system message and humanized dates
+const sillyTavernChat = `{"user_name":"You","character_name":"Seraphina","create_date":"2024-03-05@14h30m12s","chat_metadata":{}}
+{"name":"Seraphina","is_user":false,"is_system":false,"send_date":"March 5, 2024 2:30pm","mes":"Welcome, traveler.","extra":{}}
+{"name":"You","is_user":true,"is_system":false,"send_date":1709649060000,"mes":"Where am I?","extra":{}}
+{"name":"System","is_user":false,"is_system":true,"send_date":"March 5, 2024 2:31pm","mes":"Hidden note","extra":{}}
+{"name":"Seraphina","is_user":false,"is_system":false,"send_date":"2024-03-05T14:32:00Z","mes":"In the forest.","swipes":["In my home.","In the forest."],"swipe_id":1,"swipe_info":[{"send_date":"2024-03-05T14:31:30Z","extra":{}},{"send_date":"2024-03-05T14:32:00Z","extra":{}}],"extra":{}}
+`
+
+// SillyTavernSuite tests converting chats from and to the SillyTavern format
+type SillyTavernSuite struct {
+	FakeSuite
+}
+
+func (s *SillyTavernSuite) TestRead() {
+	header, turns, err := cai.ReadSillyTavernChat(strings.NewReader(sillyTavernChat))
+	s.Require().NoError(err, "ReadSillyTavernChat returned an error")
+	s.Require().NotNil(header)
+	s.Assert().Equal("Seraphina", header.CharacterName)
+
+	s.Require().Len(turns, 3, "The system message should be skipped")
+	s.Assert().Equal(time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC), turns[0].CreateTime)
+	s.Assert().False(turns[0].Author.IsHuman)
+	s.Assert().Equal("Where am I?", turns[1].PrimaryCandidate().Text)
+	s.Assert().True(turns[1].Author.IsHuman)
+	s.Assert().Equal(time.UnixMilli(1709649060000).UTC(), turns[1].CreateTime)
+
+	s.Require().Len(turns[2].CandidatesList, 2)
+	s.Assert().Equal("In the forest.", turns[2].PrimaryCandidate().Text)
+	s.Assert().Equal("In my home.", turns[2].Alternatives()[0].Text)
+
+	// TavernAI chats may lack the header
+	header, turns, err = cai.ReadSillyTavernChat(strings.NewReader(`{"name":"You","is_user":true,"send_date":"","mes":"Hi"}`))
+	s.Require().NoError(err)
+	s.Assert().Nil(header)
+	s.Require().Len(turns, 1)
+	s.Assert().True(turns[0].CreateTime.IsZero())
+
+	_, _, err = cai.ReadSillyTavernChat(strings.NewReader("{\"mes\":\"Hi\"}\n{broken"))
+	s.Assert().Error(err)
+}
+
+func (s *SillyTavernSuite) TestWriteRoundTrip() {
+	chat, _, err := s.client.CreateChat(caitest.CharacterID, true)
+	s.Require().NoError(err)
+	s.server.SetReply(caitest.CharacterID, caitest.StaticReply("First answer"))
+	reply, err := s.client.SendMessage(caitest.CharacterID, chat.ChatID, "Hello")
+	s.Require().NoError(err)
+	s.server.SetReply(caitest.CharacterID, caitest.StaticReply("Second answer"))
+	_, err = s.client.AnotherResponse(caitest.CharacterID, chat.ChatID, reply.TurnKey.TurnID)
+	s.Require().NoError(err)
+	s.Require().NoError(s.client.UpdatePrimaryCandidate(chat.ChatID, reply.TurnKey.TurnID, reply.PrimaryCandidateID))
+
+	turns, err := s.client.FetchAllMessages(chat.ChatID, false)
+	s.Require().NoError(err)
+	for i, j := 0, len(turns)-1; i < j; i, j = i+1, j-1 {
+		turns[i], turns[j] = turns[j], turns[i]
+	}
+
+	var buffer bytes.Buffer
+	s.Require().NoError(cai.WriteSillyTavernChat(&buffer, turns, "Me", "Test Character"), "WriteSillyTavernChat returned an error")
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	s.Require().Len(lines, 4)
+	var header cai.SillyTavernHeader
+	s.Require().NoError(json.Unmarshal([]byte(lines[0]), &header))
+	s.Assert().Equal("Me", header.UserName)
+	var message cai.SillyTavernMessage
+	s.Require().NoError(json.Unmarshal([]byte(lines[3]), &message))
+	s.Assert().Equal([]string{"First answer", "Second answer"}, message.Swipes)
+	s.Assert().Equal(0, message.SwipeID)
+	s.Assert().Equal("First answer", message.Mes)
+
+	_, read, err := cai.ReadSillyTavernChat(&buffer)
+	s.Require().NoError(err)
+	s.Require().Len(read, 3)
+	for i, turn := range read {
+		s.Assert().Equal(turns[i].PrimaryCandidate().Text, turn.PrimaryCandidate().Text)
+		s.Assert().True(turns[i].CreateTime.Equal(turn.CreateTime), "Timestamps should be preserved")
+		s.Assert().Len(turn.CandidatesList, len(turns[i].CandidatesList))
+	}
+}
+
+func (s *SillyTavernSuite) TestImport() {
+	chat, err := s.client.ImportSillyTavernChat(caitest.CharacterID, strings.NewReader(sillyTavernChat))
+	s.Require().NoError(err, "ImportSillyTavernChat returned an error")
+
+	turns := s.server.Turns(chat.ChatID)
+	s.Require().Len(turns, 3)
+	s.Assert().Equal("Welcome, traveler.", turns[0].PrimaryCandidate().Text)
+	s.Assert().Equal(caitest.CharacterID, turns[0].Author.AuthorID)
+	s.Assert().Equal("Where am I?", turns[1].PrimaryCandidate().Text)
+	s.Assert().True(turns[1].Author.IsHuman)
+	s.Assert().Equal("In the forest.", turns[2].PrimaryCandidate().Text)
+	s.Assert().Equal(caitest.CharacterID, turns[2].Author.AuthorID)
+	s.Assert().False(turns[2].Author.IsHuman)
+	_, generated := s.server.LastGenerateRequest(chat.ChatID)
+	s.Assert().False(generated, "Character messages should be added without generating")
+}
+
+func (s *SillyTavernSuite) TestImportFailure() {
+	// The second message of the import is rejected
+	failure := errors.New("frame rejected")
+	sent := 0
+	client := s.server.NewClient(cai.WithMiddleware(cai.Middleware{
+		SendFrame: func(frame []byte) ([]byte, error) {
+			if bytes.Contains(frame, []byte(`"create_turn"`)) {
+				sent++
+				if sent == 2 {
+					return nil, failure
+				}
+			}
+			return frame, nil
+		},
+	}))
+	defer func() {
+		s.Require().NoError(client.Close(), "Failed to close client")
+	}()
+
+	chat, err := client.ImportSillyTavernChat(caitest.CharacterID, strings.NewReader(sillyTavernChat))
+	s.Assert().ErrorIs(err, failure)
+	s.Require().NotNil(chat, "The partially imported chat should be returned")
+	turns := s.server.Turns(chat.ChatID)
+	s.Require().Len(turns, 1)
+	s.Assert().Equal("Welcome, traveler.", turns[0].PrimaryCandidate().Text)
+}
+
+func TestSillyTavernSuite(t *testing.T) {
+	suite.Run(t, new(SillyTavernSuite))
+}
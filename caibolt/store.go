@@ -0,0 +1,365 @@
+// Package caibolt persists the chat cache of a cai.Client in a bbolt database file.
+//
+// Usage:
+//
+//	store, err := caibolt.Open("chats.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//	client, err := cai.NewClient(token, "", "", cai.WithChatStore(store))
+package caibolt
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Keys of the database. The chats bucket holds a bucket per chat ID, which holds the chat, the order of its turns,
+// the sync time, the newest synced turn ID and a bucket of turns by turn ID.
+var (
+	chatsBucket = []byte("chats")
+	turnsBucket = []byte("turns")
+	chatKey     = []byte("chat")
+	orderKey    = []byte("order")
+	syncTimeKey = []byte("sync_time")
+	syncedKey   = []byte("synced_turn")
+)
+
+// Store is a cai.ChatStore backed by a bbolt database file.
+type Store struct {
+	db *bolt.DB
+}
+
+var _ cai.ChatStore = (*Store)(nil)
+
+// Open opens the database file at path, creating it if needed.
+// The file is locked while it is open, so it cannot be shared between processes.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(chatsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Chat returns a cached chat, or cai.ErrNotCached
+func (s *Store) Chat(chatID string) (*cai.Chat, error) {
+	var chat *cai.Chat
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := chatBucket(tx, chatID)
+		if bucket == nil {
+			return cai.ErrNotCached
+		}
+		var err error
+		chat, err = readChat(bucket)
+		return err
+	})
+	return chat, err
+}
+
+// Chats returns all cached chats, most recently created first
+func (s *Store) Chats() ([]*cai.Chat, error) {
+	var chats []*cai.Chat
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(chatsBucket).ForEachBucket(func(chatID []byte) error {
+			chat, err := readChat(tx.Bucket(chatsBucket).Bucket(chatID))
+			if err == cai.ErrNotCached {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			chats = append(chats, chat)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	cai.SortChats(chats)
+	return chats, nil
+}
+
+// PutChat adds or replaces a chat
+func (s *Store) PutChat(chat *cai.Chat) error {
+	data, err := json.Marshal(chat)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createChatBucket(tx, chat.ChatID)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(chatKey, data)
+	})
+}
+
+// Turn returns a cached turn, or cai.ErrNotCached
+func (s *Store) Turn(chatID, turnID string) (*cai.Turn, error) {
+	var turn *cai.Turn
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := chatBucket(tx, chatID)
+		if bucket == nil {
+			return cai.ErrNotCached
+		}
+		var err error
+		turn, err = readTurn(bucket.Bucket(turnsBucket), turnID)
+		return err
+	})
+	return turn, err
+}
+
+// Turns returns the cached turns of a chat, newest first
+func (s *Store) Turns(chatID string) ([]*cai.Turn, error) {
+	var turns []*cai.Turn
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := chatBucket(tx, chatID)
+		if bucket == nil {
+			return nil
+		}
+		order, err := readOrder(bucket)
+		if err != nil {
+			return err
+		}
+		turns = make([]*cai.Turn, 0, len(order))
+		for _, turnID := range order {
+			turn, err := readTurn(bucket.Bucket(turnsBucket), turnID)
+			if err != nil {
+				return err
+			}
+			turns = append(turns, turn)
+		}
+		return nil
+	})
+	return turns, err
+}
+
+// PutTurns adds or replaces turns given newest first
+func (s *Store) PutTurns(chatID string, turns []*cai.Turn) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createChatBucket(tx, chatID)
+		if err != nil {
+			return err
+		}
+		order, err := readOrder(bucket)
+		if err != nil {
+			return err
+		}
+
+		turnBucket := bucket.Bucket(turnsBucket)
+		var added []string
+		for _, turn := range turns {
+			turnID := turn.TurnKey.TurnID
+			if turnBucket.Get([]byte(turnID)) == nil {
+				added = append(added, turnID)
+			}
+			if err = writeTurn(turnBucket, turn); err != nil {
+				return err
+			}
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		return writeOrder(bucket, append(added, order...))
+	})
+}
+
+// ReplaceTurns replaces all cached turns of a chat with turns given newest first
+func (s *Store) ReplaceTurns(chatID string, turns []*cai.Turn) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createChatBucket(tx, chatID)
+		if err != nil {
+			return err
+		}
+		if err = bucket.DeleteBucket(turnsBucket); err != nil {
+			return err
+		}
+		turnBucket, err := bucket.CreateBucket(turnsBucket)
+		if err != nil {
+			return err
+		}
+
+		order := make([]string, 0, len(turns))
+		for _, turn := range turns {
+			if err = writeTurn(turnBucket, turn); err != nil {
+				return err
+			}
+			order = append(order, turn.TurnKey.TurnID)
+		}
+		return writeOrder(bucket, order)
+	})
+}
+
+// DeleteTurns removes turns of a chat; unknown IDs are ignored
+func (s *Store) DeleteTurns(chatID string, turnIDs []string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := chatBucket(tx, chatID)
+		if bucket == nil {
+			return nil
+		}
+		order, err := readOrder(bucket)
+		if err != nil {
+			return err
+		}
+
+		deleted := make(map[string]bool, len(turnIDs))
+		for _, turnID := range turnIDs {
+			deleted[turnID] = true
+			if err = bucket.Bucket(turnsBucket).Delete([]byte(turnID)); err != nil {
+				return err
+			}
+		}
+		kept := order[:0]
+		for _, turnID := range order {
+			if !deleted[turnID] {
+				kept = append(kept, turnID)
+			}
+		}
+		return writeOrder(bucket, kept)
+	})
+}
+
+// SyncTime returns when the turns of a chat were last synced completely
+func (s *Store) SyncTime(chatID string) (time.Time, error) {
+	var syncTime time.Time
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := chatBucket(tx, chatID)
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get(syncTimeKey)
+		if data == nil {
+			return nil
+		}
+		return syncTime.UnmarshalText(data)
+	})
+	return syncTime, err
+}
+
+// SetSyncTime records when the turns of a chat were synced completely
+func (s *Store) SetSyncTime(chatID string, syncTime time.Time) error {
+	data, err := syncTime.MarshalText()
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createChatBucket(tx, chatID)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(syncTimeKey, data)
+	})
+}
+
+// SyncedTurn returns the ID of the newest turn fetched by the last sync of a chat
+func (s *Store) SyncedTurn(chatID string) (string, error) {
+	var turnID string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if bucket := chatBucket(tx, chatID); bucket != nil {
+			turnID = string(bucket.Get(syncedKey))
+		}
+		return nil
+	})
+	return turnID, err
+}
+
+// SetSyncedTurn records the ID of the newest turn fetched by a sync of a chat
+func (s *Store) SetSyncedTurn(chatID, turnID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createChatBucket(tx, chatID)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(syncedKey, []byte(turnID))
+	})
+}
+
+// chatBucket returns the bucket of a chat, or nil if it does not exist
+func chatBucket(tx *bolt.Tx, chatID string) *bolt.Bucket {
+	return tx.Bucket(chatsBucket).Bucket([]byte(chatID))
+}
+
+// createChatBucket returns the bucket of a chat, creating it with an empty turns bucket if needed
+func createChatBucket(tx *bolt.Tx, chatID string) (*bolt.Bucket, error) {
+	bucket, err := tx.Bucket(chatsBucket).CreateBucketIfNotExists([]byte(chatID))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = bucket.CreateBucketIfNotExists(turnsBucket); err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+// readChat decodes the chat of a chat bucket, returning cai.ErrNotCached if only turns are stored
+func readChat(bucket *bolt.Bucket) (*cai.Chat, error) {
+	data := bucket.Get(chatKey)
+	if data == nil {
+		return nil, cai.ErrNotCached
+	}
+	var chat cai.Chat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
+// readTurn decodes a turn, returning cai.ErrNotCached if it is not stored
+func readTurn(bucket *bolt.Bucket, turnID string) (*cai.Turn, error) {
+	data := bucket.Get([]byte(turnID))
+	if data == nil {
+		return nil, cai.ErrNotCached
+	}
+	var turn cai.Turn
+	if err := json.Unmarshal(data, &turn); err != nil {
+		return nil, err
+	}
+	return &turn, nil
+}
+
+// writeTurn encodes a turn
+func writeTurn(bucket *bolt.Bucket, turn *cai.Turn) error {
+	data, err := json.Marshal(turn)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(turn.TurnKey.TurnID), data)
+}
+
+// readOrder returns the turn IDs of a chat, newest first
+func readOrder(bucket *bolt.Bucket) ([]string, error) {
+	data := bucket.Get(orderKey)
+	if data == nil {
+		return nil, nil
+	}
+	var order []string
+	err := json.Unmarshal(data, &order)
+	return order, err
+}
+
+// writeOrder stores the turn IDs of a chat, newest first
+func writeOrder(bucket *bolt.Bucket, order []string) error {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(orderKey, data)
+}
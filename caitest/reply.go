@@ -0,0 +1,60 @@
+package caitest
+
+import "strings"
+
+// Reply is a scripted character reply.
+type Reply struct {
+	Chunks          []string // Pieces of the reply text, each streamed as a separate update
+	SafetyTruncated bool     // Marks the final candidate as truncated by the safety filter
+	Error           string   // If set, generation fails with a neo_error carrying this comment
+}
+
+// Text returns the full text of the reply.
+func (r Reply) Text() string {
+	return strings.Join(r.Chunks, "")
+}
+
+// ReplyFunc produces the reply of a character to a message of the user.
+// For regenerated candidates, text is the message the character replies to, if there is one.
+type ReplyFunc func(characterID string, text string) Reply
+
+// EchoReply repeats the user's message, streamed word by word.
+func EchoReply(characterID string, text string) Reply {
+	return Reply{Chunks: Words("You said: " + text)}
+}
+
+// StaticReply always replies with text, streamed word by word.
+func StaticReply(text string) ReplyFunc {
+	return func(string, string) Reply {
+		return Reply{Chunks: Words(text)}
+	}
+}
+
+// ChunkedReply always replies with the given chunks, each streamed as a separate update.
+func ChunkedReply(chunks ...string) ReplyFunc {
+	return func(string, string) Reply {
+		return Reply{Chunks: chunks}
+	}
+}
+
+// ErrorReply makes every generation fail with a neo_error carrying comment.
+func ErrorReply(comment string) ReplyFunc {
+	return func(string, string) Reply {
+		return Reply{Error: comment}
+	}
+}
+
+// Words splits text into chunks of one word each, keeping the separating spaces.
+func Words(text string) []string {
+	var chunks []string
+	for len(text) > 0 {
+		end := strings.IndexByte(text[1:], ' ')
+		if end < 0 {
+			chunks = append(chunks, text)
+			break
+		}
+		chunks = append(chunks, text[:end+1])
+		text = text[end+1:]
+	}
+	return chunks
+}
@@ -0,0 +1,925 @@
+package caitest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+)
+
+// serveBeta serves the beta API
+func (s *Server) serveBeta(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/chat/user/":
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		writeJSON(w, http.StatusOK, cai.FetchMeResponse{User: s.account})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// servePlus serves the plus API
+func (s *Server) servePlus(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch r.URL.Path {
+	case "/chat/user/settings/":
+		writeJSON(w, http.StatusOK, s.settings)
+	case "/chat/user/update_settings/":
+		var settings cai.Settings
+		if !readJSON(w, r, &settings) {
+			return
+		}
+		if settings.PersonaOverrides == nil {
+			settings.PersonaOverrides = map[string]string{}
+		}
+		s.settings = settings
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "settings": s.settings})
+	case "/chat/user/followers/":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"followers": []*cai.PublicUser{}})
+	case "/chat/user/following/":
+		following := make([]*cai.PublicUser, 0, len(s.following))
+		for _, username := range s.following {
+			following = append(following, s.users[username])
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"following": following})
+	case "/chat/user/update/":
+		s.updateAccount(w, r)
+	case "/chat/user/characters/upvoted/":
+		characters := []cai.CharacterShort{}
+		for _, id := range s.order {
+			if s.votes[id] {
+				characters = append(characters, characterShort(s.characters[id]))
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"characters": characters})
+	case "/chat/user/public/":
+		s.fetchUser(w, r)
+	case "/chat/user/follow/", "/chat/user/unfollow/":
+		s.followUser(w, r, r.URL.Path == "/chat/user/follow/")
+	case "/chat/personas/":
+		personas := make([]*cai.Persona, 0, len(s.personas))
+		for _, persona := range s.personas {
+			personas = append(personas, persona)
+		}
+		sort.Slice(personas, func(i, j int) bool { return personas[i].Name < personas[j].Name })
+		writeJSON(w, http.StatusOK, map[string]interface{}{"personas": personas})
+	case "/chat/persona/":
+		persona, ok := s.personas[r.URL.Query().Get("id")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "persona not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"persona": persona})
+	case "/chat/characters/":
+		characters := []cai.CharacterShort{}
+		for _, id := range s.order {
+			if s.characters[id].AuthorUsername == s.account.User.Username {
+				characters = append(characters, characterShort(s.characters[id]))
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"characters": characters})
+	case "/chat/characters/featured_v2/":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"characters": s.publicCharacters("")})
+	case "/chat/curated_categories/characters/":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"characters_by_curated_category": map[string][]cai.CharacterShort{"Featured": s.publicCharacters("")},
+		})
+	case "/chat/characters/search/":
+		s.searchCharacters(w, r)
+	case "/chat/creators/search/":
+		s.searchCreators(w, r)
+	case "/chat/character/info/":
+		var payload cai.CharacterInfoPayload
+		if !readJSON(w, r, &payload) {
+			return
+		}
+		character, ok := s.characters[payload.ExternalID]
+		if !ok {
+			writeJSON(w, http.StatusOK, cai.CharacterInfoResponse{Status: "NOT_OK", Error: "character not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, cai.CharacterInfoResponse{Status: "OK", Character: character})
+	case "/chat/character/create/":
+		s.createCharacter(w, r)
+	case "/chat/character/update/":
+		s.updateCharacter(w, r)
+	case "/chat/character/vote/":
+		var payload cai.CharacterVotePayload
+		if !readJSON(w, r, &payload) {
+			return
+		}
+		if _, ok := s.characters[payload.ExternalID]; !ok {
+			writeJSON(w, http.StatusOK, cai.CharacterVoteResponse{Status: "NOT_OK", Error: "character not found"})
+			return
+		}
+		if payload.Vote == nil {
+			delete(s.votes, payload.ExternalID)
+		} else {
+			s.votes[payload.ExternalID] = *payload.Vote
+		}
+		writeJSON(w, http.StatusOK, cai.CharacterVoteResponse{Status: "OK"})
+	case "/chat/character/histories/":
+		s.fetchHistories(w, r)
+	case "/chat/character/generate-avatar-options":
+		var payload cai.GenerateImageRequest
+		if !readJSON(w, r, &payload) {
+			return
+		}
+		// Generated images are not served; their URLs only need to look like real ones
+		result := cai.GenerateImageResponse{Result: []cai.ImageResult{}}
+		for i := 0; i < payload.NumCandidates; i++ {
+			result.Result = append(result.Result, cai.ImageResult{URL: fmt.Sprintf("https://example.com/caitest/generated/%s.webp", uuid.New())})
+		}
+		writeJSON(w, http.StatusOK, result)
+	default:
+		// /chat/character/{id}/voice_override/{update,delete}/
+		segments := pathSegments(r.URL.Path)
+		if len(segments) == 5 && segments[0] == "chat" && segments[1] == "character" && segments[3] == "voice_override" {
+			if _, ok := s.characters[segments[2]]; !ok {
+				writeJSON(w, http.StatusOK, cai.SetVoiceResponse{Error: "character not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, cai.SetVoiceResponse{Success: true})
+			return
+		}
+		http.NotFound(w, r)
+	}
+}
+
+// updateAccount edits the profile of the fake user; the caller must hold the mutex
+func (s *Server) updateAccount(w http.ResponseWriter, r *http.Request) {
+	var payload cai.UpdateProfilePayload
+	if !readJSON(w, r, &payload) {
+		return
+	}
+
+	user := s.account.User
+	delete(s.users, user.Username)
+	user.Username = payload.Username
+	user.Account.Name = payload.Name
+	user.Account.AvatarType = payload.AvatarType
+	if payload.AvatarRelPath != "" {
+		user.Account.AvatarFileName = payload.AvatarRelPath
+	}
+	s.account.Name = payload.Name
+	s.account.Bio = payload.Bio
+	s.users[user.Username] = &cai.PublicUser{
+		Username:       user.Username,
+		Name:           payload.Name,
+		Bio:            payload.Bio,
+		AvatarFileName: user.Account.AvatarFileName,
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK"})
+}
+
+// fetchUser returns a public user profile; the caller must hold the mutex
+func (s *Server) fetchUser(w http.ResponseWriter, r *http.Request) {
+	var payload cai.FetchUserRequest
+	if !readJSON(w, r, &payload) {
+		return
+	}
+
+	user, ok := s.users[payload.Username]
+	if !ok {
+		// The real service fails with an internal error for unknown users
+		writeError(w, http.StatusInternalServerError, "user not found")
+		return
+	}
+	result := *user
+	result.Characters = []cai.CharacterShort{}
+	for _, id := range s.order {
+		character := s.characters[id]
+		if character.AuthorUsername == user.Username && character.Visibility == "PUBLIC" {
+			result.Characters = append(result.Characters, characterShort(character))
+		}
+	}
+	writeJSON(w, http.StatusOK, cai.FetchUserResponse{PublicUser: &result})
+}
+
+// followUser follows or unfollows a user; the caller must hold the mutex
+func (s *Server) followUser(w http.ResponseWriter, r *http.Request, follow bool) {
+	var payload cai.FollowUserRequest
+	if !readJSON(w, r, &payload) {
+		return
+	}
+
+	if _, ok := s.users[payload.Username]; !ok {
+		writeJSON(w, http.StatusOK, cai.FollowUserResponse{Status: "NOT_OK", Error: "user not found"})
+		return
+	}
+	following := s.following[:0]
+	for _, username := range s.following {
+		if username != payload.Username {
+			following = append(following, username)
+		}
+	}
+	if follow {
+		following = append(following, payload.Username)
+	}
+	s.following = following
+	writeJSON(w, http.StatusOK, cai.FollowUserResponse{Status: "OK"})
+}
+
+// searchCharacters finds public characters by name or title; the caller must hold the mutex
+func (s *Server) searchCharacters(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+
+	characters := []cai.CharacterSearchResult{}
+	for _, id := range s.order {
+		character := s.characters[id]
+		if character.Visibility != "PUBLIC" {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(character.Name), query) && !strings.Contains(strings.ToLower(character.Title), query) {
+			continue
+		}
+		characters = append(characters, cai.CharacterSearchResult{
+			DocumentID:              character.ExternalID,
+			ExternalID:              character.ExternalID,
+			Title:                   character.Title,
+			Greeting:                character.Greeting,
+			AvatarFileName:          character.AvatarFileName,
+			Visibility:              character.Visibility,
+			ParticipantName:         character.Name,
+			ParticipantInteractions: float64(character.NumInteractions),
+			AuthorUsername:          character.AuthorUsername,
+			SearchScore:             1,
+		})
+	}
+	start, end, hasMore := pageBounds(len(characters), r.URL.Query().Get("page"), s.pageSize)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"characters": characters[start:end], "has_more": hasMore})
+}
+
+// searchCreators finds users by username; the caller must hold the mutex
+func (s *Server) searchCreators(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+
+	known := map[string]bool{}
+	for username := range s.users {
+		known[username] = true
+	}
+	for _, character := range s.characters {
+		known[character.AuthorUsername] = true
+	}
+
+	creators := []cai.Creator{}
+	for username := range known {
+		if strings.Contains(strings.ToLower(username), query) {
+			creators = append(creators, cai.Creator{Name: username})
+		}
+	}
+	sort.Slice(creators, func(i, j int) bool { return creators[i].Name < creators[j].Name })
+	start, end, hasMore := pageBounds(len(creators), r.URL.Query().Get("page"), s.pageSize)
+	writeJSON(w, http.StatusOK, cai.SearchCreatorsResponse{Status: "OK", Creators: creators[start:end], HasMore: hasMore})
+}
+
+// createCharacter creates a character or, lacking a default voice, a persona; the caller must hold the mutex
+func (s *Server) createCharacter(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]json.RawMessage
+	if !readJSON(w, r, &fields) {
+		return
+	}
+	body, _ := json.Marshal(fields)
+
+	if _, ok := fields["default_voice_id"]; !ok {
+		var payload cai.CreatePersonaPayload
+		_ = json.Unmarshal(body, &payload)
+		persona := &cai.Persona{
+			PersonaID:             uuid.New().String(),
+			Name:                  payload.Name,
+			Definition:            payload.Definition,
+			Greeting:              payload.Greeting,
+			Description:           payload.Description,
+			AvatarFileName:        payload.AvatarRelPath,
+			Visibility:            payload.Visibility,
+			VoiceID:               payload.VoiceID,
+			AuthorUsername:        s.account.User.Username,
+			Identifier:            payload.Identifier,
+			Categories:            payload.Categories,
+			BaseImgPrompt:         payload.BaseImgPrompt,
+			ImgGenEnabled:         payload.ImgGenEnabled,
+			Copyable:              payload.Copyable,
+			StripImgPromptFromMsg: payload.StripImgPromptFromMsg,
+		}
+		s.personas[persona.PersonaID] = persona
+		writeJSON(w, http.StatusOK, cai.CreatePersonaResult{Status: "OK", Persona: persona})
+		return
+	}
+
+	var payload cai.CreateCharacterPayload
+	_ = json.Unmarshal(body, &payload)
+	character := s.addCharacter(cai.Character{
+		Name:           payload.Name,
+		Title:          payload.Title,
+		Greeting:       payload.Greeting,
+		Description:    payload.Description,
+		Definition:     payload.Definition,
+		Visibility:     payload.Visibility,
+		AvatarFileName: payload.AvatarRelPath,
+		Copyable:       payload.Copyable,
+		Identifier:     payload.Identifier,
+		ImgGenEnabled:  payload.ImgGenEnabled,
+		BaseImgPrompt:  payload.BaseImgPrompt,
+		StripImgPrompt: payload.StripImgPromptFromMsg,
+		VoiceID:        payload.VoiceID,
+		DefaultVoiceID: payload.DefaultVoiceID,
+		AuthorUsername: s.account.User.Username,
+	})
+	writeJSON(w, http.StatusOK, cai.CreateCharacterResult{Status: "OK", Character: character})
+}
+
+// updateCharacter edits a persona or one of the user's characters; the caller must hold the mutex
+func (s *Server) updateCharacter(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]json.RawMessage
+	if !readJSON(w, r, &fields) {
+		return
+	}
+	body, _ := json.Marshal(fields)
+
+	var key struct {
+		ExternalID string `json:"external_id"`
+	}
+	_ = json.Unmarshal(body, &key)
+
+	if persona, ok := s.personas[key.ExternalID]; ok {
+		var payload cai.EditPersonaPayload
+		_ = json.Unmarshal(body, &payload)
+		if payload.Archived {
+			delete(s.personas, persona.PersonaID)
+			writeJSON(w, http.StatusOK, cai.DeletePersonaResult{Status: "OK", Persona: persona})
+			return
+		}
+		persona.Name = payload.Name
+		persona.Definition = payload.Definition
+		persona.Greeting = payload.Greeting
+		persona.Description = payload.Description
+		persona.Visibility = payload.Visibility
+		persona.AvatarFileName = payload.AvatarRelPath
+		persona.VoiceID = payload.VoiceID
+		persona.Categories = payload.Categories
+		writeJSON(w, http.StatusOK, cai.EditPersonaResult{Status: "OK", Persona: persona})
+		return
+	}
+
+	character, ok := s.characters[key.ExternalID]
+	if !ok || character.AuthorUsername != s.account.User.Username {
+		writeJSON(w, http.StatusOK, cai.EditCharacterResult{Status: "NOT_OK", Error: "character not found"})
+		return
+	}
+	var payload cai.EditCharacterPayload
+	_ = json.Unmarshal(body, &payload)
+	character.Name = payload.Name
+	character.ParticipantName = payload.Name
+	character.Title = payload.Title
+	character.Greeting = payload.Greeting
+	character.Description = payload.Description
+	character.Definition = payload.Definition
+	character.Visibility = payload.Visibility
+	character.Copyable = payload.Copyable
+	character.DefaultVoiceID = payload.DefaultVoiceID
+	if payload.AvatarRelPath != "" {
+		character.AvatarFileName = payload.AvatarRelPath
+	}
+	if payload.Archived {
+		character.Visibility = "PRIVATE"
+	}
+	writeJSON(w, http.StatusOK, cai.EditCharacterResult{Status: "OK", Character: character})
+}
+
+// fetchHistories lists the chats with a character in the legacy history format; the caller must hold the mutex
+func (s *Server) fetchHistories(w http.ResponseWriter, r *http.Request) {
+	var payload cai.FetchHistoriesRequest
+	if !readJSON(w, r, &payload) {
+		return
+	}
+
+	var chats []*chatState
+	for _, state := range s.sortedChats() {
+		if state.chat.CharacterID == payload.ExternalID {
+			chats = append(chats, state)
+		}
+	}
+	size := payload.Number
+	if size <= 0 {
+		size = len(chats)
+	}
+	start, end, hasMore := pageBounds(len(chats), strconv.Itoa(payload.Page), size)
+
+	histories := []cai.ChatHistory{}
+	for _, state := range chats[start:end] {
+		history := cai.ChatHistory{
+			ChatID:             state.chat.ChatID,
+			CreateTimeStr:      state.chat.CreateTimeStr,
+			LastInteractionStr: state.lastInteraction(),
+			PreviewMessages:    []cai.HistoryMessage{},
+		}
+		for i, turn := range state.turns {
+			text := ""
+			if candidate := turn.PrimaryCandidate(); candidate != nil {
+				text = candidate.Text
+			}
+			history.PreviewMessages = append(history.PreviewMessages, cai.HistoryMessage{
+				ID:            int64(i + 1),
+				Text:          text,
+				Src:           &cai.HistoryMessageActor{IsHuman: turn.Author.IsHuman, Name: turn.Author.Name},
+				Annotable:     !turn.Author.IsHuman,
+				DisplayName:   turn.Author.Name,
+				CreateTimeStr: turn.CreateTimeStr,
+			})
+		}
+		histories = append(histories, history)
+	}
+	writeJSON(w, http.StatusOK, cai.FetchHistoriesResponse{Histories: histories, HasMore: hasMore})
+}
+
+// serveNeo serves the neo API
+func (s *Server) serveNeo(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	segments := pathSegments(r.URL.Path)
+	switch {
+	case r.URL.Path == "/ping/":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "pong"})
+	case r.URL.Path == "/chats/":
+		s.fetchChats(w, r)
+	case r.URL.Path == "/annotations/":
+		var payload cai.AnnotateCandidatePayload
+		if !readJSON(w, r, &payload) {
+			return
+		}
+		state, index := s.findTurn(payload.TurnKey)
+		if index < 0 || findCandidate(state.turns[index], payload.CandidateID) == nil {
+			writeError(w, http.StatusNotFound, "candidate not found")
+			return
+		}
+		feedback, ok := s.annotations[payload.CandidateID]
+		if !ok {
+			feedback = &annotation{}
+			s.annotations[payload.CandidateID] = feedback
+		}
+		if payload.StarRating != 0 {
+			feedback.stars = payload.StarRating
+		}
+		feedback.tags = append(feedback.tags, payload.Annotations...)
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	case r.URL.Path == "/rooms/":
+		chats := []*cai.Chat{}
+		for _, state := range s.sortedChats() {
+			if state.chat.ChatType == cai.ChatTypeGroup {
+				chat := state.chat
+				chats = append(chats, &chat)
+			}
+		}
+		writeJSON(w, http.StatusOK, cai.FetchChatsResponse{Chats: chats})
+	case r.URL.Path == "/chats/recent/":
+		chats := []*cai.Chat{}
+		for _, state := range s.sortedChats() {
+			chat := state.chat
+			chats = append(chats, &chat)
+		}
+		writeJSON(w, http.StatusOK, cai.FetchChatsResponse{Chats: chats})
+	case len(segments) == 2 && segments[0] == "turns":
+		s.fetchTurns(w, r, segments[1])
+	case len(segments) >= 2 && segments[0] == "chat":
+		s.serveChat(w, r, segments[1], segments[2:])
+	case r.URL.Path == "/notifications/":
+		s.fetchNotifications(w, r)
+	case r.URL.Path == "/notifications/mark_read/":
+		var payload cai.MarkNotificationsReadPayload
+		if !readJSON(w, r, &payload) {
+			return
+		}
+		for _, notification := range s.notifications {
+			if containsString(payload.NotificationIDs, notification.NotificationID) {
+				notification.IsRead = true
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	case r.URL.Path == "/notifications/mark_all_read/":
+		for _, notification := range s.notifications {
+			notification.IsRead = true
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	case r.URL.Path == "/recommendation/v1/user":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"characters": s.publicCharacters("")})
+	case len(segments) == 4 && segments[0] == "recommendation" && segments[2] == "character":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"characters": s.publicCharacters(segments[3])})
+	case len(segments) >= 4 && segments[0] == "multimodal" && segments[3] == "voices":
+		s.serveVoices(w, r, segments[4:])
+	case r.URL.Path == "/multimodal/api/v1/memo/replay":
+		var payload cai.GenerateSpeechPayload
+		if !readJSON(w, r, &payload) {
+			return
+		}
+		if _, ok := s.voices[payload.VoiceID]; !ok {
+			writeError(w, http.StatusNotFound, "voice not found")
+			return
+		}
+		replayURL := fmt.Sprintf("%s/audio/%s.mp3", s.Endpoints().Media, payload.CandidateID)
+		writeJSON(w, http.StatusOK, cai.GenerateSpeechResponse{ReplayURL: replayURL})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// fetchChats lists the chats with a character including their latest turns; the caller must hold the mutex
+func (s *Server) fetchChats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	numPreviewTurns, _ := strconv.Atoi(query.Get("num_preview_turns"))
+	characterIDs := strings.Split(query.Get("character_ids"), ",")
+
+	chats := []*cai.Chat{}
+	for _, state := range s.sortedChats() {
+		if !containsString(characterIDs, state.chat.CharacterID) {
+			continue
+		}
+		chat := state.chat
+		for i := len(state.turns) - 1; i >= 0 && len(chat.PreviewTurns) < numPreviewTurns; i-- {
+			turn := copyTurn(state.turns[i])
+			chat.PreviewTurns = append(chat.PreviewTurns, &turn)
+		}
+		chats = append(chats, &chat)
+	}
+	writeJSON(w, http.StatusOK, cai.FetchChatsResponse{Chats: chats})
+}
+
+// fetchTurns returns a page of turns of a chat, newest first; the caller must hold the mutex
+func (s *Server) fetchTurns(w http.ResponseWriter, r *http.Request, chatID string) {
+	state, ok := s.chats[chatID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "chat not found")
+		return
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("next_token"))
+	result := cai.FetchMessagesResponse{Turns: []cai.Turn{}}
+	for i := len(state.turns) - 1 - offset; i >= 0 && len(result.Turns) < s.pageSize; i-- {
+		result.Turns = append(result.Turns, copyTurn(state.turns[i]))
+	}
+	if next := offset + len(result.Turns); next < len(state.turns) {
+		result.Meta.NextToken = strconv.Itoa(next)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// fetchNotifications lists a page of notifications, newest first; the caller must hold the mutex
+func (s *Server) fetchNotifications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	unreadOnly := query.Get("unread_only") == "true"
+
+	var matching []cai.Notification
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		if !unreadOnly || !s.notifications[i].IsRead {
+			matching = append(matching, *s.notifications[i])
+		}
+	}
+
+	offset, _ := strconv.Atoi(query.Get("next_token"))
+	result := cai.NotificationsResponsePayload{Notifications: []cai.Notification{}}
+	for i := offset; i < len(matching) && len(result.Notifications) < s.pageSize; i++ {
+		result.Notifications = append(result.Notifications, matching[i])
+	}
+	if next := offset + len(result.Notifications); next < len(matching) {
+		result.Meta.NextToken = strconv.Itoa(next)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// serveChat serves /chat/{id}/ and the actions below it; the caller must hold the mutex
+func (s *Server) serveChat(w http.ResponseWriter, r *http.Request, chatID string, action []string) {
+	state, ok := s.chats[chatID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "chat not found")
+		return
+	}
+
+	if len(action) == 0 {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"chat": state.chat})
+		return
+	}
+	switch action[0] {
+	case "update_name":
+		var payload cai.UpdateChatNamePayload
+		if !readJSON(w, r, &payload) {
+			return
+		}
+		state.chat.ChatName = payload.Name
+	case "archive":
+		state.archived = true
+	case "unarchive":
+		state.archived = false
+	case "copy":
+		var payload cai.CopyChatRequest
+		if !readJSON(w, r, &payload) {
+			return
+		}
+		copied := &chatState{chat: state.chat}
+		copied.chat.ChatID = uuid.New().String()
+		copied.chat.CreateTimeStr = timestamp()
+		for _, turn := range state.turns {
+			turnCopy := copyTurn(turn)
+			turnCopy.TurnKey.ChatID = copied.chat.ChatID
+			turnCopy.ChatID = copied.chat.ChatID
+			copied.turns = append(copied.turns, &turnCopy)
+			if turn.TurnKey.TurnID == payload.EndTurnID {
+				break
+			}
+		}
+		s.chats[copied.chat.ChatID] = copied
+		writeJSON(w, http.StatusOK, cai.CopyChatResponse{NewChatID: copied.chat.ChatID})
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+// serveVoices serves the voices API below /multimodal/api/v1/voices/; the caller must hold the mutex
+func (s *Server) serveVoices(w http.ResponseWriter, r *http.Request, path []string) {
+	if len(path) == 0 {
+		if r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		s.uploadVoice(w, r)
+		return
+	}
+
+	switch path[0] {
+	case "user":
+		writeJSON(w, http.StatusOK, cai.SearchVoicesResponse{Voices: s.filterVoices(func(voice *cai.Voice) bool {
+			return voice.CreatorInfo.Username == s.account.User.Username
+		})})
+	case "search":
+		query := r.URL.Query()
+		name := strings.ToLower(query.Get("query"))
+		creator := query.Get("creatorInfo.username")
+		voices := s.filterVoices(func(voice *cai.Voice) bool {
+			if creator != "" {
+				return voice.CreatorInfo.Username == creator
+			}
+			return strings.Contains(strings.ToLower(voice.Name), name)
+		})
+		offset, _ := strconv.Atoi(query.Get("pageToken"))
+		if offset > len(voices) {
+			offset = len(voices)
+		}
+		result := cai.SearchVoicesResponse{Voices: voices[offset:]}
+		if len(result.Voices) > s.pageSize {
+			result.Voices = result.Voices[:s.pageSize]
+			result.NextPageToken = strconv.Itoa(offset + s.pageSize)
+		}
+		writeJSON(w, http.StatusOK, result)
+	default:
+		voice, ok := s.voices[path[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "voice not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, cai.FetchVoiceResponse{Voice: voice})
+		case http.MethodPut:
+			var payload cai.VoiceUpdatePayload
+			if !readJSON(w, r, &payload) {
+				return
+			}
+			voice.Name = payload.Voice.Name
+			voice.Description = payload.Voice.Description
+			voice.Visibility = payload.Voice.Visibility
+			voice.LastUpdateTimeStr = timestamp()
+			writeJSON(w, http.StatusOK, cai.UploadVoiceResponse{Voice: voice})
+		case http.MethodDelete:
+			delete(s.voices, voice.VoiceID)
+			writeJSON(w, http.StatusOK, map[string]interface{}{})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// uploadVoice creates a voice from a multipart upload; the caller must hold the mutex
+func (s *Server) uploadVoice(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var metadata cai.UploadVoiceMetadata
+	err = json.Unmarshal([]byte(r.FormValue("json")), &metadata)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	voice := &cai.Voice{
+		VoiceID:           uuid.New().String(),
+		Name:              metadata.Voice.Name,
+		Description:       metadata.Voice.Description,
+		Gender:            metadata.Voice.Gender,
+		Visibility:        metadata.Voice.Visibility,
+		PreviewText:       metadata.Voice.PreviewText,
+		InternalStatus:    "draft",
+		CreatorInfo:       &cai.CreatorInfo{ID: fmt.Sprint(s.account.User.ID), Username: s.account.User.Username},
+		LastUpdateTimeStr: timestamp(),
+	}
+	s.voices[voice.VoiceID] = voice
+	writeJSON(w, http.StatusOK, cai.UploadVoiceResponse{Voice: voice})
+}
+
+// filterVoices returns the voices matching keep, ordered by name; the caller must hold the mutex
+func (s *Server) filterVoices(keep func(voice *cai.Voice) bool) []*cai.Voice {
+	voices := []*cai.Voice{}
+	for _, voice := range s.voices {
+		if keep(voice) {
+			voices = append(voices, voice)
+		}
+	}
+	sort.Slice(voices, func(i, j int) bool { return voices[i].Name < voices[j].Name })
+	return voices
+}
+
+// serveTRPC serves the tRPC API of the website
+func (s *Server) serveTRPC(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/user.uploadAvatar" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var payload cai.UploadAvatarRequest
+	if !readJSON(w, r, &payload) {
+		return
+	}
+	dataURL := payload["0"].JSON.ImageDataURL
+	comma := strings.IndexByte(dataURL, ',')
+	if !strings.HasPrefix(dataURL, "data:") || comma < 0 {
+		writeError(w, http.StatusBadRequest, "invalid image data URL")
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(dataURL[comma+1:])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fileName := fmt.Sprintf("uploaded/%s.png", uuid.New())
+	s.mutex.Lock()
+	s.avatars[fileName] = data
+	s.mutex.Unlock()
+
+	var result cai.UploadAvatarResponse
+	result.Result.Data.JSON = fileName
+	writeJSON(w, http.StatusOK, []cai.UploadAvatarResponse{result})
+}
+
+// serveMedia serves uploaded avatars and generated speech
+func (s *Server) serveMedia(w http.ResponseWriter, r *http.Request) {
+	segments := pathSegments(r.URL.Path)
+	switch {
+	case len(segments) >= 5 && segments[0] == "i" && segments[2] == "static" && segments[3] == "avatars":
+		s.mutex.Lock()
+		data, ok := s.avatars[strings.Join(segments[4:], "/")]
+		s.mutex.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", http.DetectContentType(data))
+		_, _ = w.Write(data)
+	case len(segments) == 2 && segments[0] == "audio":
+		// Speech is not synthesized; the audio names the candidate it was generated for
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("caitest audio " + strings.TrimSuffix(segments[1], ".mp3")))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// sortedChats returns the chats which are not archived, most recently active first; the caller must hold the mutex
+func (s *Server) sortedChats() []*chatState {
+	var chats []*chatState
+	for _, state := range s.chats {
+		if !state.archived {
+			chats = append(chats, state)
+		}
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i].lastInteraction() > chats[j].lastInteraction() })
+	return chats
+}
+
+// lastInteraction returns the time of the latest turn, or the creation time of an empty chat
+func (c *chatState) lastInteraction() string {
+	if len(c.turns) == 0 {
+		return c.chat.CreateTimeStr
+	}
+	return c.turns[len(c.turns)-1].CreateTimeStr
+}
+
+// publicCharacters returns the public characters except the one with ID excluded; the caller must hold the mutex
+func (s *Server) publicCharacters(excluded string) []cai.CharacterShort {
+	characters := []cai.CharacterShort{}
+	for _, id := range s.order {
+		character := s.characters[id]
+		if character.Visibility == "PUBLIC" && id != excluded {
+			characters = append(characters, characterShort(character))
+		}
+	}
+	return characters
+}
+
+// characterShort returns the summary of a character
+func characterShort(character *cai.Character) cai.CharacterShort {
+	short := cai.CharacterShort{
+		CharacterID:     character.ExternalID,
+		Title:           character.Title,
+		Greeting:        character.Greeting,
+		AvatarFileName:  character.AvatarFileName,
+		Copyable:        character.Copyable,
+		ParticipantName: character.Name,
+		AuthorUsername:  character.AuthorUsername,
+		NumInteractions: character.NumInteractions,
+		ImgGenEnabled:   character.ImgGenEnabled,
+		Upvotes:         character.Upvotes,
+		Name:            character.Name,
+	}
+	if character.DefaultVoiceID != "" {
+		voiceID := character.DefaultVoiceID
+		short.DefaultVoiceID = &voiceID
+	}
+	return short
+}
+
+// writeJSON writes v as JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format used by the API
+func writeError(w http.ResponseWriter, status int, message string) {
+	var response cai.ErrorResponse
+	response.Error.Message = message
+	writeJSON(w, status, response)
+}
+
+// readJSON decodes the request body into v, answering with an error response if it is malformed
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// pageBounds returns the range of the items on a page of a page-numbered listing, given its number starting at 1,
+// and whether more pages follow
+func pageBounds(count int, number string, size int) (start, end int, hasMore bool) {
+	page, _ := strconv.Atoi(number)
+	if page < 1 {
+		page = 1
+	}
+	start = (page - 1) * size
+	if start > count {
+		start = count
+	}
+	end = start + size
+	if end > count {
+		end = count
+	}
+	return start, end, end < count
+}
+
+// pathSegments splits a URL path into its non-empty segments
+func pathSegments(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
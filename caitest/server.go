@@ -0,0 +1,555 @@
+// Package caitest provides an in-process fake of the character.ai services for testing code built on cai.Client.
+//
+// The fake serves the REST endpoints and the neo WebSocket protocol from an httptest.Server.
+// Character replies are scriptable per character, so bots can be unit-tested offline:
+//
+//	server := caitest.NewServer()
+//	defer server.Close()
+//
+//	server.SetReply(caitest.CharacterID, caitest.StaticReply("Hello there!"))
+//	client := server.NewClient()
+//	chat, _, _ := client.CreateChat(caitest.CharacterID, false)
+//	turn, _ := client.SendMessage(caitest.CharacterID, chat.ChatID, "Hi")
+package caitest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+)
+
+const (
+	// Token is the API token accepted by the fake
+	Token = "caitest-token"
+	// UserID is the account ID of the fake user
+	UserID = 1000
+	// Username is the username of the fake user
+	Username = "caitest"
+	// CharacterID is the ID of the character every new Server knows
+	CharacterID = "caitest-character"
+)
+
+// Server is a fake character.ai service.
+// All exported methods are safe for concurrent use.
+type Server struct {
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+	conns    map[*websocket.Conn]struct{} // Open WebSocket connections
+
+	mutex         sync.Mutex
+	account       cai.UserAccount
+	settings      cai.Settings
+	votes         map[string]bool
+	following     []string
+	users         map[string]*cai.PublicUser
+	characters    map[string]*cai.Character
+	order         []string // Character IDs in creation order
+	personas      map[string]*cai.Persona
+	voices        map[string]*cai.Voice
+	avatars       map[string][]byte
+	chats         map[string]*chatState
+	annotations   map[string]*annotation // Feedback by candidate ID
+	notifications []*cai.Notification    // Chronological order
+	replies       map[string]ReplyFunc
+	defaultReply  ReplyFunc
+	failures      map[string][]string      // Scripted neo_error comments by command
+	httpFailures  map[string][]httpFailure // Scripted HTTP error responses by path
+	requests      map[string]int           // Number of HTTP requests received by path
+	dialFailures  int                      // Number of WebSocket handshakes still to reject
+	ignorePings   bool
+	updateHuman   bool // Whether the user's turns are confirmed by an update_turn frame
+	chunkDelay    time.Duration
+	pageSize      int
+}
+
+// chatState is a chat held by the fake
+type chatState struct {
+	chat         cai.Chat
+	turns        []*cai.Turn // Chronological order
+	archived     bool
+	lastGenerate *GenerateRequest // Latest generate request received in the chat
+	aborts       int              // Number of abort_generation commands received for the chat
+}
+
+// GenerateRequest holds the generation parameters of a request for a character turn or candidate
+type GenerateRequest struct {
+	CharacterID         string
+	NumCandidates       int
+	PreviousAnnotations cai.PreviousAnnotations
+	SelectedLanguage    string
+	TTSEnabled          bool
+	UserName            string
+	PersonaID           string // Persona override of the character when the request was received
+}
+
+// annotation is the feedback given on a candidate
+type annotation struct {
+	stars int
+	tags  []string
+}
+
+// NewServer starts a fake service knowing the fake user and the character CharacterID.
+// The server must be closed after use.
+func NewServer() *Server {
+	s := &Server{
+		conns: map[*websocket.Conn]struct{}{},
+		account: cai.UserAccount{
+			User: &cai.User{
+				Username:  Username,
+				ID:        UserID,
+				FirstName: "Caitest",
+				Account: &cai.Account{
+					Name:               "Caitest User",
+					AvatarType:         "DEFAULT",
+					OnboardingComplete: true,
+				},
+			},
+			IsHuman: true,
+			Name:    "Caitest User",
+			Email:   "caitest@example.com",
+		},
+		settings: cai.Settings{
+			PersonaOverrides: map[string]string{},
+		},
+		votes:        map[string]bool{},
+		users:        map[string]*cai.PublicUser{},
+		characters:   map[string]*cai.Character{},
+		personas:     map[string]*cai.Persona{},
+		voices:       map[string]*cai.Voice{},
+		avatars:      map[string][]byte{},
+		chats:        map[string]*chatState{},
+		annotations:  map[string]*annotation{},
+		replies:      map[string]ReplyFunc{},
+		defaultReply: EchoReply,
+		failures:     map[string][]string{},
+		httpFailures: map[string][]httpFailure{},
+		requests:     map[string]int{},
+		pageSize:     50,
+	}
+	s.users[Username] = &cai.PublicUser{
+		Username: Username,
+		Name:     "Caitest User",
+	}
+	s.AddCharacter(cai.Character{
+		ExternalID:     CharacterID,
+		Name:           "Test Character",
+		Title:          "A character for testing",
+		Greeting:       "Hello! I am a test character.",
+		Description:    "Replies to everything in a predictable way.",
+		Definition:     "{{char}} is a fake character served by caitest.",
+		Visibility:     "PUBLIC",
+		Copyable:       true,
+		AuthorUsername: "caitest-creator",
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/beta/", s.authenticated(http.StripPrefix("/beta", http.HandlerFunc(s.serveBeta))))
+	mux.Handle("/plus/", s.authenticated(http.StripPrefix("/plus", http.HandlerFunc(s.servePlus))))
+	mux.Handle("/neo/ws/", http.HandlerFunc(s.serveWebSocket))
+	mux.Handle("/neo/", s.authenticated(http.StripPrefix("/neo", http.HandlerFunc(s.serveNeo))))
+	mux.Handle("/trpc/", s.authenticated(http.StripPrefix("/trpc", http.HandlerFunc(s.serveTRPC))))
+	mux.Handle("/media/", http.StripPrefix("/media", http.HandlerFunc(s.serveMedia)))
+	s.server = httptest.NewServer(s.scripted(mux))
+
+	return s
+}
+
+// Close shuts down the server and all its connections.
+func (s *Server) Close() {
+	// Hijacked WebSocket connections are not tracked by the HTTP server
+	s.mutex.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+// URL returns the base URL of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Endpoints returns the endpoints to configure a cai.Client with for talking to the fake.
+func (s *Server) Endpoints() cai.Endpoints {
+	return cai.Endpoints{
+		Neo:       s.server.URL + "/neo",
+		Plus:      s.server.URL + "/plus",
+		Beta:      s.server.URL + "/beta",
+		TRPC:      s.server.URL + "/trpc",
+		Media:     s.server.URL + "/media",
+		WebSocket: "ws" + strings.TrimPrefix(s.server.URL, "http") + "/neo/ws/",
+	}
+}
+
+// NewClient returns a client using the fake's endpoints and token.
+// The client is authenticated, so turn-creating calls work right away.
+func (s *Server) NewClient(options ...cai.ClientOption) *cai.Client {
+	options = append([]cai.ClientOption{cai.WithEndpoints(s.Endpoints())}, options...)
+	client, err := cai.NewClient(Token, "", "", options...)
+	if err != nil {
+		panic(fmt.Sprintf("caitest: creating client: %v", err))
+	}
+	client.UserAccountID = fmt.Sprint(UserID)
+	return client
+}
+
+// AddCharacter adds a character to the fake. An empty ExternalID is generated.
+// The character is returned with its ID set.
+func (s *Server) AddCharacter(character cai.Character) *cai.Character {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.addCharacter(character)
+}
+
+// addCharacter adds a character; the caller must hold the mutex
+func (s *Server) addCharacter(character cai.Character) *cai.Character {
+	if character.ExternalID == "" {
+		character.ExternalID = uuid.New().String()
+	}
+	if character.ParticipantName == "" {
+		character.ParticipantName = character.Name
+	}
+	if character.AuthorUsername == "" {
+		character.AuthorUsername = Username
+	}
+	if _, ok := s.characters[character.ExternalID]; !ok {
+		s.order = append(s.order, character.ExternalID)
+	}
+	s.characters[character.ExternalID] = &character
+	return &character
+}
+
+// AddVoice adds a voice to the fake. An empty VoiceID is generated.
+// The voice is returned with its ID set.
+func (s *Server) AddVoice(voice cai.Voice) *cai.Voice {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if voice.VoiceID == "" {
+		voice.VoiceID = uuid.New().String()
+	}
+	if voice.CreatorInfo == nil {
+		voice.CreatorInfo = &cai.CreatorInfo{ID: fmt.Sprint(UserID), Username: Username}
+	}
+	if voice.LastUpdateTimeStr == "" {
+		voice.LastUpdateTimeStr = timestamp()
+	}
+	s.voices[voice.VoiceID] = &voice
+	return &voice
+}
+
+// AddUser adds a public user to the fake, e.g. to be followed.
+func (s *Server) AddUser(user cai.PublicUser) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.users[user.Username] = &user
+}
+
+// SetReply scripts the replies of a character.
+func (s *Server) SetReply(characterID string, reply ReplyFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.replies[characterID] = reply
+}
+
+// SetDefaultReply scripts the replies of all characters without a reply of their own. It defaults to EchoReply.
+func (s *Server) SetDefaultReply(reply ReplyFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.defaultReply = reply
+}
+
+// SetChunkDelay sets the delay between the streamed updates of a reply. It defaults to zero.
+func (s *Server) SetChunkDelay(delay time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.chunkDelay = delay
+}
+
+// SetPageSize sets the number of items per page of paginated endpoints. It defaults to 50.
+func (s *Server) SetPageSize(size int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pageSize = size
+}
+
+// FailNext makes the next WebSocket command of the given name fail with a neo_error carrying comment.
+// Calls queue up, failing that many subsequent commands.
+func (s *Server) FailNext(command string, comment string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures[command] = append(s.failures[command], comment)
+}
+
+// DropWebSockets closes all open WebSocket connections without a close handshake, as a network failure would.
+func (s *Server) DropWebSockets() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+// FailNextWebSocketDials makes the next count WebSocket handshakes fail with 503 Service Unavailable.
+func (s *Server) FailNextWebSocketDials(count int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.dialFailures = count
+}
+
+// SetIgnorePings makes WebSocket connections stop answering pings, like a peer which died silently.
+func (s *Server) SetIgnorePings(ignore bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.ignorePings = ignore
+}
+
+// SetUserTurnUpdates makes the fake follow the add_turn frame of each user message with an update_turn frame
+// for the same turn, as the service does once the message passed moderation.
+func (s *Server) SetUserTurnUpdates(enabled bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateHuman = enabled
+}
+
+// FailNextRequest makes the next HTTP request to path, relative to URL, fail with the given status code.
+// A positive retryAfter is sent as Retry-After header. Calls queue up, failing that many subsequent requests.
+func (s *Server) FailNextRequest(path string, status int, retryAfter time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.httpFailures[path] = append(s.httpFailures[path], httpFailure{status: status, retryAfter: retryAfter})
+}
+
+// FailRequestAfter lets the next passed HTTP requests to path, relative to URL, through and makes the one after
+// them fail with the given status code. It queues up with FailNextRequest.
+func (s *Server) FailRequestAfter(path string, passed int, status int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := 0; i < passed; i++ {
+		s.httpFailures[path] = append(s.httpFailures[path], httpFailure{})
+	}
+	s.httpFailures[path] = append(s.httpFailures[path], httpFailure{status: status})
+}
+
+// Requests returns the number of HTTP requests received for path, relative to URL, e.g. "/neo/turns/<chat ID>/".
+func (s *Server) Requests(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests[path]
+}
+
+// AddNotification adds a notification for the fake user. An empty NotificationID is generated and
+// an empty creation time is set to now. The notification is returned with these fields set.
+func (s *Server) AddNotification(notification cai.Notification) *cai.Notification {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if notification.NotificationID == "" {
+		notification.NotificationID = uuid.New().String()
+	}
+	if notification.CreateTimeStr == "" {
+		notification.CreateTimeStr = timestamp()
+	}
+	notification.CreateTime, _ = time.Parse(time.RFC3339Nano, notification.CreateTimeStr)
+	s.notifications = append(s.notifications, &notification)
+
+	added := notification
+	return &added
+}
+
+// RemoveNotification deletes a notification of the fake user, as the service does for notifications of
+// removed content. Unknown IDs are ignored.
+func (s *Server) RemoveNotification(notificationID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, notification := range s.notifications {
+		if notification.NotificationID == notificationID {
+			s.notifications = append(s.notifications[:i], s.notifications[i+1:]...)
+			return
+		}
+	}
+}
+
+// Notifications returns a copy of the fake user's notifications in chronological order.
+func (s *Server) Notifications() []cai.Notification {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	notifications := make([]cai.Notification, len(s.notifications))
+	for i, notification := range s.notifications {
+		notifications[i] = *notification
+	}
+	return notifications
+}
+
+// Annotation returns the latest star rating given to a candidate, 0 if none, and all tags it was annotated with.
+func (s *Server) Annotation(candidateID string) (int, []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	feedback, ok := s.annotations[candidateID]
+	if !ok {
+		return 0, nil
+	}
+	return feedback.stars, append([]string(nil), feedback.tags...)
+}
+
+// PreviousAnnotations returns the previous annotations sent with the latest generate request in a chat,
+// or nil if there was none.
+func (s *Server) PreviousAnnotations(chatID string) cai.PreviousAnnotations {
+	request, ok := s.LastGenerateRequest(chatID)
+	if !ok {
+		return nil
+	}
+	return request.PreviousAnnotations
+}
+
+// LastGenerateRequest returns the parameters of the latest generate request in a chat, and whether there was one.
+func (s *Server) LastGenerateRequest(chatID string) (GenerateRequest, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, ok := s.chats[chatID]
+	if !ok || state.lastGenerate == nil {
+		return GenerateRequest{}, false
+	}
+	request := *state.lastGenerate
+	if request.PreviousAnnotations != nil {
+		request.PreviousAnnotations = make(cai.PreviousAnnotations, len(state.lastGenerate.PreviousAnnotations))
+		for tag, count := range state.lastGenerate.PreviousAnnotations {
+			request.PreviousAnnotations[tag] = count
+		}
+	}
+	return request, true
+}
+
+// Aborts returns the number of abort_generation commands received for a chat
+func (s *Server) Aborts(chatID string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if state, ok := s.chats[chatID]; ok {
+		return state.aborts
+	}
+	return 0
+}
+
+// Turns returns a copy of the turns of a chat in chronological order, or nil if the chat does not exist.
+func (s *Server) Turns(chatID string) []cai.Turn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, ok := s.chats[chatID]
+	if !ok {
+		return nil
+	}
+	turns := make([]cai.Turn, len(state.turns))
+	for i, turn := range state.turns {
+		turns[i] = copyTurn(turn)
+	}
+	return turns
+}
+
+// Avatar returns a copy of the image stored under an avatar file name.
+func (s *Server) Avatar(fileName string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, ok := s.avatars[fileName]
+	return append([]byte(nil), data...), ok
+}
+
+// authenticated rejects requests without the fake's token
+func (s *Server) authenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("authorization") != "Token "+Token {
+			writeError(w, http.StatusUnauthorized, "authentication failed")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// httpFailure is a scripted HTTP error response
+type httpFailure struct {
+	status     int // Zero passes the request on
+	retryAfter time.Duration
+}
+
+// scripted answers requests with scripted HTTP failures before passing them on
+func (s *Server) scripted(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.requests[r.URL.Path]++
+		queue := s.httpFailures[r.URL.Path]
+		if len(queue) == 0 {
+			s.mutex.Unlock()
+			next.ServeHTTP(w, r)
+			return
+		}
+		failure := queue[0]
+		s.httpFailures[r.URL.Path] = queue[1:]
+		s.mutex.Unlock()
+
+		if failure.status == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if failure.retryAfter > 0 {
+			w.Header().Set("Retry-After", fmt.Sprint(int(failure.retryAfter.Seconds())))
+		}
+		writeError(w, failure.status, http.StatusText(failure.status))
+	})
+}
+
+// takeFailure pops a scripted failure for command; the caller must hold the mutex
+func (s *Server) takeFailure(command string) (string, bool) {
+	queue := s.failures[command]
+	if len(queue) == 0 {
+		return "", false
+	}
+	s.failures[command] = queue[1:]
+	return queue[0], true
+}
+
+// timestamp returns the current time in the format used by the API
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// copyTurn returns a deep copy of turn, so it can be handed out without holding the mutex
+func copyTurn(turn *cai.Turn) cai.Turn {
+	result := *turn
+	result.CandidatesList = append([]cai.TurnCandidate(nil), turn.CandidatesList...)
+	result.Candidates = make(map[string]*cai.TurnCandidate, len(result.CandidatesList))
+	for i := range result.CandidatesList {
+		candidate := &result.CandidatesList[i]
+		result.Candidates[candidate.CandidateID] = candidate
+	}
+	return result
+}
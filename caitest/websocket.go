@@ -0,0 +1,582 @@
+package caitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/harmony-ai-solutions/CharacterAI-Golang/cai"
+)
+
+// incomingFrame is a command sent by the client
+type incomingFrame struct {
+	Command   string          `json:"command"`
+	RequestID string          `json:"request_id"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// outgoingFrame is a frame sent to the client. Like the real service, the fake puts the payload at the top level.
+type outgoingFrame struct {
+	Command   string        `json:"command"`
+	RequestID string        `json:"request_id,omitempty"`
+	Turn      *cai.Turn     `json:"turn,omitempty"`
+	Chat      *cai.Chat     `json:"chat,omitempty"`
+	ChatInfo  *cai.ChatInfo `json:"chat_info,omitempty"`
+	ChatID    string        `json:"chat_id,omitempty"`
+	TurnIDs   []string      `json:"turn_ids,omitempty"`
+	Comment   string        `json:"comment,omitempty"`
+}
+
+// wsSession is a WebSocket connection of a client
+type wsSession struct {
+	conn       *websocket.Conn
+	writeMutex sync.Mutex
+}
+
+// send writes a frame to the client. Errors are ignored, the read loop notices broken connections.
+func (w *wsSession) send(frame outgoingFrame) {
+	w.writeMutex.Lock()
+	defer w.writeMutex.Unlock()
+
+	_ = w.conn.WriteJSON(frame)
+}
+
+// serveWebSocket upgrades an authenticated request and handles the commands sent over the connection
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Cookie"), "Token "+Token) {
+		writeError(w, http.StatusUnauthorized, "authentication failed")
+		return
+	}
+	s.mutex.Lock()
+	if s.dialFailures > 0 {
+		s.dialFailures--
+		s.mutex.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	s.mutex.Unlock()
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	session := &wsSession{conn: conn}
+	conn.SetPingHandler(func(data string) error {
+		s.mutex.Lock()
+		ignore := s.ignorePings
+		s.mutex.Unlock()
+		if ignore {
+			return nil
+		}
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	s.mutex.Lock()
+	s.conns[conn] = struct{}{}
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		_ = conn.Close()
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var frame incomingFrame
+		if err := json.Unmarshal(message, &frame); err != nil {
+			session.send(outgoingFrame{Command: "neo_error", Comment: "malformed frame"})
+			continue
+		}
+		go s.handleCommand(session, frame)
+	}
+}
+
+// handleCommand executes a command and sends the resulting frames
+func (s *Server) handleCommand(session *wsSession, frame incomingFrame) {
+	s.mutex.Lock()
+	comment, failed := s.takeFailure(frame.Command)
+	s.mutex.Unlock()
+	if failed {
+		session.send(outgoingFrame{Command: "neo_error", RequestID: frame.RequestID, Comment: comment})
+		return
+	}
+
+	var err error
+	switch frame.Command {
+	case "create_chat":
+		var payload cai.CreateChatPayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.createChat(session, frame.RequestID, payload)
+		}
+	case "create_and_generate_turn":
+		var payload cai.CreateAndGenerateTurnPayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.createAndGenerateTurn(session, frame.RequestID, payload)
+		}
+	case "create_turn":
+		var payload cai.CreateTurnPayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.createTurn(session, frame.RequestID, payload)
+		}
+	case "generate_turn":
+		var payload cai.GenerateTurnPayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.generateTurn(session, frame.RequestID, payload)
+		}
+	case "generate_turn_candidate":
+		var payload cai.GenerateTurnCandidatePayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.generateTurnCandidate(session, frame.RequestID, payload)
+		}
+	case "update_primary_candidate":
+		var payload cai.UpdatePrimaryCandidatePayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.updateTurn(session, frame.RequestID, payload.TurnKey, "ok", func(turn *cai.Turn) string {
+				if findCandidate(turn, payload.CandidateID) == nil {
+					return "candidate not found"
+				}
+				turn.PrimaryCandidateID = payload.CandidateID
+				return ""
+			})
+		}
+	case "edit_turn_candidate":
+		var payload cai.EditTurnCandidatePayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.updateTurn(session, frame.RequestID, payload.TurnKey, "update_turn", func(turn *cai.Turn) string {
+				candidate := cai.TurnCandidate{
+					CandidateID:   uuid.New().String(),
+					Text:          payload.NewCandidateRawContent,
+					IsFinal:       true,
+					CreateTimeStr: timestamp(),
+				}
+				turn.CandidatesList = append(turn.CandidatesList, candidate)
+				turn.PrimaryCandidateID = candidate.CandidateID
+				return ""
+			})
+		}
+	case "set_turn_pin":
+		var payload cai.SetTurnPinPayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.updateTurn(session, frame.RequestID, payload.TurnKey, "update_turn", func(turn *cai.Turn) string {
+				turn.IsPinned = payload.IsPinned
+				return ""
+			})
+		}
+	case "abort_generation":
+		var payload cai.AbortGenerationPayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			// Generations in progress notice the abort before streaming their next chunk
+			s.mutex.Lock()
+			if state, ok := s.chats[payload.ChatID]; ok {
+				state.aborts++
+			}
+			s.mutex.Unlock()
+		}
+	case "remove_turns":
+		var payload cai.RemoveTurnsPayload
+		if err = json.Unmarshal(frame.Payload, &payload); err == nil {
+			s.removeTurns(session, frame.RequestID, payload)
+		}
+	default:
+		session.send(outgoingFrame{Command: "neo_error", RequestID: frame.RequestID, Comment: "unknown command " + frame.Command})
+		return
+	}
+
+	if err != nil {
+		session.send(outgoingFrame{Command: "neo_error", RequestID: frame.RequestID, Comment: err.Error()})
+	}
+}
+
+// createChat creates a chat, optionally starting it with the character's greeting
+func (s *Server) createChat(session *wsSession, requestID string, payload cai.CreateChatPayload) {
+	s.mutex.Lock()
+	character, ok := s.characters[payload.Chat.CharacterID]
+	for _, characterID := range payload.Chat.CharacterIDs {
+		_, known := s.characters[characterID]
+		ok = ok && known
+	}
+	if !ok {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: "character not found"})
+		return
+	}
+	state := &chatState{chat: cai.Chat{
+		ChatID:             payload.Chat.ChatID,
+		CharacterID:        character.ExternalID,
+		CharacterIDs:       payload.Chat.CharacterIDs,
+		CreatorID:          payload.Chat.CreatorID,
+		CreateTimeStr:      timestamp(),
+		State:              "STATE_ACTIVE",
+		ChatType:           payload.Chat.Type,
+		Visibility:         payload.Chat.Visibility,
+		ChatName:           payload.Chat.Name,
+		CharacterName:      character.Name,
+		CharacterAvatarURI: character.AvatarFileName,
+	}}
+	s.chats[state.chat.ChatID] = state
+	chat := state.chat
+
+	var greeting cai.Turn
+	if payload.WithGreeting {
+		turn := s.newCharacterTurn(state, character.ExternalID)
+		turn.CandidatesList[0].Text = character.Greeting
+		turn.CandidatesList[0].IsFinal = true
+		greeting = copyTurn(turn)
+	}
+	s.mutex.Unlock()
+
+	session.send(outgoingFrame{Command: "create_chat_response", RequestID: requestID, Chat: &chat})
+	if payload.WithGreeting {
+		session.send(outgoingFrame{Command: "add_turn", RequestID: requestID, Turn: &greeting, ChatInfo: chatInfo(chat)})
+	}
+}
+
+// createAndGenerateTurn adds the user's turn to a chat and streams the character's reply
+func (s *Server) createAndGenerateTurn(session *wsSession, requestID string, payload cai.CreateAndGenerateTurnPayload) {
+	s.mutex.Lock()
+	state, ok := s.chats[payload.Turn.TurnKey.ChatID]
+	if !ok {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: "chat not found"})
+		return
+	}
+
+	human, text := addTurn(state, payload.Turn)
+	humanCopy := copyTurn(human)
+	info := chatInfo(state.chat)
+	updateHuman := s.updateHuman
+	state.lastGenerate = &GenerateRequest{
+		CharacterID:         payload.CharacterID,
+		NumCandidates:       payload.NumCandidates,
+		PreviousAnnotations: payload.PreviousAnnotations,
+		SelectedLanguage:    payload.SelectedLanguage,
+		TTSEnabled:          payload.TTSEnabled,
+		UserName:            payload.UserName,
+		PersonaID:           s.settings.PersonaOverrides[payload.CharacterID],
+	}
+
+	// Each requested candidate gets its own reply; the first one is primary
+	replies := []Reply{s.reply(payload.CharacterID, text)}
+	for len(replies) < payload.NumCandidates {
+		replies = append(replies, s.reply(payload.CharacterID, text))
+	}
+	var turn *cai.Turn
+	var candidateIDs []string
+	if replies[0].Error == "" {
+		turn = s.newCharacterTurn(state, payload.CharacterID)
+		for len(turn.CandidatesList) < len(replies) {
+			turn.CandidatesList = append(turn.CandidatesList, cai.TurnCandidate{CandidateID: uuid.New().String(), CreateTimeStr: timestamp()})
+		}
+		for _, candidate := range turn.CandidatesList {
+			candidateIDs = append(candidateIDs, candidate.CandidateID)
+		}
+	}
+	s.mutex.Unlock()
+
+	session.send(outgoingFrame{Command: "add_turn", RequestID: requestID, Turn: &humanCopy, ChatInfo: info})
+	if updateHuman {
+		session.send(outgoingFrame{Command: "update_turn", RequestID: requestID, Turn: &humanCopy, ChatInfo: info})
+	}
+	if replies[0].Error != "" {
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: replies[0].Error})
+		return
+	}
+	for i, reply := range replies {
+		s.streamReply(session, requestID, info, turn, candidateIDs[i], reply)
+	}
+}
+
+// createTurn adds a turn of the user or a character to a chat without generating a reply
+func (s *Server) createTurn(session *wsSession, requestID string, payload cai.CreateTurnPayload) {
+	s.mutex.Lock()
+	state, ok := s.chats[payload.Turn.TurnKey.ChatID]
+	if !ok {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: "chat not found"})
+		return
+	}
+	turn, _ := addTurn(state, payload.Turn)
+	turnCopy := copyTurn(turn)
+	info := chatInfo(state.chat)
+	s.mutex.Unlock()
+
+	session.send(outgoingFrame{Command: "add_turn", RequestID: requestID, Turn: &turnCopy, ChatInfo: info})
+}
+
+// generateTurn streams a new turn of a participating character, replying to the latest message of the user
+func (s *Server) generateTurn(session *wsSession, requestID string, payload cai.GenerateTurnPayload) {
+	s.mutex.Lock()
+	state, ok := s.chats[payload.TurnKey.ChatID]
+	if !ok {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: "chat not found"})
+		return
+	}
+	if payload.CharacterID != state.chat.CharacterID && !containsString(state.chat.CharacterIDs, payload.CharacterID) {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: "character is not a participant"})
+		return
+	}
+	info := chatInfo(state.chat)
+	state.lastGenerate = &GenerateRequest{
+		CharacterID:         payload.CharacterID,
+		NumCandidates:       payload.NumCandidates,
+		PreviousAnnotations: payload.PreviousAnnotations,
+		SelectedLanguage:    payload.SelectedLanguage,
+		TTSEnabled:          payload.TTSEnabled,
+		UserName:            payload.UserName,
+		PersonaID:           s.settings.PersonaOverrides[payload.CharacterID],
+	}
+
+	reply := s.reply(payload.CharacterID, latestUserText(state, len(state.turns)))
+	if reply.Error != "" {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: reply.Error})
+		return
+	}
+	turn := s.newCharacterTurn(state, payload.CharacterID)
+	if payload.TurnKey.TurnID != "" {
+		turn.TurnKey.TurnID = payload.TurnKey.TurnID
+	}
+	s.mutex.Unlock()
+
+	s.streamReply(session, requestID, info, turn, turn.PrimaryCandidateID, reply)
+}
+
+// generateTurnCandidate streams a new candidate for a character turn, which becomes the primary candidate
+func (s *Server) generateTurnCandidate(session *wsSession, requestID string, payload cai.GenerateTurnCandidatePayload) {
+	s.mutex.Lock()
+	state, index := s.findTurn(payload.TurnKey)
+	if index < 0 || state.turns[index].Author.IsHuman {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: "turn not found"})
+		return
+	}
+	turn := state.turns[index]
+	info := chatInfo(state.chat)
+	state.lastGenerate = &GenerateRequest{
+		CharacterID:         payload.CharacterID,
+		NumCandidates:       1,
+		PreviousAnnotations: payload.PreviousAnnotations,
+		SelectedLanguage:    payload.SelectedLanguage,
+		TTSEnabled:          payload.TTSEnabled,
+		UserName:            payload.UserName,
+		PersonaID:           s.settings.PersonaOverrides[payload.CharacterID],
+	}
+
+	// The character replies to the latest message of the user before the turn
+	reply := s.reply(payload.CharacterID, latestUserText(state, index))
+	if reply.Error != "" {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: reply.Error})
+		return
+	}
+	candidate := cai.TurnCandidate{CandidateID: uuid.New().String(), CreateTimeStr: timestamp()}
+	turn.CandidatesList = append(turn.CandidatesList, candidate)
+	turn.PrimaryCandidateID = candidate.CandidateID
+	s.mutex.Unlock()
+
+	s.streamReply(session, requestID, info, turn, candidate.CandidateID, reply)
+}
+
+// updateTurn applies change to a turn and answers with command, carrying the updated turn unless command is "ok".
+// A non-empty result of change is sent as neo_error instead.
+func (s *Server) updateTurn(session *wsSession, requestID string, key cai.TurnKey, command string, change func(turn *cai.Turn) string) {
+	s.mutex.Lock()
+	state, index := s.findTurn(key)
+	if index < 0 {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: "turn not found"})
+		return
+	}
+	turn := state.turns[index]
+	if comment := change(turn); comment != "" {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: comment})
+		return
+	}
+	turn.LastUpdateTimeStr = timestamp()
+	updated := copyTurn(turn)
+	info := chatInfo(state.chat)
+	s.mutex.Unlock()
+
+	if command == "ok" {
+		session.send(outgoingFrame{Command: command, RequestID: requestID})
+		return
+	}
+	session.send(outgoingFrame{Command: command, RequestID: requestID, Turn: &updated, ChatInfo: info})
+}
+
+// removeTurns deletes turns from a chat
+func (s *Server) removeTurns(session *wsSession, requestID string, payload cai.RemoveTurnsPayload) {
+	s.mutex.Lock()
+	state, ok := s.chats[payload.ChatID]
+	if !ok {
+		s.mutex.Unlock()
+		session.send(outgoingFrame{Command: "neo_error", RequestID: requestID, Comment: "chat not found"})
+		return
+	}
+	turns := state.turns[:0]
+	for _, turn := range state.turns {
+		if !containsString(payload.TurnIDs, turn.TurnKey.TurnID) {
+			turns = append(turns, turn)
+		}
+	}
+	state.turns = turns
+	s.mutex.Unlock()
+
+	session.send(outgoingFrame{Command: "remove_turns_response", RequestID: requestID, ChatID: payload.ChatID, TurnIDs: payload.TurnIDs})
+}
+
+// streamReply fills a candidate of turn with reply chunk by chunk, sending an update_turn frame for each chunk.
+// The last frame marks the candidate final.
+func (s *Server) streamReply(session *wsSession, requestID string, info *cai.ChatInfo, turn *cai.Turn, candidateID string, reply Reply) {
+	s.mutex.Lock()
+	delay := s.chunkDelay
+	state := s.chats[turn.TurnKey.ChatID]
+	aborts := state.aborts
+	s.mutex.Unlock()
+
+	chunks := reply.Chunks
+	if len(chunks) == 0 {
+		chunks = []string{""}
+	}
+	var text strings.Builder
+	for i, chunk := range chunks {
+		if i > 0 && delay > 0 {
+			time.Sleep(delay)
+		}
+
+		s.mutex.Lock()
+		// An aborted generation keeps the text streamed so far
+		aborted := state.aborts != aborts
+		if !aborted {
+			text.WriteString(chunk)
+		}
+		final := aborted || i == len(chunks)-1
+		candidate := findCandidate(turn, candidateID)
+		candidate.Text = text.String()
+		candidate.IsFinal = final
+		candidate.IsFiltered = final && reply.SafetyTruncated
+		turn.LastUpdateTimeStr = timestamp()
+		update := copyTurn(turn)
+		s.mutex.Unlock()
+
+		session.send(outgoingFrame{Command: "update_turn", RequestID: requestID, Turn: &update, ChatInfo: info})
+		if final {
+			return
+		}
+	}
+}
+
+// newCharacterTurn appends an empty character turn with one candidate to a chat; the caller must hold the mutex
+func (s *Server) newCharacterTurn(state *chatState, characterID string) *cai.Turn {
+	name := characterID
+	if character, ok := s.characters[characterID]; ok {
+		name = character.Name
+	}
+	now := timestamp()
+	candidateID := uuid.New().String()
+	turn := &cai.Turn{
+		TurnKey:            cai.TurnKey{ChatID: state.chat.ChatID, TurnID: uuid.New().String()},
+		CreateTimeStr:      now,
+		LastUpdateTimeStr:  now,
+		State:              "STATE_OK",
+		Author:             cai.AuthorInfo{AuthorID: characterID, Name: name},
+		CandidatesList:     []cai.TurnCandidate{{CandidateID: candidateID, CreateTimeStr: now}},
+		PrimaryCandidateID: candidateID,
+	}
+	state.turns = append(state.turns, turn)
+	return turn
+}
+
+// addTurn appends a turn written by the client to a chat and returns it along with its primary text;
+// the caller must hold the mutex
+func addTurn(state *chatState, payload cai.TurnPayload) (*cai.Turn, string) {
+	now := timestamp()
+	turn := &cai.Turn{
+		TurnKey:            payload.TurnKey,
+		CreateTimeStr:      now,
+		LastUpdateTimeStr:  now,
+		State:              "STATE_OK",
+		Author:             cai.AuthorInfo{AuthorID: payload.Author.AuthorID, Name: payload.Author.Name, IsHuman: payload.Author.IsHuman},
+		PrimaryCandidateID: payload.PrimaryCandidateID,
+	}
+	var text string
+	for _, candidate := range payload.Candidates {
+		turn.CandidatesList = append(turn.CandidatesList, cai.TurnCandidate{
+			CandidateID:   candidate.CandidateID,
+			Text:          candidate.RawContent,
+			IsFinal:       true,
+			CreateTimeStr: now,
+		})
+		if candidate.CandidateID == payload.PrimaryCandidateID {
+			text = candidate.RawContent
+		}
+	}
+	state.turns = append(state.turns, turn)
+	return turn, text
+}
+
+// latestUserText returns the primary text of the latest user turn before index; the caller must hold the mutex
+func latestUserText(state *chatState, index int) string {
+	for i := index - 1; i >= 0; i-- {
+		if state.turns[i].Author.IsHuman {
+			if candidate := findCandidate(state.turns[i], state.turns[i].PrimaryCandidateID); candidate != nil {
+				return candidate.Text
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// reply produces the scripted reply of a character; the caller must hold the mutex
+func (s *Server) reply(characterID string, text string) Reply {
+	reply, ok := s.replies[characterID]
+	if !ok {
+		reply = s.defaultReply
+	}
+	return reply(characterID, text)
+}
+
+// findTurn returns the chat of a turn and the turn's index in it, or -1; the caller must hold the mutex
+func (s *Server) findTurn(key cai.TurnKey) (*chatState, int) {
+	state, ok := s.chats[key.ChatID]
+	if !ok {
+		return nil, -1
+	}
+	for i, turn := range state.turns {
+		if turn.TurnKey.TurnID == key.TurnID {
+			return state, i
+		}
+	}
+	return state, -1
+}
+
+// findCandidate returns a candidate of turn by ID, or nil
+func findCandidate(turn *cai.Turn, candidateID string) *cai.TurnCandidate {
+	for i := range turn.CandidatesList {
+		if turn.CandidatesList[i].CandidateID == candidateID {
+			return &turn.CandidatesList[i]
+		}
+	}
+	return nil
+}
+
+// chatInfo returns the chat info sent along with turns of chat
+func chatInfo(chat cai.Chat) *cai.ChatInfo {
+	return &cai.ChatInfo{Type: chat.ChatType}
+}
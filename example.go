@@ -37,8 +37,12 @@ func main() {
 	}
 
 	// Create a new client instance
-	client := cai.NewClient(token, webNextAuth, proxyURL)
-	err := client.Authenticate()
+	client, err := cai.NewClient(token, webNextAuth, proxyURL)
+	if err != nil {
+		fmt.Printf("Error creating client: %v\n", err)
+		os.Exit(1)
+	}
+	err = client.Authenticate()
 	if err != nil {
 		fmt.Printf("Authentication failed: %v\n", err)
 		os.Exit(2)